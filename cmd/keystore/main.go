@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"solana-dex-service/internal/signer"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// 密钥库管理工具
+//
+//	go run ./cmd/keystore -dir data/keystore list
+//	go run ./cmd/keystore -dir data/keystore new <id>
+//	go run ./cmd/keystore -dir data/keystore import <id>   # 从标准输入读取Base58私钥
+//
+// 口令从环境变量DEX_KEYSTORE_PASSPHRASE读取，未设置时从标准输入读取
+func main() {
+	dir := flag.String("dir", "data/keystore", "keystore directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: keystore [-dir path] list | new <id> | import <id>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ks, err := signer.NewKeystore(*dir)
+	if err != nil {
+		log.Fatalf("Failed to open keystore: %v", err)
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+
+	switch flag.Arg(0) {
+	case "list":
+		keys, err := ks.ListKeys(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to list keys: %v", err)
+		}
+		for _, key := range keys {
+			fmt.Printf("%s\t%s\n", key.ID, key.PublicKey)
+		}
+	case "new", "import":
+		if flag.NArg() != 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		id := flag.Arg(1)
+
+		var privateKey solana.PrivateKey
+		if flag.Arg(0) == "new" {
+			privateKey = solana.NewWallet().PrivateKey
+		} else {
+			encoded := readLine(stdin, "Private key (base58): ")
+			privateKey, err = solana.PrivateKeyFromBase58(encoded)
+			if err != nil {
+				log.Fatalf("Invalid private key: %v", err)
+			}
+		}
+
+		passphrase := os.Getenv("DEX_KEYSTORE_PASSPHRASE")
+		if passphrase == "" {
+			passphrase = readLine(stdin, "Passphrase: ")
+		}
+
+		key, err := ks.Import(id, privateKey, passphrase)
+		if err != nil {
+			log.Fatalf("Failed to import key: %v", err)
+		}
+		fmt.Printf("%s\t%s\n", key.ID, key.PublicKey)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// readLine 提示并从标准输入读取一行
+func readLine(r *bufio.Reader, prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatalf("Failed to read input: %v", err)
+	}
+	return strings.TrimSpace(line)
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,21 +15,65 @@ import (
 	"solana-dex-service/internal/config"
 	"solana-dex-service/internal/handlers"
 	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/signer"
+	"solana-dex-service/internal/store"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	// 加载配置
-	cfg, err := config.LoadConfig("config/config.yaml")
+	// 配置文件路径：--config 参数，其次是 CONFIG_PATH 环境变量
+	defaultConfigPath := os.Getenv("CONFIG_PATH")
+	if defaultConfigPath == "" {
+		defaultConfigPath = "config/config.yaml"
+	}
+	configPath := flag.String("config", defaultConfigPath, "path to the config file")
+	flag.Parse()
+
+	// 加载配置，DEX_ 开头的环境变量和 <变量>_FILE 指定的文件覆盖配置文件中的值
+	cfg, err := config.LoadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 打开交易记录存储
+	txStore, err := store.NewBoltTransactionStore(cfg.Storage.Path)
+	if err != nil {
+		log.Fatalf("Failed to open transaction store: %v", err)
+	}
+	defer txStore.Close()
+
 	// 初始化服务
 	transactionService := services.NewTransactionService(cfg)
+	transactionService.SetTransactionStore(txStore)
+	if txSigner, err := signer.New(cfg.Signer); err != nil {
+		log.Printf("Signer unavailable, test transactions cannot be signed: %v", err)
+	} else {
+		transactionService.SetSigner(txSigner)
+	}
+	transactionService.Start()
+	defer transactionService.Stop()
 	dexService := services.NewDEXService(cfg)
+	dexService.SetTransactionService(transactionService)
 	configService := services.NewConfigService(cfg)
+	configService.SetConfigPath(*configPath)
+
+	// 打开配置版本历史存储，记录每次应用的配置
+	historyStore, err := store.NewBoltConfigHistoryStore(cfg.Storage.ConfigHistoryPath)
+	if err != nil {
+		log.Printf("Config history unavailable, changes will not be versioned: %v", err)
+	} else {
+		defer historyStore.Close()
+		configService.SetHistoryStore(historyStore)
+	}
+
+	// 配置变更后重建适配器注册表，手动修改配置文件时自动重新加载
+	configService.OnChange(transactionService.ApplyConfig)
+	configService.OnChange(dexService.ApplyConfig)
+	if err := configService.StartWatcher(); err != nil {
+		log.Printf("Config file watcher unavailable, edits require a restart or reload: %v", err)
+	}
+	defer configService.StopWatcher()
 
 	// 设置Gin模式
 	if cfg.Server.Mode == "release" {
@@ -43,7 +89,8 @@ func main() {
 	router.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Config-Author, X-Config-Reason, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
@@ -57,12 +104,15 @@ func main() {
 	configHandler := handlers.NewConfigHandler(configService)
 
 	// 设置路由
-	setupRoutes(router, transactionHandler, dexHandler, configHandler)
+	handlers.SetupRoutes(router, transactionHandler, dexHandler, configHandler)
 
-	// 创建HTTP服务器
+	// 创建HTTP服务器，请求上下文派生自baseCtx，关闭超时后取消以中止进行中的上游调用
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler: router,
+		Addr:        fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
 
 	// 启动服务器
@@ -83,51 +133,9 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := srv.Shutdown(ctx); err != nil {
+		cancelRequests()
 		log.Fatal("Server forced to shutdown:", err)
 	}
 
 	log.Println("Server exited")
-}
-
-// setupRoutes 设置所有路由
-func setupRoutes(router *gin.Engine, transactionHandler *handlers.TransactionHandler, dexHandler *handlers.DEXHandler, configHandler *handlers.ConfigHandler) {
-	// 健康检查
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "timestamp": time.Now().Unix()})
-	})
-
-	// API v1 路由组
-	v1 := router.Group("/api/v1")
-	{
-		// 交易编码相关路由
-		encode := v1.Group("/encode")
-		{
-			encode.POST("/swap", transactionHandler.EncodeSwap)
-			encode.POST("/liquidity", transactionHandler.EncodeLiquidity)
-		}
-
-		// 交易测试相关路由
-		test := v1.Group("/test")
-		{
-			test.POST("/transaction", transactionHandler.TestTransaction)
-			test.POST("/simulate", transactionHandler.SimulateTransaction)
-		}
-
-		// DEX相关路由
-		dex := v1.Group("/dex")
-		{
-			dex.GET("/list", dexHandler.ListDEXes)
-			dex.GET("/:name", dexHandler.GetDEX)
-			dex.GET("/:name/pools", dexHandler.GetPools)
-		}
-
-		// 配置管理相关路由
-		config := v1.Group("/config")
-		{
-			config.GET("/", configHandler.GetConfig)
-			config.PUT("/", configHandler.UpdateConfig)
-			config.GET("/dex", configHandler.GetDEXConfig)
-			config.PUT("/dex", configHandler.UpdateDEXConfig)
-		}
-	}
 }
\ No newline at end of file
@@ -0,0 +1,579 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// 通用Anchor适配器支持的操作
+const (
+	AnchorOpSwap            = "swap"
+	AnchorOpAddLiquidity    = "add_liquidity"
+	AnchorOpRemoveLiquidity = "remove_liquidity"
+)
+
+// anchorConstPrefix 参数映射中的字面量前缀
+const anchorConstPrefix = "const:"
+
+// anchorOperationSources 各操作可以映射到参数或账户的请求字段
+var anchorOperationSources = map[string][]string{
+	AnchorOpSwap:            {"amount_in", "min_amount_out", "slippage_bps", "user_wallet", "input_mint", "output_mint", "user_input_ata", "user_output_ata"},
+	AnchorOpAddLiquidity:    {"amount_a", "amount_b", "slippage_bps", "user_wallet", "token_a_mint", "token_b_mint", "user_token_a_ata", "user_token_b_ata"},
+	AnchorOpRemoveLiquidity: {"amount_a", "amount_b", "slippage_bps", "user_wallet", "token_a_mint", "token_b_mint", "user_token_a_ata", "user_token_b_ata"},
+}
+
+// anchorProgramAliases 账户映射中可以使用的程序别名，program为DEX程序自身
+var anchorProgramAliases = map[string]solana.PublicKey{
+	"token_program":            solana.TokenProgramID,
+	"system_program":           solana.SystemProgramID,
+	"associated_token_program": solana.SPLAssociatedTokenAccountProgramID,
+	"rent":                     solana.SysVarRentPubkey,
+}
+
+// AnchorAdapter 通用Anchor DEX适配器，按IDL和配置中的字段映射构建指令，无需为每个DEX单独编写适配器
+type AnchorAdapter struct {
+	*BaseAdapter
+	programID solana.PublicKey
+	idl       *anchorIDL
+	mappings  map[string]*anchorMapping
+}
+
+// anchorMapping 一个操作到IDL指令的映射
+type anchorMapping struct {
+	instruction *anchorInstruction
+	args        map[string]string
+	accounts    map[string]string
+}
+
+// NewAnchorAdapter 创建通用Anchor适配器，加载IDL并检查每个操作的参数和账户都能得到取值
+func NewAnchorAdapter(cfg *config.DEXConfig) (*AnchorAdapter, error) {
+	programID, err := solana.PublicKeyFromBase58(cfg.ProgramID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid program ID: %w", err)
+	}
+	if cfg.IDLPath == "" {
+		return nil, fmt.Errorf("idl_path is required")
+	}
+	idl, err := loadAnchorIDL(cfg.IDLPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Instructions) == 0 {
+		return nil, fmt.Errorf("no instruction mappings configured")
+	}
+
+	a := &AnchorAdapter{
+		BaseAdapter: NewBaseAdapter(cfg.Name, cfg),
+		programID:   programID,
+		idl:         idl,
+		mappings:    make(map[string]*anchorMapping),
+	}
+	for op, insCfg := range cfg.Instructions {
+		mapping, err := newAnchorMapping(idl, op, insCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s mapping: %w", op, err)
+		}
+		a.mappings[op] = mapping
+	}
+	return a, nil
+}
+
+// newAnchorMapping 校验操作映射：映射的参数和账户必须存在于IDL中，未映射的参数必须是option，未映射的账户必须能从IDL推导
+func newAnchorMapping(idl *anchorIDL, op string, cfg config.AnchorInstructionConfig) (*anchorMapping, error) {
+	sources, ok := anchorOperationSources[op]
+	if !ok {
+		return nil, fmt.Errorf("unsupported operation (supported: %s, %s, %s)", AnchorOpSwap, AnchorOpAddLiquidity, AnchorOpRemoveLiquidity)
+	}
+	ins := idl.instruction(cfg.Instruction)
+	if ins == nil {
+		return nil, fmt.Errorf("instruction %q not found in IDL", cfg.Instruction)
+	}
+	isSource := func(name string) bool {
+		for _, source := range sources {
+			if source == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	args := make(map[string]*anchorArg, len(ins.args))
+	for i := range ins.args {
+		args[ins.args[i].name] = &ins.args[i]
+	}
+	for name, source := range cfg.Args {
+		arg, ok := args[name]
+		if !ok {
+			return nil, fmt.Errorf("arg %q not found in instruction %s", name, ins.name)
+		}
+		if literal, ok := strings.CutPrefix(source, anchorConstPrefix); ok {
+			if _, err := encodeBorsh(arg.typ, literal); err != nil {
+				return nil, fmt.Errorf("arg %s: %w", name, err)
+			}
+			continue
+		}
+		if !isSource(source) {
+			return nil, fmt.Errorf("arg %s: unknown source %q", name, source)
+		}
+	}
+	for _, arg := range ins.args {
+		if _, ok := cfg.Args[arg.name]; !ok && arg.typ.kind != "option" {
+			return nil, fmt.Errorf("arg %s of instruction %s is not mapped", arg.name, ins.name)
+		}
+	}
+
+	accounts := make(map[string]*anchorAccount, len(ins.accounts))
+	for i := range ins.accounts {
+		accounts[ins.accounts[i].name] = &ins.accounts[i]
+	}
+	for name, source := range cfg.Accounts {
+		if _, ok := accounts[name]; !ok {
+			return nil, fmt.Errorf("account %q not found in instruction %s", name, ins.name)
+		}
+		if source == "program" || isSource(source) {
+			continue
+		}
+		if _, ok := anchorProgramAliases[source]; ok {
+			continue
+		}
+		if _, err := solana.PublicKeyFromBase58(source); err != nil {
+			return nil, fmt.Errorf("account %s: unknown source %q", name, source)
+		}
+	}
+	for _, account := range ins.accounts {
+		if _, ok := cfg.Accounts[account.name]; ok || account.address != nil || account.signer || account.optional {
+			continue
+		}
+		if account.pda == nil {
+			return nil, fmt.Errorf("account %s of instruction %s is not mapped", account.name, ins.name)
+		}
+		seeds := account.pda.Seeds
+		if account.pda.Program != nil {
+			seeds = append(append([]idlSeed(nil), seeds...), *account.pda.Program)
+		}
+		for _, seed := range seeds {
+			if err := checkSeed(seed, args, accounts); err != nil {
+				return nil, fmt.Errorf("account %s: %w", account.name, err)
+			}
+		}
+	}
+
+	return &anchorMapping{instruction: ins, args: cfg.Args, accounts: cfg.Accounts}, nil
+}
+
+// checkSeed 检查PDA种子可以解析，不支持引用账户数据字段的种子
+func checkSeed(seed idlSeed, args map[string]*anchorArg, accounts map[string]*anchorAccount) error {
+	switch seed.Kind {
+	case "const":
+		_, err := constSeed(seed)
+		return err
+	case "arg":
+		if _, ok := args[seed.Path]; !ok {
+			return fmt.Errorf("seed references unknown arg %q", seed.Path)
+		}
+	case "account":
+		if strings.Contains(seed.Path, ".") {
+			return fmt.Errorf("seed %q references account data, which is not supported", seed.Path)
+		}
+		if _, ok := accounts[seed.Path]; !ok {
+			return fmt.Errorf("seed references unknown account %q", seed.Path)
+		}
+	default:
+		return fmt.Errorf("unsupported seed kind %q", seed.Kind)
+	}
+	return nil
+}
+
+// GetQuote 获取交易报价，报价接口格式与PumpSwap相同
+func (a *AnchorAdapter) GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
+	quoteURL := a.config.Endpoints["quote"]
+	if quoteURL == "" {
+		return nil, fmt.Errorf("quote endpoint not configured for %s", a.GetName())
+	}
+
+	reqParams := map[string]interface{}{
+		"inputMint":  inputMint,
+		"outputMint": outputMint,
+		"amount":     amountIn,
+		"slippage":   0.005, // 默认0.5%滑点
+	}
+
+	var quoteResp struct {
+		Success bool `json:"success"`
+		Data    struct {
+			AmountOut    uint64  `json:"amountOut"`
+			MinAmountOut uint64  `json:"minAmountOut"`
+			PriceImpact  float64 `json:"priceImpact"`
+			Fee          uint64  `json:"fee"`
+		} `json:"data"`
+		Error string `json:"error,omitempty"`
+	}
+
+	if err := a.makeRequest(ctx, "POST", quoteURL, reqParams, &quoteResp); err != nil {
+		return nil, fmt.Errorf("failed to get quote: %w", err)
+	}
+	if !quoteResp.Success {
+		return nil, fmt.Errorf("quote request failed: %s", quoteResp.Error)
+	}
+
+	return &types.QuoteResponse{
+		InputMint:    inputMint,
+		OutputMint:   outputMint,
+		AmountIn:     amountIn,
+		AmountOut:    quoteResp.Data.AmountOut,
+		MinAmountOut: quoteResp.Data.MinAmountOut,
+		PriceImpact:  quoteResp.Data.PriceImpact,
+		Fee:          quoteResp.Data.Fee,
+		Route: []types.Route{{
+			DEX:        a.GetName(),
+			InputMint:  inputMint,
+			OutputMint: outputMint,
+			AmountIn:   amountIn,
+			AmountOut:  quoteResp.Data.AmountOut,
+		}},
+	}, nil
+}
+
+// GetPools 获取流动性池信息，池接口格式与PumpSwap相同
+func (a *AnchorAdapter) GetPools(ctx context.Context) ([]types.PoolInfo, error) {
+	poolsURL := a.config.Endpoints["pools"]
+	if poolsURL == "" {
+		if a.config.Endpoints["api"] == "" {
+			return nil, fmt.Errorf("pools endpoint not configured for %s", a.GetName())
+		}
+		poolsURL = fmt.Sprintf("%s/pools", a.config.Endpoints["api"])
+	}
+
+	var poolsResp struct {
+		Success bool `json:"success"`
+		Data    []struct {
+			Address    string  `json:"address"`
+			TokenAMint string  `json:"tokenAMint"`
+			TokenBMint string  `json:"tokenBMint"`
+			TokenAName string  `json:"tokenAName"`
+			TokenBName string  `json:"tokenBName"`
+			ReserveA   uint64  `json:"reserveA"`
+			ReserveB   uint64  `json:"reserveB"`
+			Liquidity  uint64  `json:"liquidity"`
+			FeeRate    float64 `json:"feeRate"`
+			TVL        float64 `json:"tvl"`
+			Volume24h  float64 `json:"volume24h"`
+			APR        float64 `json:"apr"`
+		} `json:"data"`
+		Error string `json:"error,omitempty"`
+	}
+	if err := a.makeRequest(ctx, "GET", poolsURL, nil, &poolsResp); err != nil {
+		return nil, fmt.Errorf("failed to get pools: %w", err)
+	}
+	if !poolsResp.Success {
+		return nil, fmt.Errorf("pools request failed: %s", poolsResp.Error)
+	}
+
+	pools := make([]types.PoolInfo, 0, len(poolsResp.Data))
+	for _, pool := range poolsResp.Data {
+		pools = append(pools, types.PoolInfo{
+			Address:    pool.Address,
+			TokenAMint: pool.TokenAMint,
+			TokenBMint: pool.TokenBMint,
+			TokenAName: pool.TokenAName,
+			TokenBName: pool.TokenBName,
+			ReserveA:   pool.ReserveA,
+			ReserveB:   pool.ReserveB,
+			Liquidity:  pool.Liquidity,
+			FeeRate:    pool.FeeRate,
+			TVL:        pool.TVL,
+			Volume24h:  pool.Volume24h,
+			APR:        pool.APR,
+		})
+	}
+	return pools, nil
+}
+
+// BuildSwapInstruction 按swap映射构建交换指令
+func (a *AnchorAdapter) BuildSwapInstruction(req *types.SwapRequest) (*types.InstructionData, error) {
+	if err := a.ValidateSwapRequest(req); err != nil {
+		return nil, err
+	}
+
+	userWallet := solana.MustPublicKeyFromBase58(req.UserWallet)
+	inputMint := solana.MustPublicKeyFromBase58(req.InputMint)
+	outputMint := solana.MustPublicKeyFromBase58(req.OutputMint)
+	userInputATA, _, err := solana.FindAssociatedTokenAddress(userWallet, inputMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user input token account: %w", err)
+	}
+	userOutputATA, _, err := solana.FindAssociatedTokenAddress(userWallet, outputMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user output token account: %w", err)
+	}
+
+	return a.buildInstruction(AnchorOpSwap, map[string]interface{}{
+		"amount_in":       req.AmountIn,
+		"min_amount_out":  a.minAmountOut(req),
+		"slippage_bps":    slippageBps(req.Slippage),
+		"user_wallet":     userWallet,
+		"input_mint":      inputMint,
+		"output_mint":     outputMint,
+		"user_input_ata":  userInputATA,
+		"user_output_ata": userOutputATA,
+	})
+}
+
+// BuildLiquidityInstruction 按add_liquidity或remove_liquidity映射构建流动性指令
+func (a *AnchorAdapter) BuildLiquidityInstruction(req *types.LiquidityRequest) (*types.InstructionData, error) {
+	if err := a.validateLiquidityRequest(req); err != nil {
+		return nil, err
+	}
+
+	op := AnchorOpAddLiquidity
+	if req.Operation == "remove" {
+		op = AnchorOpRemoveLiquidity
+	}
+
+	userWallet := solana.MustPublicKeyFromBase58(req.UserWallet)
+	tokenAMint := solana.MustPublicKeyFromBase58(req.TokenAMint)
+	tokenBMint := solana.MustPublicKeyFromBase58(req.TokenBMint)
+	userTokenAATA, _, err := solana.FindAssociatedTokenAddress(userWallet, tokenAMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user token A account: %w", err)
+	}
+	userTokenBATA, _, err := solana.FindAssociatedTokenAddress(userWallet, tokenBMint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user token B account: %w", err)
+	}
+
+	return a.buildInstruction(op, map[string]interface{}{
+		"amount_a":         req.AmountA,
+		"amount_b":         req.AmountB,
+		"slippage_bps":     slippageBps(req.Slippage),
+		"user_wallet":      userWallet,
+		"token_a_mint":     tokenAMint,
+		"token_b_mint":     tokenBMint,
+		"user_token_a_ata": userTokenAATA,
+		"user_token_b_ata": userTokenBATA,
+	})
+}
+
+// buildInstruction 编码指令数据（discriminator + Borsh参数）并按IDL顺序解析账户
+func (a *AnchorAdapter) buildInstruction(op string, values map[string]interface{}) (*types.InstructionData, error) {
+	mapping, ok := a.mappings[op]
+	if !ok {
+		return nil, fmt.Errorf("%s does not support %s: no instruction mapping configured", a.GetName(), op)
+	}
+	ins := mapping.instruction
+
+	data := append([]byte(nil), ins.discriminator...)
+	argSeeds := make(map[string][]byte, len(ins.args))
+	for _, arg := range ins.args {
+		var value interface{}
+		if source, ok := mapping.args[arg.name]; ok {
+			if literal, ok := strings.CutPrefix(source, anchorConstPrefix); ok {
+				value = literal
+			} else {
+				value = values[source]
+			}
+		}
+		encoded, err := encodeBorsh(arg.typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arg %s: %w", arg.name, err)
+		}
+		// 字符串和字节参数作为种子时不带长度前缀
+		if arg.typ.kind == "string" || arg.typ.kind == "bytes" {
+			argSeeds[arg.name] = encoded[4:]
+		} else {
+			argSeeds[arg.name] = encoded
+		}
+		data = append(data, encoded...)
+	}
+
+	keys, err := a.resolveAccounts(mapping, values, argSeeds)
+	if err != nil {
+		return nil, err
+	}
+	accounts := make([]solana.AccountMeta, len(ins.accounts))
+	for i, account := range ins.accounts {
+		accounts[i] = solana.AccountMeta{
+			PublicKey:  keys[account.name],
+			IsSigner:   account.signer,
+			IsWritable: account.writable,
+		}
+		// 未提供的可选账户按Anchor约定传入程序ID，只读且不签名
+		if account.optional && keys[account.name].Equals(a.programID) {
+			accounts[i].IsSigner = false
+			accounts[i].IsWritable = false
+		}
+	}
+
+	return a.createInstruction(a.programID, accounts, data), nil
+}
+
+// resolveAccounts 解析指令账户：映射的账户直接取值，其余按IDL中的地址、PDA种子、签名者（用户钱包）和可选账户依次推导
+func (a *AnchorAdapter) resolveAccounts(mapping *anchorMapping, values map[string]interface{}, argSeeds map[string][]byte) (map[string]solana.PublicKey, error) {
+	ins := mapping.instruction
+	keys := make(map[string]solana.PublicKey, len(ins.accounts))
+	var pending []anchorAccount
+
+	for _, account := range ins.accounts {
+		source, mapped := mapping.accounts[account.name]
+		switch {
+		case mapped:
+			key, err := a.accountSource(source, values)
+			if err != nil {
+				return nil, fmt.Errorf("account %s: %w", account.name, err)
+			}
+			keys[account.name] = key
+		case account.address != nil:
+			keys[account.name] = *account.address
+		case account.pda != nil:
+			pending = append(pending, account)
+		case account.signer:
+			keys[account.name] = values["user_wallet"].(solana.PublicKey)
+		default:
+			keys[account.name] = a.programID
+		}
+	}
+
+	// PDA种子可能引用其它PDA账户，按依赖顺序反复推导
+	for len(pending) > 0 {
+		var next []anchorAccount
+		for _, account := range pending {
+			key, ready, err := a.derivePDA(account.pda, keys, argSeeds)
+			if err != nil {
+				return nil, fmt.Errorf("failed to derive account %s: %w", account.name, err)
+			}
+			if !ready {
+				next = append(next, account)
+				continue
+			}
+			keys[account.name] = key
+		}
+		if len(next) == len(pending) {
+			names := make([]string, len(next))
+			for i, account := range next {
+				names[i] = account.name
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("cannot resolve PDA accounts with circular seeds: %s", strings.Join(names, ", "))
+		}
+		pending = next
+	}
+	return keys, nil
+}
+
+// accountSource 将账户映射解析为公钥
+func (a *AnchorAdapter) accountSource(source string, values map[string]interface{}) (solana.PublicKey, error) {
+	if source == "program" {
+		return a.programID, nil
+	}
+	if key, ok := anchorProgramAliases[source]; ok {
+		return key, nil
+	}
+	if value, ok := values[source]; ok {
+		key, ok := value.(solana.PublicKey)
+		if !ok {
+			return solana.PublicKey{}, fmt.Errorf("source %q is not an account", source)
+		}
+		return key, nil
+	}
+	return solana.PublicKeyFromBase58(source)
+}
+
+// derivePDA 按IDL种子推导PDA，引用的账户尚未解析时返回ready=false
+func (a *AnchorAdapter) derivePDA(pda *idlPDA, keys map[string]solana.PublicKey, argSeeds map[string][]byte) (solana.PublicKey, bool, error) {
+	seedBytes := func(seed idlSeed) ([]byte, bool, error) {
+		switch seed.Kind {
+		case "const":
+			b, err := constSeed(seed)
+			return b, true, err
+		case "arg":
+			return argSeeds[seed.Path], true, nil
+		case "account":
+			key, ok := keys[seed.Path]
+			if !ok {
+				return nil, false, nil
+			}
+			return key.Bytes(), true, nil
+		}
+		return nil, false, fmt.Errorf("unsupported seed kind %q", seed.Kind)
+	}
+
+	var seeds [][]byte
+	for _, seed := range pda.Seeds {
+		b, ready, err := seedBytes(seed)
+		if err != nil || !ready {
+			return solana.PublicKey{}, ready, err
+		}
+		seeds = append(seeds, b)
+	}
+
+	programID := a.programID
+	if pda.Program != nil {
+		b, ready, err := seedBytes(*pda.Program)
+		if err != nil || !ready {
+			return solana.PublicKey{}, ready, err
+		}
+		if len(b) != solana.PublicKeyLength {
+			return solana.PublicKey{}, false, fmt.Errorf("invalid PDA program seed length %d", len(b))
+		}
+		programID = solana.PublicKeyFromBytes(b)
+	}
+
+	address, _, err := solana.FindProgramAddress(seeds, programID)
+	if err != nil {
+		return solana.PublicKey{}, false, err
+	}
+	return address, true, nil
+}
+
+// ValidateRequest 验证请求
+func (a *AnchorAdapter) ValidateRequest(req interface{}) error {
+	switch v := req.(type) {
+	case *types.SwapRequest:
+		return a.ValidateSwapRequest(v)
+	case *types.LiquidityRequest:
+		return a.validateLiquidityRequest(v)
+	default:
+		return fmt.Errorf("unsupported request type")
+	}
+}
+
+// DecodeInstruction 按discriminator匹配IDL指令，解码参数并按IDL为账户命名
+func (a *AnchorAdapter) DecodeInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	for _, ins := range a.idl.instructions {
+		if !bytes.HasPrefix(data, ins.discriminator) {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(ins.args))
+		rest := data[len(ins.discriminator):]
+		for _, arg := range ins.args {
+			value, remaining, err := decodeBorsh(arg.typ, rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s instruction arg %s: %w", ins.name, arg.name, err)
+			}
+			fields[arg.name] = value
+			rest = remaining
+		}
+
+		names := make([]string, len(ins.accounts))
+		for i, account := range ins.accounts {
+			names[i] = account.name
+		}
+		return a.decodedInstruction(toSnakeCase(ins.name), fields, accounts, names...), nil
+	}
+	return nil, fmt.Errorf("unknown %s instruction", a.GetName())
+}
+
+// slippageBps 将滑点比例转换为基点
+func slippageBps(slippage float64) uint64 {
+	return uint64(math.Round(slippage * 10000))
+}
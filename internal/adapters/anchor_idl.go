@@ -0,0 +1,427 @@
+package adapters
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// idlFile Anchor IDL文件，兼容0.30之前的旧格式（isMut/isSigner、无discriminator）和新格式
+type idlFile struct {
+	Instructions []idlInstruction `json:"instructions"`
+}
+
+type idlInstruction struct {
+	Name          string       `json:"name"`
+	Discriminator []int        `json:"discriminator"`
+	Accounts      []idlAccount `json:"accounts"`
+	Args          []idlField   `json:"args"`
+}
+
+type idlAccount struct {
+	Name     string       `json:"name"`
+	Writable bool         `json:"writable"`
+	Signer   bool         `json:"signer"`
+	IsMut    bool         `json:"isMut"`
+	IsSigner bool         `json:"isSigner"`
+	Optional bool         `json:"optional"`
+	Address  string       `json:"address"`
+	PDA      *idlPDA      `json:"pda"`
+	Accounts []idlAccount `json:"accounts"` // 嵌套账户组
+}
+
+type idlPDA struct {
+	Seeds   []idlSeed `json:"seeds"`
+	Program *idlSeed  `json:"program"`
+}
+
+type idlSeed struct {
+	Kind  string          `json:"kind"` // const, arg, account
+	Type  json.RawMessage `json:"type"`
+	Value json.RawMessage `json:"value"`
+	Path  string          `json:"path"`
+}
+
+type idlField struct {
+	Name string          `json:"name"`
+	Type json.RawMessage `json:"type"`
+}
+
+// anchorInstruction 解析后的IDL指令
+type anchorInstruction struct {
+	name          string
+	discriminator []byte
+	accounts      []anchorAccount
+	args          []anchorArg
+}
+
+// anchorAccount 展开嵌套账户组后的指令账户
+type anchorAccount struct {
+	name     string
+	writable bool
+	signer   bool
+	optional bool
+	address  *solana.PublicKey
+	pda      *idlPDA
+}
+
+// anchorArg 指令参数
+type anchorArg struct {
+	name string
+	typ  *idlType
+}
+
+// idlType 支持的Borsh类型：整数、bool、pubkey、string、bytes和option
+type idlType struct {
+	kind  string
+	inner *idlType
+}
+
+// anchorIDL 解析后的IDL
+type anchorIDL struct {
+	instructions []*anchorInstruction
+}
+
+// loadAnchorIDL 读取并解析IDL文件
+func loadAnchorIDL(path string) (*anchorIDL, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IDL: %w", err)
+	}
+	return parseAnchorIDL(raw)
+}
+
+// parseAnchorIDL 解析IDL，计算缺失的指令discriminator
+func parseAnchorIDL(raw []byte) (*anchorIDL, error) {
+	var file idlFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("invalid IDL: %w", err)
+	}
+	if len(file.Instructions) == 0 {
+		return nil, fmt.Errorf("IDL has no instructions")
+	}
+
+	idl := &anchorIDL{}
+	for _, ins := range file.Instructions {
+		parsed := &anchorInstruction{name: ins.Name}
+		if len(ins.Discriminator) > 0 {
+			for _, b := range ins.Discriminator {
+				if b < 0 || b > 255 {
+					return nil, fmt.Errorf("instruction %s: invalid discriminator", ins.Name)
+				}
+				parsed.discriminator = append(parsed.discriminator, byte(b))
+			}
+		} else {
+			parsed.discriminator = anchorDiscriminator(ins.Name)
+		}
+
+		accounts, err := flattenIDLAccounts(ins.Accounts)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %s: %w", ins.Name, err)
+		}
+		parsed.accounts = accounts
+
+		for _, arg := range ins.Args {
+			typ, err := parseIDLType(arg.Type)
+			if err != nil {
+				return nil, fmt.Errorf("instruction %s arg %s: %w", ins.Name, arg.Name, err)
+			}
+			parsed.args = append(parsed.args, anchorArg{name: arg.Name, typ: typ})
+		}
+		idl.instructions = append(idl.instructions, parsed)
+	}
+	return idl, nil
+}
+
+// instruction 按名称查找指令，名称同时按snake_case比较以兼容新旧格式
+func (idl *anchorIDL) instruction(name string) *anchorInstruction {
+	for _, ins := range idl.instructions {
+		if ins.name == name || toSnakeCase(ins.name) == toSnakeCase(name) {
+			return ins
+		}
+	}
+	return nil
+}
+
+// anchorDiscriminator 计算Anchor指令discriminator：sha256("global:<snake_case名称>")的前8字节
+func anchorDiscriminator(name string) []byte {
+	sum := sha256.Sum256([]byte("global:" + toSnakeCase(name)))
+	return sum[:8]
+}
+
+// toSnakeCase 将旧格式IDL中的camelCase名称转换为snake_case
+func toSnakeCase(name string) string {
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && (!unicode.IsUpper(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				sb.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// flattenIDLAccounts 展开嵌套账户组
+func flattenIDLAccounts(accounts []idlAccount) ([]anchorAccount, error) {
+	var flat []anchorAccount
+	for _, account := range accounts {
+		if len(account.Accounts) > 0 {
+			nested, err := flattenIDLAccounts(account.Accounts)
+			if err != nil {
+				return nil, err
+			}
+			flat = append(flat, nested...)
+			continue
+		}
+
+		parsed := anchorAccount{
+			name:     account.Name,
+			writable: account.Writable || account.IsMut,
+			signer:   account.Signer || account.IsSigner,
+			optional: account.Optional,
+			pda:      account.PDA,
+		}
+		if account.Address != "" {
+			address, err := solana.PublicKeyFromBase58(account.Address)
+			if err != nil {
+				return nil, fmt.Errorf("account %s: invalid address: %w", account.Name, err)
+			}
+			parsed.address = &address
+		}
+		flat = append(flat, parsed)
+	}
+	return flat, nil
+}
+
+// parseIDLType 解析参数类型，不支持的类型返回错误
+func parseIDLType(raw json.RawMessage) (*idlType, error) {
+	var name string
+	if err := json.Unmarshal(raw, &name); err == nil {
+		switch name {
+		case "u8", "u16", "u32", "u64", "u128", "i8", "i16", "i32", "i64", "i128", "bool", "string", "bytes":
+			return &idlType{kind: name}, nil
+		case "pubkey", "publicKey":
+			return &idlType{kind: "pubkey"}, nil
+		default:
+			return nil, fmt.Errorf("unsupported type %q", name)
+		}
+	}
+
+	var option struct {
+		Option json.RawMessage `json:"option"`
+	}
+	if err := json.Unmarshal(raw, &option); err == nil && len(option.Option) > 0 {
+		inner, err := parseIDLType(option.Option)
+		if err != nil {
+			return nil, err
+		}
+		return &idlType{kind: "option", inner: inner}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", string(raw))
+}
+
+// intSize 返回整数类型的字节数，非整数类型返回0
+func (t *idlType) intSize() int {
+	switch t.kind {
+	case "u8", "i8":
+		return 1
+	case "u16", "i16":
+		return 2
+	case "u32", "i32":
+		return 4
+	case "u64", "i64":
+		return 8
+	case "u128", "i128":
+		return 16
+	}
+	return 0
+}
+
+// encodeBorsh 按类型对参数值进行Borsh编码，值可以是uint64、bool、公钥或字面量字符串，option类型的nil值编码为None
+func encodeBorsh(t *idlType, value interface{}) ([]byte, error) {
+	if t.kind == "option" {
+		if value == nil {
+			return []byte{0}, nil
+		}
+		inner, err := encodeBorsh(t.inner, value)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{1}, inner...), nil
+	}
+	if value == nil {
+		return nil, fmt.Errorf("value is required for %s", t.kind)
+	}
+
+	if size := t.intSize(); size > 0 {
+		buf := make([]byte, size)
+		switch v := value.(type) {
+		case uint64:
+			if size < 8 && v>>(uint(size)*8) != 0 {
+				return nil, fmt.Errorf("value %d overflows %s", v, t.kind)
+			}
+			putUint(buf, v)
+		case string:
+			if strings.HasPrefix(t.kind, "i") {
+				n, err := strconv.ParseInt(v, 10, min(size, 8)*8)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s: %w", t.kind, err)
+				}
+				putUint(buf, uint64(n))
+				if n < 0 {
+					for i := 8; i < size; i++ {
+						buf[i] = 0xff
+					}
+				}
+			} else {
+				n, err := strconv.ParseUint(v, 10, min(size, 8)*8)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s: %w", t.kind, err)
+				}
+				putUint(buf, n)
+			}
+		default:
+			return nil, fmt.Errorf("cannot encode %T as %s", value, t.kind)
+		}
+		return buf, nil
+	}
+
+	switch t.kind {
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			if v {
+				return []byte{1}, nil
+			}
+			return []byte{0}, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool: %w", err)
+			}
+			return encodeBorsh(t, b)
+		}
+	case "pubkey":
+		switch v := value.(type) {
+		case solana.PublicKey:
+			return v.Bytes(), nil
+		case string:
+			key, err := solana.PublicKeyFromBase58(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pubkey: %w", err)
+			}
+			return key.Bytes(), nil
+		}
+	case "string", "bytes":
+		if v, ok := value.(string); ok {
+			buf := make([]byte, 4, 4+len(v))
+			binary.LittleEndian.PutUint32(buf, uint32(len(v)))
+			return append(buf, v...), nil
+		}
+	}
+	return nil, fmt.Errorf("cannot encode %T as %s", value, t.kind)
+}
+
+// decodeBorsh 解码一个参数值，返回值和剩余数据；128位整数超出uint64时以十进制字符串返回
+func decodeBorsh(t *idlType, data []byte) (interface{}, []byte, error) {
+	if size := t.intSize(); size > 0 {
+		if len(data) < size {
+			return nil, nil, fmt.Errorf("not enough data for %s", t.kind)
+		}
+		buf := make([]byte, 8)
+		copy(buf, data[:min(size, 8)])
+		v := binary.LittleEndian.Uint64(buf)
+		rest := data[size:]
+		if strings.HasPrefix(t.kind, "i") {
+			shift := uint(64 - min(size, 8)*8)
+			return int64(v<<shift) >> shift, rest, nil
+		}
+		if size == 16 {
+			for _, b := range data[8:16] {
+				if b != 0 {
+					return nil, nil, fmt.Errorf("%s value overflows uint64", t.kind)
+				}
+			}
+		}
+		return v, rest, nil
+	}
+
+	switch t.kind {
+	case "option":
+		if len(data) < 1 {
+			return nil, nil, fmt.Errorf("not enough data for option")
+		}
+		if data[0] == 0 {
+			return nil, data[1:], nil
+		}
+		return decodeBorsh(t.inner, data[1:])
+	case "bool":
+		if len(data) < 1 {
+			return nil, nil, fmt.Errorf("not enough data for bool")
+		}
+		return data[0] != 0, data[1:], nil
+	case "pubkey":
+		if len(data) < solana.PublicKeyLength {
+			return nil, nil, fmt.Errorf("not enough data for pubkey")
+		}
+		return solana.PublicKeyFromBytes(data[:solana.PublicKeyLength]).String(), data[solana.PublicKeyLength:], nil
+	case "string", "bytes":
+		if len(data) < 4 {
+			return nil, nil, fmt.Errorf("not enough data for %s length", t.kind)
+		}
+		n := binary.LittleEndian.Uint32(data)
+		if uint64(len(data)-4) < uint64(n) {
+			return nil, nil, fmt.Errorf("not enough data for %s", t.kind)
+		}
+		return string(data[4 : 4+n]), data[4+n:], nil
+	}
+	return nil, nil, fmt.Errorf("unsupported type %s", t.kind)
+}
+
+// putUint 以小端序写入整数的低位字节
+func putUint(buf []byte, v uint64) {
+	for i := 0; i < len(buf) && i < 8; i++ {
+		buf[i] = byte(v >> (8 * uint(i)))
+	}
+}
+
+// constSeed 解析const种子：新格式为字节数组，旧格式按type为string或pubkey解析字符串
+func constSeed(seed idlSeed) ([]byte, error) {
+	var bytes []int
+	if err := json.Unmarshal(seed.Value, &bytes); err == nil {
+		out := make([]byte, len(bytes))
+		for i, b := range bytes {
+			if b < 0 || b > 255 {
+				return nil, fmt.Errorf("invalid const seed byte %d", b)
+			}
+			out[i] = byte(b)
+		}
+		return out, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(seed.Value, &value); err != nil {
+		return nil, fmt.Errorf("unsupported const seed %s", string(seed.Value))
+	}
+	var typ string
+	json.Unmarshal(seed.Type, &typ)
+	if typ == "publicKey" || typ == "pubkey" {
+		key, err := solana.PublicKeyFromBase58(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid const seed pubkey: %w", err)
+		}
+		return key.Bytes(), nil
+	}
+	return []byte(value), nil
+}
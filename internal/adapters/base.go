@@ -1,25 +1,25 @@
 package adapters
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"net/http"
-	"time"
 
 	"solana-dex-service/internal/config"
 	"solana-dex-service/internal/types"
+	"solana-dex-service/internal/upstream"
 
 	"github.com/gagliardetto/solana-go"
 )
 
+// defaultUpstream 未注入上游客户端时使用的默认客户端
+var defaultUpstream = upstream.New(nil)
+
 // BaseAdapter DEX适配器基础实现
 type BaseAdapter struct {
 	name   string
 	config *config.DEXConfig
-	client *http.Client
+	client *upstream.Client
 }
 
 // NewBaseAdapter 创建基础适配器
@@ -27,9 +27,7 @@ func NewBaseAdapter(name string, cfg *config.DEXConfig) *BaseAdapter {
 	return &BaseAdapter{
 		name:   name,
 		config: cfg,
-		client: &http.Client{
-			Timeout: cfg.Timeout,
-		},
+		client: defaultUpstream.WithPolicy(cfg.Timeout, cfg.RetryCount),
 	}
 }
 
@@ -43,69 +41,22 @@ func (b *BaseAdapter) GetConfig() *config.DEXConfig {
 	return b.config
 }
 
-// makeRequest 发起HTTP请求的通用方法
-func (b *BaseAdapter) makeRequest(ctx context.Context, method, url string, body interface{}, result interface{}) error {
-	var err error
-	var reqBodyBytes []byte
-
-	if body != nil {
-		reqBodyBytes, err = json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-	}
-
-	var reqBody *bytes.Reader
-	if body != nil {
-		reqBody = bytes.NewReader(reqBodyBytes)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("User-Agent", "solana-dex-service/1.0")
-
-	// 重试机制
-	var resp *http.Response
-	for i := 0; i < b.config.RetryCount; i++ {
-		resp, err = b.client.Do(req)
-		if err == nil && resp.StatusCode < 500 {
-			break
-		}
-		if resp != nil {
-			resp.Body.Close()
-		}
-		if i < b.config.RetryCount-1 {
-			time.Sleep(time.Duration(i+1) * time.Second)
-		}
-	}
-
-	if err != nil {
-		return fmt.Errorf("request failed after %d retries: %w", b.config.RetryCount, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		return fmt.Errorf("request failed with status %d", resp.StatusCode)
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
+// SetUpstreamClient 设置共享连接池的上游HTTP客户端，超时和重试次数使用DEX配置
+func (b *BaseAdapter) SetUpstreamClient(client *upstream.Client) {
+	b.client = client.WithPolicy(b.config.Timeout, b.config.RetryCount)
+}
 
-	return nil
+// makeRequest 发起HTTP请求的通用方法，GET请求的参数编码为查询参数
+func (b *BaseAdapter) makeRequest(ctx context.Context, method, url string, params interface{}, result interface{}) error {
+	return b.client.Do(ctx, method, url, params, result)
 }
 
 // ValidateSwapRequest 验证交换请求
 func (b *BaseAdapter) ValidateSwapRequest(req *types.SwapRequest) error {
 	// Basic validation
+	if req == nil {
+		return errors.New("swap request is nil")
+	}
 	if req.InputMint == "" {
 		return errors.New("input mint is required")
 	}
@@ -118,6 +69,25 @@ func (b *BaseAdapter) ValidateSwapRequest(req *types.SwapRequest) error {
 	if req.UserWallet == "" {
 		return errors.New("user wallet is required")
 	}
+	if req.Slippage < 0 || req.Slippage > 1 {
+		return errors.New("slippage must be between 0 and 1")
+	}
+
+	// 验证公钥格式
+	if _, err := solana.PublicKeyFromBase58(req.InputMint); err != nil {
+		return fmt.Errorf("invalid input mint address: %w", err)
+	}
+	if _, err := solana.PublicKeyFromBase58(req.OutputMint); err != nil {
+		return fmt.Errorf("invalid output mint address: %w", err)
+	}
+	if _, err := solana.PublicKeyFromBase58(req.UserWallet); err != nil {
+		return fmt.Errorf("invalid user wallet address: %w", err)
+	}
+	if req.FeePayer != "" {
+		if _, err := solana.PublicKeyFromBase58(req.FeePayer); err != nil {
+			return fmt.Errorf("invalid fee payer address: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -169,6 +139,12 @@ func (b *BaseAdapter) validateLiquidityRequest(req *types.LiquidityRequest) erro
 		return fmt.Errorf("invalid user wallet address: %w", err)
 	}
 
+	if req.FeePayer != "" {
+		if _, err := solana.PublicKeyFromBase58(req.FeePayer); err != nil {
+			return fmt.Errorf("invalid fee payer address: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -181,6 +157,36 @@ func (b *BaseAdapter) createInstruction(programID solana.PublicKey, accounts []s
 	}
 }
 
+// decodedInstruction 构建解码结果，并按指令账户顺序为账户命名
+func (b *BaseAdapter) decodedInstruction(name string, fields map[string]interface{}, accounts []types.DecodedAccount, accountNames ...string) *types.DecodedInstruction {
+	return &types.DecodedInstruction{
+		Program:  b.name,
+		Name:     name,
+		Fields:   fields,
+		Accounts: NameAccounts(accounts, accountNames...),
+	}
+}
+
+// NameAccounts 按顺序为指令账户命名，返回副本，多出的账户保持未命名
+func NameAccounts(accounts []types.DecodedAccount, names ...string) []types.DecodedAccount {
+	named := make([]types.DecodedAccount, len(accounts))
+	copy(named, accounts)
+	for i := range named {
+		if i < len(names) {
+			named[i].Name = names[i]
+		}
+	}
+	return named
+}
+
+// accountAt 返回指定位置的账户公钥，不存在时返回空字符串
+func accountAt(accounts []types.DecodedAccount, index int) string {
+	if index < len(accounts) {
+		return accounts[index].PublicKey
+	}
+	return ""
+}
+
 // calculateMinAmountOut 计算最小输出金额（考虑滑点）
 func (b *BaseAdapter) calculateMinAmountOut(amountOut uint64, slippage float64) uint64 {
 	if slippage <= 0 {
@@ -189,6 +195,14 @@ func (b *BaseAdapter) calculateMinAmountOut(amountOut uint64, slippage float64)
 	return uint64(float64(amountOut) * (1.0 - slippage))
 }
 
+// minAmountOut 返回交换指令的最小输出金额，请求中指定时直接使用
+func (b *BaseAdapter) minAmountOut(req *types.SwapRequest) uint64 {
+	if req.MinAmountOut > 0 {
+		return req.MinAmountOut
+	}
+	return b.calculateMinAmountOut(req.AmountIn, req.Slippage)
+}
+
 // AdapterRegistry DEX适配器注册表
 type AdapterRegistry struct {
 	adapters map[string]types.DEXAdapter
@@ -224,6 +238,16 @@ func (r *AdapterRegistry) List() []string {
 	return names
 }
 
+// FindByProgramID 根据程序ID查找适配器
+func (r *AdapterRegistry) FindByProgramID(programID string) (string, types.DEXAdapter, bool) {
+	for name, adapter := range r.adapters {
+		if adapter.GetConfig().ProgramID == programID {
+			return name, adapter, true
+		}
+	}
+	return "", nil, false
+}
+
 // GetAll 获取所有适配器
 func (r *AdapterRegistry) GetAll() map[string]types.DEXAdapter {
 	return r.adapters
@@ -0,0 +1,220 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/types"
+)
+
+// DEX状态
+const (
+	StatusOnline   = "online"   // 正常
+	StatusDegraded = "degraded" // 有失败或延迟过高，仍然放行请求
+	StatusOffline  = "offline"  // 已熔断或不可用
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"    // 放行所有请求
+	BreakerOpen     = "open"      // 拒绝所有请求，冷却后转为半开
+	BreakerHalfOpen = "half_open" // 放行一个探测请求，成功后关闭，失败后重新熔断
+)
+
+// breakerLatencyAlpha 平均延迟的指数加权系数
+const breakerLatencyAlpha = 0.2
+
+// ErrCircuitOpen 熔断期间拒绝请求
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerStatus 熔断器状态快照
+type BreakerStatus struct {
+	State               string     `json:"state"`                // closed, open, half_open
+	ConsecutiveFailures int        `json:"consecutive_failures"` // 连续失败次数
+	LatencyMs           int64      `json:"latency_ms"`           // 平均延迟（指数加权，毫秒）
+	LastError           string     `json:"last_error,omitempty"` // 最近一次失败原因
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`  // 最近一次熔断时间
+}
+
+// BreakerAdapter 为DEX适配器的上游调用增加熔断：连续失败达到阈值后熔断，冷却后放行一个探测请求
+type BreakerAdapter struct {
+	types.DEXAdapter
+	threshold int
+	cooldown  time.Duration
+	slowCall  time.Duration
+
+	mu        sync.Mutex
+	state     string
+	failures  int
+	latency   time.Duration
+	lastError string
+	openedAt  time.Time
+	probing   bool
+}
+
+// WithBreaker 按熔断配置为适配器增加熔断，未配置失败阈值时返回原适配器
+func WithBreaker(adapter types.DEXAdapter, cfg *config.BreakerConfig) types.DEXAdapter {
+	if cfg.FailureThreshold <= 0 {
+		return adapter
+	}
+	return &BreakerAdapter{
+		DEXAdapter: adapter,
+		threshold:  cfg.FailureThreshold,
+		cooldown:   cfg.Cooldown,
+		slowCall:   cfg.SlowCallThreshold,
+		state:      BreakerClosed,
+	}
+}
+
+// GetQuote 获取交易报价，熔断期间直接返回错误
+func (b *BreakerAdapter) GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
+	var quote *types.QuoteResponse
+	err := b.execute(ctx, func(ctx context.Context) (err error) {
+		quote, err = b.DEXAdapter.GetQuote(ctx, inputMint, outputMint, amountIn)
+		return err
+	})
+	return quote, err
+}
+
+// GetPools 获取流动性池信息，熔断期间直接返回错误
+func (b *BreakerAdapter) GetPools(ctx context.Context) ([]types.PoolInfo, error) {
+	var pools []types.PoolInfo
+	err := b.execute(ctx, func(ctx context.Context) (err error) {
+		pools, err = b.DEXAdapter.GetPools(ctx)
+		return err
+	})
+	return pools, err
+}
+
+// execute 经过熔断器执行一次报价或池子查询，并记录结果和延迟
+// 调用方取消的请求不计入失败，超时仍计入失败
+func (b *BreakerAdapter) execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := b.allow(); err != nil {
+		return err
+	}
+	start := time.Now()
+	err := fn(ctx)
+	if errors.Is(err, context.Canceled) {
+		b.release()
+		return err
+	}
+	b.record(err, time.Since(start))
+	return err
+}
+
+// allow 判断是否放行请求，冷却结束后只放行一个探测请求
+func (b *BreakerAdapter) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+	}
+	switch b.state {
+	case BreakerOpen:
+		return fmt.Errorf("%w for %s, retry after %s", ErrCircuitOpen, b.GetName(), (b.cooldown - time.Since(b.openedAt)).Truncate(time.Millisecond))
+	case BreakerHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w for %s, probe in progress", ErrCircuitOpen, b.GetName())
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// release 释放被取消的探测请求占用的名额，不改变熔断状态
+func (b *BreakerAdapter) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// record 记录调用结果：探测成功后关闭熔断，探测失败或连续失败达到阈值时熔断
+func (b *BreakerAdapter) record(err error, latency time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.latency == 0 {
+		b.latency = latency
+	} else {
+		b.latency = time.Duration(breakerLatencyAlpha*float64(latency) + (1-breakerLatencyAlpha)*float64(b.latency))
+	}
+
+	probe := b.probing
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.state = BreakerClosed
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+	if probe || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Breaker 返回熔断器状态快照
+func (b *BreakerAdapter) Breaker() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		state = BreakerHalfOpen
+	}
+	status := BreakerStatus{
+		State:               state,
+		ConsecutiveFailures: b.failures,
+		LatencyMs:           b.latency.Milliseconds(),
+		LastError:           b.lastError,
+	}
+	if !b.openedAt.IsZero() {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// Status 将熔断器状态映射为DEX状态：熔断为offline，半开、有连续失败或平均延迟过高为degraded
+func (b *BreakerAdapter) Status() string {
+	status := b.Breaker()
+	switch {
+	case status.State == BreakerOpen:
+		return StatusOffline
+	case status.State == BreakerHalfOpen, status.ConsecutiveFailures > 0:
+		return StatusDegraded
+	case b.slowCall > 0 && time.Duration(status.LatencyMs)*time.Millisecond > b.slowCall:
+		return StatusDegraded
+	}
+	return StatusOnline
+}
+
+// Unwrap 返回被包装的适配器
+func (b *BreakerAdapter) Unwrap() types.DEXAdapter {
+	return b.DEXAdapter
+}
+
+// FindBreaker 沿包装链查找适配器的熔断器，未启用熔断时返回nil
+func FindBreaker(adapter types.DEXAdapter) *BreakerAdapter {
+	for adapter != nil {
+		if breaker, ok := adapter.(*BreakerAdapter); ok {
+			return breaker
+		}
+		wrapper, ok := adapter.(interface{ Unwrap() types.DEXAdapter })
+		if !ok {
+			return nil
+		}
+		adapter = wrapper.Unwrap()
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package adapters
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/types"
+)
+
+// Factory 根据DEX配置创建适配器
+type Factory func(cfg *config.DEXConfig) (types.DEXAdapter, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// 内置的适配器类型
+func init() {
+	RegisterFactory("raydium", factoryOf(NewRaydiumAdapter))
+	RegisterFactory("pumpfun", factoryOf(NewPumpfunAdapter))
+	RegisterFactory("pumpswap", factoryOf(NewPumpSwapAdapter))
+	RegisterFactory("anchor", factoryOf(NewAnchorAdapter))
+}
+
+// factoryOf 将返回具体适配器类型的构造函数转换为Factory，创建失败时返回nil接口而不是带类型的nil指针
+func factoryOf[T types.DEXAdapter](newAdapter func(cfg *config.DEXConfig) (T, error)) Factory {
+	return func(cfg *config.DEXConfig) (types.DEXAdapter, error) {
+		adapter, err := newAdapter(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return adapter, nil
+	}
+}
+
+// RegisterFactory 注册适配器类型，同一类型重复注册时panic
+func RegisterFactory(dexType string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("adapters: RegisterFactory factory is nil for type " + dexType)
+	}
+	if _, exists := factories[dexType]; exists {
+		panic("adapters: RegisterFactory called twice for type " + dexType)
+	}
+	factories[dexType] = factory
+}
+
+// FactoryTypes 返回已注册的适配器类型，按名称排序
+func FactoryTypes() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	dexTypes := make([]string, 0, len(factories))
+	for dexType := range factories {
+		dexTypes = append(dexTypes, dexType)
+	}
+	sort.Strings(dexTypes)
+	return dexTypes
+}
+
+// NewAdapter 按DEX配置的类型创建适配器，同一类型可以创建多个使用不同配置的实例
+func NewAdapter(cfg *config.DEXConfig) (types.DEXAdapter, error) {
+	dexType := cfg.AdapterType()
+
+	factoriesMu.RLock()
+	factory, exists := factories[dexType]
+	factoriesMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown dex type %q (registered: %s)", dexType, strings.Join(FactoryTypes(), ", "))
+	}
+
+	adapter, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s adapter: %w", dexType, err)
+	}
+	return adapter, nil
+}
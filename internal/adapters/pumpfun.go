@@ -31,9 +31,7 @@ func NewPumpfunAdapter(cfg *config.DEXConfig) (*PumpfunAdapter, error) {
 }
 
 // GetQuote 获取交易报价
-func (p *PumpfunAdapter) GetQuote(inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
-	ctx := context.Background()
-	
+func (p *PumpfunAdapter) GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
 	// 构建请求URL
 	quoteURL := fmt.Sprintf("%s/quote", p.config.Endpoints["api"])
 	if p.config.Endpoints["quote"] != "" {
@@ -154,9 +152,7 @@ func (p *PumpfunAdapter) BuildLiquidityInstruction(req *types.LiquidityRequest)
 }
 
 // GetPools 获取流动性池信息
-func (p *PumpfunAdapter) GetPools() ([]types.PoolInfo, error) {
-	ctx := context.Background()
-	
+func (p *PumpfunAdapter) GetPools(ctx context.Context) ([]types.PoolInfo, error) {
 	poolsURL := fmt.Sprintf("%s/tokens", p.config.Endpoints["api"])
 
 	var tokensResp struct {
@@ -233,7 +229,7 @@ func (p *PumpfunAdapter) buildSwapInstructionData(req *types.SwapRequest) []byte
 	binary.LittleEndian.PutUint64(data[1:9], req.AmountIn)
 	
 	// 最小输出金额（考虑滑点）
-	minAmountOut := p.calculateMinAmountOut(req.AmountIn, req.Slippage)
+	minAmountOut := p.minAmountOut(req)
 	binary.LittleEndian.PutUint64(data[9:17], minAmountOut)
 	
 	// 交易方向 (0: 买入, 1: 卖出)
@@ -272,4 +268,34 @@ func (p *PumpfunAdapter) deriveBondingCurveTokenAccount(bondingCurve, mint solan
 	}
 	
 	return address, nil
-}
\ No newline at end of file
+}
+
+// DecodeInstruction 解码Pumpfun指令，与buildSwapInstructionData对应
+func (p *PumpfunAdapter) DecodeInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty instruction data")
+	}
+
+	var name string
+	switch data[0] {
+	case 6:
+		name = "buy"
+	case 7:
+		name = "sell"
+	default:
+		return nil, fmt.Errorf("unknown pumpfun instruction: %d", data[0])
+	}
+	if len(data) != 18 {
+		return nil, fmt.Errorf("invalid %s instruction data length: %d", name, len(data))
+	}
+
+	fields := map[string]interface{}{
+		"amount_in":      binary.LittleEndian.Uint64(data[1:9]),
+		"min_amount_out": binary.LittleEndian.Uint64(data[9:17]),
+		"bonding_curve":  accountAt(accounts, 3),
+	}
+	return p.decodedInstruction(name, fields, accounts,
+		"user_wallet", "user_input_token_account", "user_output_token_account",
+		"bonding_curve", "bonding_curve_token_account", "input_mint", "output_mint",
+		"token_program", "system_program"), nil
+}
@@ -38,9 +38,7 @@ func NewPumpSwapAdapter(cfg *config.DEXConfig) (*PumpSwapAdapter, error) {
 }
 
 // GetQuote 获取交易报价
-func (ps *PumpSwapAdapter) GetQuote(inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
-	ctx := context.Background()
-	
+func (ps *PumpSwapAdapter) GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
 	// 构建请求URL
 	quoteURL := ps.config.Endpoints["quote"]
 	if quoteURL == "" {
@@ -89,7 +87,7 @@ func (ps *PumpSwapAdapter) GetQuote(inputMint, outputMint string, amountIn uint6
 	var routes []types.Route
 	for _, r := range quoteResp.Data.Route {
 		routes = append(routes, types.Route{
-			DEX:        "pumpswap",
+			DEX:        ps.GetName(),
 			PoolID:     r.PoolID,
 			InputMint:  r.InputMint,
 			OutputMint: r.OutputMint,
@@ -250,9 +248,7 @@ func (ps *PumpSwapAdapter) BuildLiquidityInstruction(req *types.LiquidityRequest
 }
 
 // GetPools 获取流动性池信息
-func (ps *PumpSwapAdapter) GetPools() ([]types.PoolInfo, error) {
-	ctx := context.Background()
-	
+func (ps *PumpSwapAdapter) GetPools(ctx context.Context) ([]types.PoolInfo, error) {
 	poolsURL := fmt.Sprintf("%s/pools", ps.config.Endpoints["api"])
 
 	var poolsResp struct {
@@ -328,7 +324,7 @@ func (ps *PumpSwapAdapter) buildSwapInstructionData(req *types.SwapRequest) []by
 	binary.LittleEndian.PutUint64(data[1:9], req.AmountIn)
 	
 	// 最小输出金额（考虑滑点）
-	minAmountOut := ps.calculateMinAmountOut(req.AmountIn, req.Slippage)
+	minAmountOut := ps.minAmountOut(req)
 	binary.LittleEndian.PutUint64(data[9:17], minAmountOut)
 	
 	return data
@@ -417,4 +413,45 @@ func (ps *PumpSwapAdapter) deriveLPMintAddress(poolAddress solana.PublicKey) (so
 	}
 	
 	return address, nil
-}
\ No newline at end of file
+}
+
+// DecodeInstruction 解码PumpSwap指令，与buildSwapInstructionData和buildLiquidityInstructionData对应
+func (ps *PumpSwapAdapter) DecodeInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty instruction data")
+	}
+
+	switch data[0] {
+	case 1:
+		if len(data) != 17 {
+			return nil, fmt.Errorf("invalid swap instruction data length: %d", len(data))
+		}
+		fields := map[string]interface{}{
+			"amount_in":      binary.LittleEndian.Uint64(data[1:9]),
+			"min_amount_out": binary.LittleEndian.Uint64(data[9:17]),
+			"pool":           accountAt(accounts, 3),
+		}
+		return ps.decodedInstruction("swap", fields, accounts,
+			"user_wallet", "user_input_token_account", "user_output_token_account",
+			"pool", "pool_input_token_account", "pool_output_token_account",
+			"router", "input_mint", "output_mint", "token_program"), nil
+	case 2, 3:
+		if len(data) != 18 {
+			return nil, fmt.Errorf("invalid liquidity instruction data length: %d", len(data))
+		}
+		name := "add_liquidity"
+		if data[0] == 3 {
+			name = "remove_liquidity"
+		}
+		fields := map[string]interface{}{
+			"amount_a": binary.LittleEndian.Uint64(data[2:10]),
+			"amount_b": binary.LittleEndian.Uint64(data[10:18]),
+			"pool":     accountAt(accounts, 4),
+		}
+		return ps.decodedInstruction(name, fields, accounts,
+			"user_wallet", "user_token_a_account", "user_token_b_account", "user_lp_token_account",
+			"pool", "lp_mint", "token_a_mint", "token_b_mint", "token_program"), nil
+	default:
+		return nil, fmt.Errorf("unknown pumpswap instruction: %d", data[0])
+	}
+}
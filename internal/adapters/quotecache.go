@@ -0,0 +1,189 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/types"
+)
+
+const (
+	// QuoteCacheHit 报价来自缓存
+	QuoteCacheHit = "hit"
+	// QuoteCacheMiss 报价来自上游DEX接口
+	QuoteCacheMiss = "miss"
+	// QuoteCacheCoalesced 报价与正在进行的相同请求合并
+	QuoteCacheCoalesced = "coalesced"
+
+	// maxQuoteCacheEntries 缓存条目超过该数量时清理过期条目
+	maxQuoteCacheEntries = 1024
+)
+
+// QuoteCacher 可以返回报价缓存结果的适配器
+type QuoteCacher interface {
+	GetQuoteCached(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, string, error)
+}
+
+// GetQuoteWithCacheStatus 获取报价及缓存结果，适配器未启用缓存时缓存结果为空
+func GetQuoteWithCacheStatus(ctx context.Context, adapter types.DEXAdapter, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, string, error) {
+	if cacher, ok := adapter.(QuoteCacher); ok {
+		return cacher.GetQuoteCached(ctx, inputMint, outputMint, amountIn)
+	}
+	quote, err := adapter.GetQuote(ctx, inputMint, outputMint, amountIn)
+	return quote, "", err
+}
+
+// quoteKey 缓存键：代币对和金额区间
+type quoteKey struct {
+	inputMint  string
+	outputMint string
+	bucket     uint64
+}
+
+// quoteEntry 缓存的报价
+type quoteEntry struct {
+	quote     *types.QuoteResponse
+	expiresAt time.Time
+}
+
+// quoteCall 正在进行的上游询价，完成时关闭done
+type quoteCall struct {
+	done  chan struct{}
+	quote *types.QuoteResponse
+	err   error
+}
+
+// CachedAdapter 在DEX适配器的GetQuote前增加报价缓存，并合并正在进行的相同询价
+type CachedAdapter struct {
+	types.DEXAdapter
+	ttl       time.Duration
+	bucketBps int
+
+	mu       sync.Mutex
+	entries  map[quoteKey]quoteEntry
+	inflight map[quoteKey]*quoteCall
+}
+
+// WithQuoteCache 按DEX配置为适配器增加报价缓存，未配置缓存时间时返回原适配器
+func WithQuoteCache(adapter types.DEXAdapter, cfg *config.DEXConfig) types.DEXAdapter {
+	if cfg.QuoteCacheTTL <= 0 {
+		return adapter
+	}
+	return &CachedAdapter{
+		DEXAdapter: adapter,
+		ttl:        cfg.QuoteCacheTTL,
+		bucketBps:  cfg.QuoteBucketBps,
+		entries:    make(map[quoteKey]quoteEntry),
+		inflight:   make(map[quoteKey]*quoteCall),
+	}
+}
+
+// GetQuote 获取交易报价，优先使用缓存
+func (c *CachedAdapter) GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
+	quote, _, err := c.GetQuoteCached(ctx, inputMint, outputMint, amountIn)
+	return quote, err
+}
+
+// GetQuoteCached 获取交易报价并返回缓存结果
+// 同一金额区间内的缓存报价按输入金额等比例换算，失败的询价不缓存
+// 合并的请求各自等待自己的ctx；发起询价的请求被取消时，仍在等待的请求重新询价
+func (c *CachedAdapter) GetQuoteCached(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, string, error) {
+	key := quoteKey{inputMint: inputMint, outputMint: outputMint, bucket: c.bucket(amountIn)}
+
+	for {
+		c.mu.Lock()
+		if entry, ok := c.entries[key]; ok && time.Now().Before(entry.expiresAt) {
+			c.mu.Unlock()
+			return scaleQuote(entry.quote, amountIn), QuoteCacheHit, nil
+		}
+		call, ok := c.inflight[key]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, QuoteCacheCoalesced, ctx.Err()
+		case <-call.done:
+		}
+		if call.err != nil {
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return nil, QuoteCacheCoalesced, call.err
+		}
+		return scaleQuote(call.quote, amountIn), QuoteCacheCoalesced, nil
+	}
+	call := &quoteCall{done: make(chan struct{})}
+	c.inflight[key] = call
+	c.mu.Unlock()
+
+	call.quote, call.err = c.DEXAdapter.GetQuote(ctx, inputMint, outputMint, amountIn)
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if call.err == nil && call.quote != nil {
+		if len(c.entries) >= maxQuoteCacheEntries {
+			c.evictExpired()
+		}
+		c.entries[key] = quoteEntry{quote: call.quote, expiresAt: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	if call.err != nil {
+		return nil, QuoteCacheMiss, call.err
+	}
+	return scaleQuote(call.quote, amountIn), QuoteCacheMiss, nil
+}
+
+// Unwrap 返回被包装的适配器
+func (c *CachedAdapter) Unwrap() types.DEXAdapter {
+	return c.DEXAdapter
+}
+
+// bucket 金额区间：未配置区间宽度时按精确金额，否则按宽度为bucketBps的对数区间
+func (c *CachedAdapter) bucket(amountIn uint64) uint64 {
+	if c.bucketBps <= 0 || amountIn == 0 {
+		return amountIn
+	}
+	return uint64(math.Log(float64(amountIn)) / math.Log1p(float64(c.bucketBps)/10000))
+}
+
+// evictExpired 清理过期条目，调用方需持有锁
+func (c *CachedAdapter) evictExpired() {
+	now := time.Now()
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// isContextError 判断错误是否由ctx取消或超时引起
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// scaleQuote 复制报价，输入金额不同时按比例换算输出、最小输出和手续费
+// 价格影响按恒定乘积池换算：影响为 x/(R+x)，由原报价反推储备量R后代入新的输入金额
+func scaleQuote(quote *types.QuoteResponse, amountIn uint64) *types.QuoteResponse {
+	scaled := *quote
+	if quote.AmountIn == 0 || quote.AmountIn == amountIn {
+		return &scaled
+	}
+	ratio := float64(amountIn) / float64(quote.AmountIn)
+	scaled.AmountIn = amountIn
+	scaled.AmountOut = uint64(float64(quote.AmountOut) * ratio)
+	scaled.MinAmountOut = uint64(float64(quote.MinAmountOut) * ratio)
+	scaled.Fee = uint64(float64(quote.Fee) * ratio)
+	if quote.PriceImpact > 0 && quote.PriceImpact < 1 {
+		scaled.PriceImpact = ratio * quote.PriceImpact / (1 - quote.PriceImpact + ratio*quote.PriceImpact)
+	}
+	return &scaled
+}
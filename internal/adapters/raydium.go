@@ -31,16 +31,14 @@ func NewRaydiumAdapter(cfg *config.DEXConfig) (*RaydiumAdapter, error) {
 }
 
 // GetQuote 获取交易报价
-func (r *RaydiumAdapter) GetQuote(inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
-	ctx := context.Background()
-	
+func (r *RaydiumAdapter) GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
 	// 构建请求URL
 	quoteURL := r.config.Endpoints["quote"]
 	if quoteURL == "" {
 		return nil, fmt.Errorf("quote endpoint not configured")
 	}
 
-	// 构建请求参数
+	// 构建请求参数，GET请求编码为查询参数
 	reqParams := map[string]interface{}{
 		"inputMint":  inputMint,
 		"outputMint": outputMint,
@@ -187,9 +185,7 @@ func (r *RaydiumAdapter) BuildLiquidityInstruction(req *types.LiquidityRequest)
 }
 
 // GetPools 获取流动性池信息
-func (r *RaydiumAdapter) GetPools() ([]types.PoolInfo, error) {
-	ctx := context.Background()
-	
+func (r *RaydiumAdapter) GetPools(ctx context.Context) ([]types.PoolInfo, error) {
 	poolsURL := r.config.Endpoints["pools"]
 	if poolsURL == "" {
 		return nil, fmt.Errorf("pools endpoint not configured")
@@ -266,7 +262,7 @@ func (r *RaydiumAdapter) buildSwapInstructionData(req *types.SwapRequest) []byte
 	binary.LittleEndian.PutUint64(data[1:9], req.AmountIn)
 	
 	// 最小输出金额（考虑滑点）
-	minAmountOut := r.calculateMinAmountOut(req.AmountIn, req.Slippage)
+	minAmountOut := r.minAmountOut(req)
 	binary.LittleEndian.PutUint64(data[9:17], minAmountOut)
 	
 	return data
@@ -295,4 +291,42 @@ func (r *RaydiumAdapter) buildLiquidityInstructionData(req *types.LiquidityReque
 	binary.LittleEndian.PutUint64(data[10:18], req.AmountB)
 	
 	return data
-}
\ No newline at end of file
+}
+
+// DecodeInstruction 解码Raydium指令，与buildSwapInstructionData和buildLiquidityInstructionData对应
+func (r *RaydiumAdapter) DecodeInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty instruction data")
+	}
+
+	switch data[0] {
+	case 9:
+		if len(data) != 17 {
+			return nil, fmt.Errorf("invalid swap instruction data length: %d", len(data))
+		}
+		fields := map[string]interface{}{
+			"amount_in":      binary.LittleEndian.Uint64(data[1:9]),
+			"min_amount_out": binary.LittleEndian.Uint64(data[9:17]),
+		}
+		return r.decodedInstruction("swap", fields, accounts,
+			"user_wallet", "user_input_token_account", "user_output_token_account",
+			"input_mint", "output_mint", "token_program"), nil
+	case 10:
+		if len(data) != 18 {
+			return nil, fmt.Errorf("invalid liquidity instruction data length: %d", len(data))
+		}
+		name := "add_liquidity"
+		if data[1] == 1 {
+			name = "remove_liquidity"
+		}
+		fields := map[string]interface{}{
+			"amount_a": binary.LittleEndian.Uint64(data[2:10]),
+			"amount_b": binary.LittleEndian.Uint64(data[10:18]),
+		}
+		return r.decodedInstruction(name, fields, accounts,
+			"user_wallet", "user_token_a_account", "user_token_b_account",
+			"token_a_mint", "token_b_mint", "token_program"), nil
+	default:
+		return nil, fmt.Errorf("unknown raydium instruction: %d", data[0])
+	}
+}
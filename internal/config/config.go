@@ -3,27 +3,38 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"time"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Config 应用程序配置
 type Config struct {
-	Server   ServerConfig `yaml:"server"`
-	Solana   SolanaConfig `yaml:"solana"`
-	DEXes    []DEXConfig  `yaml:"dexes"`
-	Logging  LogConfig    `yaml:"logging"`
-	Security SecurityConfig `yaml:"security"`
+	Server   ServerConfig      `yaml:"server"`
+	Solana   SolanaConfig      `yaml:"solana"`
+	DEXes    []DEXConfig       `yaml:"dexes"`
+	Logging  LogConfig         `yaml:"logging"`
+	Security SecurityConfig    `yaml:"security"`
+	Storage  StorageConfig     `yaml:"storage"`
+	Signer   SignerConfig      `yaml:"signer"`
+	Routing  RoutingConfig     `yaml:"routing"`
+	Batch    BatchConfig       `yaml:"batch"`
+	Breaker  BreakerConfig     `yaml:"circuit_breaker"`
+	Health   HealthCheckConfig `yaml:"health_check"`
+	Upstream UpstreamConfig    `yaml:"upstream"`
+
+	layers *layerInfo // 各字段的来源，分层加载时记录
 }
 
 // ServerConfig HTTP服务器配置
 type ServerConfig struct {
-	Port         int           `yaml:"port"`
-	Host         string        `yaml:"host"`
-	Mode         string        `yaml:"mode"` // debug, release, test
-	ReadTimeout  time.Duration `yaml:"read_timeout"`
-	WriteTimeout time.Duration `yaml:"write_timeout"`
+	Port          int           `yaml:"port"`
+	Host          string        `yaml:"host"`
+	Mode          string        `yaml:"mode"` // debug, release, test
+	ReadTimeout   time.Duration `yaml:"read_timeout"`
+	WriteTimeout  time.Duration `yaml:"write_timeout"`
+	QuoteTimeout  time.Duration `yaml:"quote_timeout"`  // 报价、路由和流动性池查询的超时时间
+	EncodeTimeout time.Duration `yaml:"encode_timeout"` // 交易编码（包括获取区块哈希）的超时时间
+	SubmitTimeout time.Duration `yaml:"submit_timeout"` // 交易测试、模拟、发送和状态查询的超时时间
 }
 
 // SolanaConfig Solana网络配置
@@ -34,19 +45,61 @@ type SolanaConfig struct {
 	Timeout     time.Duration `yaml:"timeout"`
 	RetryCount  int           `yaml:"retry_count"`
 	Commitment  string        `yaml:"commitment"` // processed, confirmed, finalized
+
+	BlockhashCommitment      string        `yaml:"blockhash_commitment"`       // 获取区块哈希使用的确认级别
+	BlockhashRefreshInterval time.Duration `yaml:"blockhash_refresh_interval"` // 区块哈希后台刷新间隔
+
+	RPCEndpoints        []RPCEndpointConfig `yaml:"rpc_endpoints"`         // 多RPC端点，为空时使用rpc_url
+	HealthCheckInterval time.Duration       `yaml:"health_check_interval"` // RPC端点健康检查间隔
+	MaxSlotLag          uint64              `yaml:"max_slot_lag"`          // 允许落后于最高slot的数量，超过后降级
+}
+
+// RPCEndpointConfig RPC端点配置
+type RPCEndpointConfig struct {
+	URL    string   `yaml:"url"`
+	Weight int      `yaml:"weight"` // 权重，默认1
+	Roles  []string `yaml:"roles"`  // read, send，默认两者都有
+}
+
+// GetRPCEndpoints 获取RPC端点列表，未配置rpc_endpoints时使用rpc_url
+func (s *SolanaConfig) GetRPCEndpoints() []RPCEndpointConfig {
+	if len(s.RPCEndpoints) > 0 {
+		return s.RPCEndpoints
+	}
+	return []RPCEndpointConfig{{URL: s.RPCURL, Weight: 1}}
 }
 
 // DEXConfig DEX配置
 type DEXConfig struct {
-	Name          string            `yaml:"name"`
-	ProgramID     string            `yaml:"program_id"`
-	RouterAddress string            `yaml:"router_address"`
-	Endpoints     map[string]string `yaml:"endpoints"`
-	Enabled       bool              `yaml:"enabled"`
-	Timeout       time.Duration     `yaml:"timeout"`
-	RetryCount    int               `yaml:"retry_count"`
-	CreatedAt     time.Time         `yaml:"created_at"`
-	UpdatedAt     time.Time         `yaml:"updated_at"`
+	Name           string                             `yaml:"name"`
+	Type           string                             `yaml:"type"` // 适配器类型（raydium、pumpfun、pumpswap、anchor），未配置时与名称相同
+	ProgramID      string                             `yaml:"program_id"`
+	RouterAddress  string                             `yaml:"router_address"`
+	Endpoints      map[string]string                  `yaml:"endpoints"`
+	Enabled        bool                               `yaml:"enabled"`
+	Timeout        time.Duration                      `yaml:"timeout"`
+	RetryCount     int                                `yaml:"retry_count"`
+	QuoteCacheTTL  time.Duration                      `yaml:"quote_cache_ttl"`  // 报价缓存时间，0表示不缓存
+	QuoteBucketBps int                                `yaml:"quote_bucket_bps"` // 报价缓存的金额区间宽度（基点），0表示按精确金额缓存
+	IDLPath        string                             `yaml:"idl_path"`         // Anchor IDL JSON文件路径（type为anchor时必填）
+	Instructions   map[string]AnchorInstructionConfig `yaml:"instructions"`     // 操作（swap、add_liquidity、remove_liquidity）到IDL指令的映射（type为anchor时使用）
+	CreatedAt      time.Time                          `yaml:"created_at"`
+	UpdatedAt      time.Time                          `yaml:"updated_at"`
+}
+
+// AnchorInstructionConfig 通用Anchor适配器中一个操作到IDL指令的映射
+type AnchorInstructionConfig struct {
+	Instruction string            `yaml:"instruction"` // IDL中的指令名称
+	Args        map[string]string `yaml:"args"`        // IDL参数名 -> 请求字段，或 const:字面量
+	Accounts    map[string]string `yaml:"accounts"`    // IDL账户名 -> 请求字段、程序别名或公钥，IDL中声明了地址或PDA的账户可以省略
+}
+
+// AdapterType 返回创建适配器使用的类型，未配置类型时使用DEX名称
+func (d *DEXConfig) AdapterType() string {
+	if d.Type != "" {
+		return d.Type
+	}
+	return d.Name
 }
 
 // LogConfig 日志配置
@@ -69,62 +122,77 @@ type SecurityConfig struct {
 	MaxRequestSize int64  `yaml:"max_request_size"` // bytes
 }
 
-// LoadConfig 从文件加载配置
-func LoadConfig(configPath string) (*Config, error) {
-	// 检查配置文件是否存在
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("config file not found: %s", configPath)
-	}
-
-	// 读取配置文件
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
+// StorageConfig 持久化存储配置
+type StorageConfig struct {
+	Path              string `yaml:"path"`                // 交易记录数据库文件路径
+	ConfigHistoryPath string `yaml:"config_history_path"` // 配置版本历史数据库文件路径
+}
 
-	// 解析YAML配置
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
-	}
+// SignerConfig 交易签名器配置
+type SignerConfig struct {
+	Backend        string        `yaml:"backend"`          // keystore, remote
+	KeystoreDir    string        `yaml:"keystore_dir"`     // 本地密钥库目录
+	PassphraseEnv  string        `yaml:"passphrase_env"`   // 密钥库口令所在的环境变量
+	PassphraseFile string        `yaml:"passphrase_file"`  // 密钥库口令文件（环境变量未设置时使用）
+	RemoteURL      string        `yaml:"remote_url"`       // 远程签名服务地址
+	RemoteTokenEnv string        `yaml:"remote_token_env"` // 远程签名服务认证令牌所在的环境变量
+	Timeout        time.Duration `yaml:"timeout"`          // 远程签名请求超时
+}
 
-	// 验证配置
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
-	}
+// RoutingConfig 跨DEX智能路由配置
+type RoutingConfig struct {
+	QuoteTimeout      time.Duration `yaml:"quote_timeout"`      // 并发询价的截止时间，超时未返回的DEX不参与比较
+	MaxHops           int           `yaml:"max_hops"`           // 多跳路由的最大跳数（1-3），默认1只比较直接报价，多跳需显式开启
+	MaxPaths          int           `yaml:"max_paths"`          // 每次询价最多评估的多跳路径数量
+	IntermediateMints []string      `yaml:"intermediate_mints"` // 允许作为中间代币的地址，为空时不限制
+	PoolGraphTTL      time.Duration `yaml:"pool_graph_ttl"`     // 池子图缓存时间
+	SplitParts        int           `yaml:"split_parts"`        // 拆单时输入金额的等分份数，每份分配给边际价格最优的DEX
+	MaxSplitVenues    int           `yaml:"max_split_venues"`   // 拆单最多使用的DEX数量
+}
 
-	// 设置默认值
-	config.SetDefaults()
+// BatchConfig 批量编码配置
+type BatchConfig struct {
+	MaxItems int           `yaml:"max_items"` // 单次批量请求最多包含的条目数
+	Workers  int           `yaml:"workers"`   // 并发编码的工作协程数量
+	Timeout  time.Duration `yaml:"timeout"`   // 整个批量请求的截止时间，超时未完成的条目返回错误
+}
 
-	return &config, nil
+// BreakerConfig DEX适配器熔断配置
+type BreakerConfig struct {
+	FailureThreshold  int           `yaml:"failure_threshold"`   // 连续失败多少次后熔断，0表示不启用熔断
+	Cooldown          time.Duration `yaml:"cooldown"`            // 熔断后多久放行一次探测请求
+	SlowCallThreshold time.Duration `yaml:"slow_call_threshold"` // 平均延迟超过该值时标记为degraded
 }
 
-// Validate 验证配置的有效性
-func (c *Config) Validate() error {
-	// 验证服务器配置
-	if c.Server.Port <= 0 || c.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", c.Server.Port)
-	}
+// HealthCheckConfig DEX主动健康检查配置
+type HealthCheckConfig struct {
+	Interval    time.Duration `yaml:"interval"`     // 检查周期，0表示不启用后台检查
+	Timeout     time.Duration `yaml:"timeout"`      // 单次探测超时
+	History     int           `yaml:"history"`      // 每个DEX保留的最近检查次数
+	ProbeMethod string        `yaml:"probe_method"` // 探测端点使用的HTTP方法：HEAD或GET
+	MaxStatus   int           `yaml:"max_status"`   // 端点返回的状态码小于该值视为可达
+}
 
-	// 验证Solana配置
-	if c.Solana.RPCURL == "" {
-		return fmt.Errorf("solana rpc_url is required")
-	}
+// UpstreamConfig DEX适配器上游HTTP调用配置，单次超时和重试次数在各DEX配置中设置
+type UpstreamConfig struct {
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host"` // 每个上游主机保留的空闲连接数
+	MaxConnsPerHost     int           `yaml:"max_conns_per_host"`      // 每个上游主机的最大连接数，0表示不限制
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout"`       // 空闲连接关闭时间
+	MaxResponseBytes    int64         `yaml:"max_response_bytes"`      // 响应体大小上限
+	BackoffBase         time.Duration `yaml:"backoff_base"`            // 重试退避的初始时间，之后按指数增长并加入随机抖动
+	BackoffMax          time.Duration `yaml:"backoff_max"`             // 单次重试等待的上限，Retry-After超过该值时不再重试
+}
 
-	if c.Solana.Network == "" {
-		return fmt.Errorf("solana network is required")
-	}
+// LoadConfig 从文件加载配置，并应用 DEX_ 开头的环境变量覆盖
+func LoadConfig(configPath string) (*Config, error) {
+	return LoadConfigWithEnv(configPath, os.LookupEnv)
+}
 
-	// 验证DEX配置
-	for i, dex := range c.DEXes {
-		if dex.Name == "" {
-			return fmt.Errorf("dex[%d] name is required", i)
-		}
-		if dex.ProgramID == "" {
-			return fmt.Errorf("dex[%d] program_id is required", i)
-		}
+// Validate 验证配置的有效性，返回的错误为包含所有问题的ValidationErrors
+func (c *Config) Validate() error {
+	if errs := c.ValidateAll(); len(errs) > 0 {
+		return errs
 	}
-
 	return nil
 }
 
@@ -143,6 +211,15 @@ func (c *Config) SetDefaults() {
 	if c.Server.WriteTimeout == 0 {
 		c.Server.WriteTimeout = 30 * time.Second
 	}
+	if c.Server.QuoteTimeout == 0 {
+		c.Server.QuoteTimeout = 10 * time.Second
+	}
+	if c.Server.EncodeTimeout == 0 {
+		c.Server.EncodeTimeout = 15 * time.Second
+	}
+	if c.Server.SubmitTimeout == 0 {
+		c.Server.SubmitTimeout = 25 * time.Second
+	}
 
 	// Solana默认值
 	if c.Solana.Timeout == 0 {
@@ -154,9 +231,24 @@ func (c *Config) SetDefaults() {
 	if c.Solana.Commitment == "" {
 		c.Solana.Commitment = "confirmed"
 	}
+	if c.Solana.BlockhashCommitment == "" {
+		c.Solana.BlockhashCommitment = c.Solana.Commitment
+	}
+	if c.Solana.BlockhashRefreshInterval == 0 {
+		c.Solana.BlockhashRefreshInterval = 10 * time.Second
+	}
+	if c.Solana.HealthCheckInterval == 0 {
+		c.Solana.HealthCheckInterval = 10 * time.Second
+	}
+	if c.Solana.MaxSlotLag == 0 {
+		c.Solana.MaxSlotLag = 50
+	}
 
 	// DEX默认值
 	for i := range c.DEXes {
+		if c.DEXes[i].Type == "" {
+			c.DEXes[i].Type = c.DEXes[i].Name
+		}
 		if c.DEXes[i].Timeout == 0 {
 			c.DEXes[i].Timeout = 30 * time.Second
 		}
@@ -166,7 +258,9 @@ func (c *Config) SetDefaults() {
 		if c.DEXes[i].CreatedAt.IsZero() {
 			c.DEXes[i].CreatedAt = time.Now()
 		}
-		c.DEXes[i].UpdatedAt = time.Now()
+		if c.DEXes[i].UpdatedAt.IsZero() {
+			c.DEXes[i].UpdatedAt = c.DEXes[i].CreatedAt
+		}
 	}
 
 	// 日志默认值
@@ -187,6 +281,111 @@ func (c *Config) SetDefaults() {
 	if c.Security.MaxRequestSize == 0 {
 		c.Security.MaxRequestSize = 1024 * 1024 // 1MB
 	}
+
+	// 存储默认值
+	if c.Storage.Path == "" {
+		c.Storage.Path = "data/transactions.db"
+	}
+	if c.Storage.ConfigHistoryPath == "" {
+		c.Storage.ConfigHistoryPath = "data/config_history.db"
+	}
+
+	// 签名器默认值
+	if c.Signer.Backend == "" {
+		c.Signer.Backend = "keystore"
+	}
+	if c.Signer.KeystoreDir == "" {
+		c.Signer.KeystoreDir = "data/keystore"
+	}
+	if c.Signer.PassphraseEnv == "" {
+		c.Signer.PassphraseEnv = "DEX_KEYSTORE_PASSPHRASE"
+	}
+	if c.Signer.Timeout == 0 {
+		c.Signer.Timeout = 10 * time.Second
+	}
+
+	// 路由默认值
+	if c.Routing.QuoteTimeout == 0 {
+		c.Routing.QuoteTimeout = 3 * time.Second
+	}
+	if c.Routing.MaxHops == 0 {
+		c.Routing.MaxHops = 1
+	}
+	if c.Routing.MaxPaths == 0 {
+		c.Routing.MaxPaths = 20
+	}
+	if c.Routing.IntermediateMints == nil {
+		c.Routing.IntermediateMints = []string{
+			"So11111111111111111111111111111111111111112",  // SOL
+			"EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", // USDC
+			"Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB", // USDT
+		}
+	}
+	if c.Routing.PoolGraphTTL == 0 {
+		c.Routing.PoolGraphTTL = 5 * time.Minute
+	}
+	if c.Routing.SplitParts == 0 {
+		c.Routing.SplitParts = 10
+	}
+	if c.Routing.MaxSplitVenues == 0 {
+		c.Routing.MaxSplitVenues = 3
+	}
+
+	// 批量编码默认值
+	if c.Batch.MaxItems == 0 {
+		c.Batch.MaxItems = 100
+	}
+	if c.Batch.Workers == 0 {
+		c.Batch.Workers = 8
+	}
+	if c.Batch.Timeout == 0 {
+		c.Batch.Timeout = 30 * time.Second
+	}
+
+	// 熔断默认值
+	if c.Breaker.FailureThreshold == 0 {
+		c.Breaker.FailureThreshold = 5
+	}
+	if c.Breaker.Cooldown == 0 {
+		c.Breaker.Cooldown = 30 * time.Second
+	}
+	if c.Breaker.SlowCallThreshold == 0 {
+		c.Breaker.SlowCallThreshold = 2 * time.Second
+	}
+
+	// 健康检查默认值
+	if c.Health.Interval == 0 {
+		c.Health.Interval = time.Minute
+	}
+	if c.Health.Timeout == 0 {
+		c.Health.Timeout = 5 * time.Second
+	}
+	if c.Health.History == 0 {
+		c.Health.History = 20
+	}
+	if c.Health.ProbeMethod == "" {
+		c.Health.ProbeMethod = "HEAD"
+	}
+	if c.Health.MaxStatus == 0 {
+		c.Health.MaxStatus = 500
+	}
+
+	// 上游HTTP默认值
+	if c.Upstream.MaxIdleConnsPerHost == 0 {
+		c.Upstream.MaxIdleConnsPerHost = 16
+	}
+	if c.Upstream.IdleConnTimeout == 0 {
+		c.Upstream.IdleConnTimeout = 90 * time.Second
+	}
+	if c.Upstream.MaxResponseBytes == 0 {
+		c.Upstream.MaxResponseBytes = 16 << 20
+	}
+	if c.Upstream.BackoffBase == 0 {
+		c.Upstream.BackoffBase = 200 * time.Millisecond
+	}
+	if c.Upstream.BackoffMax == 0 {
+		c.Upstream.BackoffMax = 5 * time.Second
+	}
 }
 
 // GetDEXConfig 根据名称获取DEX配置
@@ -208,4 +407,53 @@ func (c *Config) GetEnabledDEXes() []DEXConfig {
 		}
 	}
 	return enabled
-}
\ No newline at end of file
+}
+// Clone 深拷贝配置，修改副本不影响原配置，字段来源信息随副本保留
+func (c *Config) Clone() *Config {
+	clone := *c
+	deepCopyInto(reflect.ValueOf(&clone).Elem())
+	return &clone
+}
+
+// deepCopyInto 把值中的切片、映射和指针替换为副本，nil保持为nil
+func deepCopyInto(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				deepCopyInto(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		for i := 0; i < s.Len(); i++ {
+			deepCopyInto(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(v.Type().Elem()).Elem()
+			value.Set(iter.Value())
+			deepCopyInto(value)
+			m.SetMapIndex(iter.Key(), value)
+		}
+		v.Set(m)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(v.Elem())
+		deepCopyInto(p.Elem())
+		v.Set(p)
+	}
+}
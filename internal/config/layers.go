@@ -0,0 +1,397 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix 覆盖配置的环境变量前缀，如 DEX_SOLANA_RPC_URL、DEX_DEXES_RAYDIUM_ENABLED
+const EnvPrefix = "DEX_"
+
+// 配置值来源，优先级从低到高：默认值、配置文件、环境变量、环境变量指定的文件
+const (
+	SourceDefault    = "default"     // 内置默认值
+	SourceFile       = "file"        // 配置文件
+	SourceEnv        = "env"         // 环境变量
+	SourceSecretFile = "secret_file" // <环境变量>_FILE 指定的文件
+	SourceRuntime    = "runtime"     // 运行时通过接口修改
+)
+
+// ValueSource 配置字段的有效值来源
+type ValueSource struct {
+	Path   string `json:"path"`             // 字段路径，如 solana.rpc_url、dexes[raydium].enabled
+	Source string `json:"source"`           // default, file, env, secret_file, runtime
+	Origin string `json:"origin,omitempty"` // 环境变量名或文件路径
+	EnvVar string `json:"env_var"`          // 覆盖该字段使用的环境变量
+}
+
+// layerInfo 分层加载时记录的字段来源
+type layerInfo struct {
+	sources   map[string]ValueSource
+	loaded    map[string]string   // 加载完成时各字段的值，用于识别运行时修改
+	overrides map[string]override // 被环境变量覆盖的字段，保存配置时恢复原值
+}
+
+// override 被环境变量覆盖的字段
+type override struct {
+	value     string // 覆盖后的值
+	baseValue string // 覆盖前的值
+	inBase    bool   // 覆盖前是否有值（配置文件中是否存在该字段）
+}
+
+// configLeaf 可以通过环境变量覆盖的配置字段
+type configLeaf struct {
+	path  string
+	env   string
+	get   func() string
+	set   func(value string) error
+	reset func()
+}
+
+// LoadConfigWithEnv 分层加载配置：默认值、配置文件、环境变量、<环境变量>_FILE 指定的文件
+func LoadConfigWithEnv(configPath string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	// 检查配置文件是否存在
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("config file not found: %s", configPath)
+	}
+
+	// 读取配置文件
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// 解析YAML配置
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	present := make(map[string]bool)
+	collectPaths("", tree, present)
+
+	// 应用环境变量
+	if err := config.applyLayers(lookupEnv, func(path string) string {
+		if present[path] {
+			return SourceFile
+		}
+		return SourceDefault
+	}); err != nil {
+		return nil, err
+	}
+
+	// 验证配置
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	// 设置默认值
+	config.SetDefaults()
+	config.layers.snapshot(&config)
+
+	return &config, nil
+}
+
+// ApplyEnv 在运行时提交的配置上应用环境变量覆盖，未被覆盖的字段来源记为runtime
+func (c *Config) ApplyEnv(lookupEnv func(string) (string, bool)) error {
+	if err := c.applyLayers(lookupEnv, func(string) string { return SourceRuntime }); err != nil {
+		return err
+	}
+	c.layers.snapshot(c)
+	return nil
+}
+
+// applyLayers 依次应用环境变量和 <环境变量>_FILE 文件，baseSource 返回未覆盖字段的来源
+func (c *Config) applyLayers(lookupEnv func(string) (string, bool), baseSource func(path string) string) error {
+	layers := &layerInfo{
+		sources:   make(map[string]ValueSource),
+		overrides: make(map[string]override),
+	}
+
+	for _, leaf := range configLeaves(c) {
+		source := ValueSource{Path: leaf.path, Source: baseSource(leaf.path), EnvVar: leaf.env}
+		value, ok := lookupEnv(leaf.env)
+		if ok {
+			source.Source, source.Origin = SourceEnv, leaf.env
+		}
+		if file, fileOK := lookupEnv(leaf.env + "_FILE"); fileOK && file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s_FILE: %w", leaf.env, err)
+			}
+			value, ok = strings.TrimRight(string(data), "\r\n"), true
+			source.Source, source.Origin = SourceSecretFile, file
+		}
+
+		if ok {
+			base, inBase := leaf.get(), baseSource(leaf.path) != SourceDefault
+			if err := leaf.set(value); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", source.Origin, err)
+			}
+			layers.overrides[leaf.path] = override{
+				value:     leaf.get(),
+				baseValue: base,
+				inBase:    inBase,
+			}
+		}
+		layers.sources[leaf.path] = source
+	}
+
+	c.layers = layers
+	return nil
+}
+
+// snapshot 记录加载完成时的字段值
+func (l *layerInfo) snapshot(c *Config) {
+	l.loaded = make(map[string]string)
+	for _, leaf := range configLeaves(c) {
+		l.loaded[leaf.path] = leaf.get()
+	}
+}
+
+// Sources 返回每个字段有效值的来源，按路径排序；加载后被修改过的字段来源为runtime
+func (c *Config) Sources() []ValueSource {
+	var sources []ValueSource
+	for _, leaf := range configLeaves(c) {
+		source := ValueSource{Path: leaf.path, Source: SourceRuntime, EnvVar: leaf.env}
+		if c.layers != nil {
+			if loaded, ok := c.layers.sources[leaf.path]; ok && c.layers.loaded[leaf.path] == leaf.get() {
+				source = loaded
+			}
+		}
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Path < sources[j].Path
+	})
+	return sources
+}
+
+// Persistable 返回用于写入文件的配置副本：仍为环境变量覆盖值的字段恢复为覆盖前的值，避免把密钥写入配置文件
+func (c *Config) Persistable() (*Config, error) {
+	clone := c.Clone()
+	if c.layers == nil {
+		return clone, nil
+	}
+
+	for _, leaf := range configLeaves(clone) {
+		o, ok := c.layers.overrides[leaf.path]
+		if !ok || leaf.get() != o.value {
+			continue
+		}
+		if !o.inBase {
+			leaf.reset()
+			continue
+		}
+		if err := leaf.set(o.baseValue); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", leaf.path, err)
+		}
+	}
+	return clone, nil
+}
+
+// configLeaves 列出可以通过环境变量覆盖的字段：标量、字符串列表（逗号分隔）和字符串映射中已有的键
+// 带name字段的列表（如dexes）按名称定位，其他结构体列表按下标定位
+func configLeaves(c *Config) []configLeaf {
+	var leaves []configLeaf
+	walkLeaves(reflect.ValueOf(c).Elem(), "", EnvPrefix[:len(EnvPrefix)-1], &leaves)
+	return leaves
+}
+
+// walkLeaves 按yaml标签递归遍历结构体字段
+func walkLeaves(v reflect.Value, path, env string, leaves *[]configLeaf) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			walkLeaves(v.Field(i), joinLeafPath(path, name), env+"_"+envSegment(name), leaves)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			addLeaf(v, path, env, leaves)
+			return
+		}
+		if v.Type().Elem().Kind() != reflect.Struct {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i)
+			key := strconv.Itoa(i)
+			if name := item.FieldByName("Name"); name.IsValid() && name.Kind() == reflect.String && name.String() != "" {
+				key = name.String()
+			}
+			walkLeaves(item, path+"["+key+"]", env+"_"+envSegment(key), leaves)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String || v.Type().Elem().Kind() != reflect.String || v.IsNil() {
+			return
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			m, k := v, key
+			*leaves = append(*leaves, configLeaf{
+				path: joinLeafPath(path, k.String()),
+				env:  env + "_" + envSegment(k.String()),
+				get:  func() string { return m.MapIndex(k).String() },
+				set: func(value string) error {
+					m.SetMapIndex(k, reflect.ValueOf(value).Convert(m.Type().Elem()))
+					return nil
+				},
+				reset: func() { m.SetMapIndex(k, reflect.Zero(m.Type().Elem())) },
+			})
+		}
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		addLeaf(v, path, env, leaves)
+	}
+}
+
+// addLeaf 添加一个可寻址的标量或字符串列表字段
+func addLeaf(v reflect.Value, path, env string, leaves *[]configLeaf) {
+	*leaves = append(*leaves, configLeaf{
+		path:  path,
+		env:   env,
+		get:   func() string { return formatLeaf(v) },
+		set:   func(value string) error { return parseLeaf(v, value) },
+		reset: func() { v.Set(reflect.Zero(v.Type())) },
+	})
+}
+
+// formatLeaf 把字段值格式化为环境变量的写法
+func formatLeaf(v reflect.Value) string {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	}
+	return ""
+}
+
+// parseLeaf 按字段类型解析环境变量的值
+func parseLeaf(v reflect.Value, value string) error {
+	value = strings.TrimSpace(value)
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	}
+	return nil
+}
+
+// collectPaths 收集配置文件中出现的字段路径，路径写法与configLeaves一致
+func collectPaths(path string, node interface{}, present map[string]bool) {
+	if path != "" {
+		present[path] = true
+	}
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for key, value := range n {
+			collectPaths(joinLeafPath(path, key), value, present)
+		}
+	case []interface{}:
+		for i, item := range n {
+			key := strconv.Itoa(i)
+			if m, ok := item.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok && name != "" {
+					key = name
+				}
+			}
+			collectPaths(path+"["+key+"]", item, present)
+		}
+	}
+}
+
+// joinLeafPath 拼接字段路径
+func joinLeafPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// envSegment 把路径片段转换为环境变量名的一部分：大写，字母数字以外的字符替换为下划线
+func envSegment(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, s)
+}
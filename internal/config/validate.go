@@ -0,0 +1,349 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// FieldError 配置字段的验证错误
+type FieldError struct {
+	Path    string `json:"path"`    // 字段路径，如 dexes[0].program_id
+	Message string `json:"message"` // 错误描述
+}
+
+// Error 实现error接口
+func (e FieldError) Error() string {
+	return e.Path + " " + e.Message
+}
+
+// ValidationErrors 配置验证发现的所有问题
+type ValidationErrors []FieldError
+
+// Error 实现error接口，按顺序列出所有问题
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldErr := range e {
+		messages[i] = fieldErr.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// 网络、确认级别和运行模式的取值
+var (
+	validNetworks    = []string{"mainnet", "devnet", "testnet"}
+	validCommitments = []string{"processed", "confirmed", "finalized"}
+	validModes       = []string{"debug", "release", "test"}
+)
+
+// knownProgram 已知程序及其部署的网络
+type knownProgram struct {
+	name     string
+	networks []string
+}
+
+// knownPrograms 已知的DEX程序ID，程序ID与所选网络不匹配时通常是复制了其他网络的配置
+var knownPrograms = map[string]knownProgram{
+	"675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8": {"Raydium AMM v4", []string{"mainnet"}},
+	"HWy1jotHpo6UqeQxx49dpYYdQB8wj9Qk9MdxwjLvDHB8": {"Raydium AMM v4", []string{"devnet"}},
+	"CPMMoo8L3F4NbTegBCKVNunggL7H1ZpdTHKxQB5qKP1C": {"Raydium CPMM", []string{"mainnet"}},
+	"CPMDWBwJDtYax9qW7AyRuVC19Cc4L4Vcy4n2BHAbHkCW": {"Raydium CPMM", []string{"devnet"}},
+	"CAMMCzo5YL8w4VFF8KVHrK22GGUsp5VTaW7grrKgrWqK": {"Raydium CLMM", []string{"mainnet"}},
+	"devi51mZmdwUJGU9hjN27vEz64Gps7uUefqxg27EAtH":  {"Raydium CLMM", []string{"devnet"}},
+	"whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc":  {"Orca Whirlpool", []string{"mainnet", "devnet"}},
+	"6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P":  {"Pump.fun", []string{"mainnet", "devnet"}},
+}
+
+// validator 收集验证错误
+type validator struct {
+	errs ValidationErrors
+}
+
+// addf 记录一个字段错误
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// oneOf 检查枚举值，空值表示使用默认值
+func (v *validator) oneOf(path, value string, allowed []string) bool {
+	if value == "" || contains(allowed, value) {
+		return true
+	}
+	v.addf(path, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+	return false
+}
+
+// validURL 检查URL格式，要求使用指定的协议并包含主机名
+func (v *validator) validURL(path, value string, schemes ...string) {
+	u, err := url.Parse(value)
+	if err != nil {
+		v.addf(path, "is not a valid URL: %v", err)
+		return
+	}
+	if !contains(schemes, u.Scheme) {
+		v.addf(path, "must use %s scheme, got %q", strings.Join(schemes, " or "), value)
+		return
+	}
+	if u.Host == "" {
+		v.addf(path, "must include a host, got %q", value)
+	}
+}
+
+// publicKey 检查base58编码的32字节公钥
+func (v *validator) publicKey(path, value string) bool {
+	if _, err := solana.PublicKeyFromBase58(value); err != nil {
+		v.addf(path, "is not a valid base58 public key: %q", value)
+		return false
+	}
+	return true
+}
+
+// readableFile 检查文件存在且不是目录
+func (v *validator) readableFile(path, file, requiredBy string) bool {
+	if file == "" {
+		v.addf(path, "is required when %s", requiredBy)
+		return false
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		v.addf(path, "cannot be read: %v", err)
+		return false
+	}
+	if info.IsDir() {
+		v.addf(path, "is a directory: %s", file)
+		return false
+	}
+	return true
+}
+
+// contains 判断字符串是否在列表中
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAll 深度验证配置，返回发现的所有问题，每个问题带有字段路径；没有问题时返回nil
+func (c *Config) ValidateAll() ValidationErrors {
+	v := &validator{}
+	c.validateServer(v)
+	c.validateSolana(v)
+	c.validateDEXes(v)
+	c.validateSecurity(v)
+
+	// 验证路由配置，0表示使用默认值
+	if c.Routing.MaxHops < 0 || c.Routing.MaxHops > 3 {
+		v.addf("routing.max_hops", "must be between 1 and 3, or 0 for the default, got %d", c.Routing.MaxHops)
+	}
+	if c.Routing.SplitParts < 0 || c.Routing.SplitParts == 1 || c.Routing.SplitParts > 100 {
+		v.addf("routing.split_parts", "must be between 2 and 100, or 0 for the default, got %d", c.Routing.SplitParts)
+	}
+	if c.Routing.MaxSplitVenues < 0 {
+		v.addf("routing.max_split_venues", "must be positive")
+	}
+
+	// 验证批量编码配置
+	if c.Batch.MaxItems < 0 {
+		v.addf("batch.max_items", "must be positive")
+	}
+	if c.Batch.Workers < 0 {
+		v.addf("batch.workers", "must be positive")
+	}
+
+	// 验证熔断配置
+	if c.Breaker.FailureThreshold < 0 {
+		v.addf("circuit_breaker.failure_threshold", "must be positive")
+	}
+	if c.Breaker.Cooldown < 0 {
+		v.addf("circuit_breaker.cooldown", "must be positive")
+	}
+
+	// 验证健康检查配置
+	if c.Health.Interval < 0 {
+		v.addf("health_check.interval", "must be positive")
+	}
+	if c.Health.Timeout < 0 {
+		v.addf("health_check.timeout", "must be positive")
+	}
+	if c.Health.History < 0 {
+		v.addf("health_check.history", "must be positive")
+	}
+	v.oneOf("health_check.probe_method", c.Health.ProbeMethod, []string{"HEAD", "GET"})
+	if c.Health.MaxStatus != 0 && (c.Health.MaxStatus < 200 || c.Health.MaxStatus > 600) {
+		v.addf("health_check.max_status", "must be between 200 and 600")
+	}
+
+	// 验证上游HTTP配置
+	if c.Upstream.MaxIdleConnsPerHost < 0 {
+		v.addf("upstream.max_idle_conns_per_host", "must be positive")
+	}
+	if c.Upstream.MaxConnsPerHost < 0 {
+		v.addf("upstream.max_conns_per_host", "must be positive")
+	}
+	if c.Upstream.IdleConnTimeout < 0 {
+		v.addf("upstream.idle_conn_timeout", "must be positive")
+	}
+	if c.Upstream.MaxResponseBytes < 0 {
+		v.addf("upstream.max_response_bytes", "must be positive")
+	}
+	if c.Upstream.BackoffBase < 0 {
+		v.addf("upstream.backoff_base", "must be positive")
+	}
+	if c.Upstream.BackoffMax < 0 {
+		v.addf("upstream.backoff_max", "must be positive")
+	}
+	if c.Upstream.BackoffMax > 0 && c.Upstream.BackoffMax < c.Upstream.BackoffBase {
+		v.addf("upstream.backoff_max", "must not be less than backoff_base")
+	}
+
+	// 验证签名器配置
+	if v.oneOf("signer.backend", c.Signer.Backend, []string{"keystore", "remote"}) && c.Signer.Backend == "remote" {
+		if c.Signer.RemoteURL == "" {
+			v.addf("signer.remote_url", "is required for remote backend")
+		} else {
+			v.validURL("signer.remote_url", c.Signer.RemoteURL, "http", "https")
+		}
+	}
+
+	return v.errs
+}
+
+// validateServer 验证服务器配置
+func (c *Config) validateServer(v *validator) {
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		v.addf("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	v.oneOf("server.mode", c.Server.Mode, validModes)
+	if c.Server.QuoteTimeout < 0 {
+		v.addf("server.quote_timeout", "must not be negative")
+	}
+	if c.Server.EncodeTimeout < 0 {
+		v.addf("server.encode_timeout", "must not be negative")
+	}
+	if c.Server.SubmitTimeout < 0 {
+		v.addf("server.submit_timeout", "must not be negative")
+	}
+}
+
+// validateSolana 验证Solana网络配置
+func (c *Config) validateSolana(v *validator) {
+	if c.Solana.RPCURL == "" && len(c.Solana.RPCEndpoints) == 0 {
+		v.addf("solana.rpc_url", "is required")
+	} else if c.Solana.RPCURL != "" {
+		v.validURL("solana.rpc_url", c.Solana.RPCURL, "http", "https")
+	}
+	if c.Solana.WSURL != "" {
+		v.validURL("solana.ws_url", c.Solana.WSURL, "ws", "wss")
+	}
+	for i, ep := range c.Solana.RPCEndpoints {
+		path := fmt.Sprintf("solana.rpc_endpoints[%d]", i)
+		if ep.URL == "" {
+			v.addf(path+".url", "is required")
+		} else {
+			v.validURL(path+".url", ep.URL, "http", "https")
+		}
+		for _, role := range ep.Roles {
+			if role != "read" && role != "send" {
+				v.addf(path+".roles", "has unknown role: %s", role)
+			}
+		}
+	}
+
+	if c.Solana.Network == "" {
+		v.addf("solana.network", "is required")
+	} else {
+		v.oneOf("solana.network", c.Solana.Network, validNetworks)
+	}
+	v.oneOf("solana.commitment", c.Solana.Commitment, validCommitments)
+	v.oneOf("solana.blockhash_commitment", c.Solana.BlockhashCommitment, validCommitments)
+}
+
+// validateDEXes 验证DEX配置
+func (c *Config) validateDEXes(v *validator) {
+	dexNames := make(map[string]int)
+	for i, dex := range c.DEXes {
+		path := fmt.Sprintf("dexes[%d]", i)
+		if dex.Name == "" {
+			v.addf(path+".name", "is required")
+		} else if first, exists := dexNames[dex.Name]; exists {
+			v.addf(path+".name", "%s is duplicated (also dexes[%d])", dex.Name, first)
+		} else {
+			dexNames[dex.Name] = i
+		}
+
+		if dex.ProgramID == "" {
+			v.addf(path+".program_id", "is required")
+		} else if v.publicKey(path+".program_id", dex.ProgramID) {
+			known, ok := knownPrograms[dex.ProgramID]
+			if ok && contains(validNetworks, c.Solana.Network) && !contains(known.networks, c.Solana.Network) {
+				v.addf(path+".program_id", "is the %s program on %s, not on %s", known.name, strings.Join(known.networks, "/"), c.Solana.Network)
+			}
+		}
+		if dex.RouterAddress != "" {
+			v.publicKey(path+".router_address", dex.RouterAddress)
+		}
+
+		keys := make([]string, 0, len(dex.Endpoints))
+		for key := range dex.Endpoints {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			v.validURL(path+".endpoints."+key, dex.Endpoints[key], "http", "https")
+		}
+
+		if dex.QuoteCacheTTL < 0 {
+			v.addf(path+".quote_cache_ttl", "must be positive")
+		}
+		if dex.QuoteBucketBps < 0 || dex.QuoteBucketBps > 10000 {
+			v.addf(path+".quote_bucket_bps", "must be between 0 and 10000")
+		}
+		if dex.AdapterType() == "anchor" {
+			if dex.IDLPath == "" {
+				v.addf(path+".idl_path", "is required for anchor adapter")
+			}
+			if len(dex.Instructions) == 0 {
+				v.addf(path+".instructions", "are required for anchor adapter")
+			}
+			ops := make([]string, 0, len(dex.Instructions))
+			for op := range dex.Instructions {
+				ops = append(ops, op)
+			}
+			sort.Strings(ops)
+			for _, op := range ops {
+				if dex.Instructions[op].Instruction == "" {
+					v.addf(path+".instructions."+op+".instruction", "is required")
+				}
+			}
+		}
+	}
+}
+
+// validateSecurity 验证安全配置，启用HTTPS时检查证书和私钥文件
+func (c *Config) validateSecurity(v *validator) {
+	if c.Security.RateLimitRPS < 0 {
+		v.addf("security.rate_limit_rps", "must be positive")
+	}
+	if c.Security.MaxRequestSize < 0 {
+		v.addf("security.max_request_size", "must be positive")
+	}
+	if !c.Security.EnableHTTPS {
+		return
+	}
+
+	certOK := v.readableFile("security.cert_file", c.Security.CertFile, "enable_https is true")
+	keyOK := v.readableFile("security.key_file", c.Security.KeyFile, "enable_https is true")
+	if certOK && keyOK {
+		if _, err := tls.LoadX509KeyPair(c.Security.CertFile, c.Security.KeyFile); err != nil {
+			v.addf("security.cert_file", "does not form a valid key pair with key_file: %v", err)
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"solana-dex-service/internal/config"
 	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/store"
 	"solana-dex-service/internal/types"
 
 	"github.com/gin-gonic/gin"
@@ -27,12 +32,13 @@ func NewConfigHandler(configService *services.ConfigService) *ConfigHandler {
 // @Description 获取系统的完整配置信息
 // @Tags 配置管理
 // @Produce json
-// @Success 200 {object} types.SuccessResponse "配置获取成功"
+// @Success 200 {object} types.SuccessResponse "配置获取成功，ETag响应头为当前配置版本"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config [get]
 func (ch *ConfigHandler) GetConfig(c *gin.Context) {
-	config := ch.configService.GetConfig()
+	config, version := ch.configService.Snapshot()
 
+	c.Header("ETag", strconv.Quote(version))
 	c.JSON(http.StatusOK, types.SuccessResponse{
 		Success: true,
 		Data:    config,
@@ -47,8 +53,10 @@ func (ch *ConfigHandler) GetConfig(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param config body config.Config true "配置信息"
-// @Success 200 {object} types.SuccessResponse "配置更新成功"
-// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
+// @Success 200 {object} types.SuccessResponse "配置更新成功，ETag响应头为新的配置版本"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误或配置验证失败"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config [put]
 func (ch *ConfigHandler) UpdateConfig(c *gin.Context) {
@@ -63,8 +71,12 @@ func (ch *ConfigHandler) UpdateConfig(c *gin.Context) {
 		return
 	}
 
-	// 更新配置
-	if err := ch.configService.UpdateConfig(&newConfig); err != nil {
+	// 更新配置，If-Match 与当前版本不一致时说明配置已被其他请求修改
+	version, err := ch.configService.UpdateConfig(&newConfig, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to update configuration",
 			Details: err.Error(),
@@ -72,6 +84,7 @@ func (ch *ConfigHandler) UpdateConfig(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", strconv.Quote(version))
 	c.JSON(http.StatusOK, types.SuccessResponse{
 		Success: true,
 		Message: "Configuration updated successfully",
@@ -102,8 +115,10 @@ func (ch *ConfigHandler) GetDEXConfig(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param dexConfig body []config.DEXConfig true "DEX配置列表"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "DEX配置更新成功"
 // @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/dex [put]
 func (ch *ConfigHandler) UpdateDEXConfig(c *gin.Context) {
@@ -119,7 +134,11 @@ func (ch *ConfigHandler) UpdateDEXConfig(c *gin.Context) {
 	}
 
 	// 更新DEX配置
-	if err := ch.configService.UpdateDEXConfig(dexConfigs); err != nil {
+	err := ch.configService.UpdateDEXConfig(dexConfigs, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to update DEX configuration",
 			Details: err.Error(),
@@ -140,8 +159,10 @@ func (ch *ConfigHandler) UpdateDEXConfig(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param dexConfig body config.DEXConfig true "DEX配置"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 201 {object} types.SuccessResponse "DEX配置添加成功"
 // @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/dex [post]
 func (ch *ConfigHandler) AddDEXConfig(c *gin.Context) {
@@ -157,7 +178,11 @@ func (ch *ConfigHandler) AddDEXConfig(c *gin.Context) {
 	}
 
 	// 添加DEX配置
-	if err := ch.configService.AddDEXConfig(dexConfig); err != nil {
+	err := ch.configService.AddDEXConfig(dexConfig, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "Failed to add DEX configuration",
 			Details: err.Error(),
@@ -177,8 +202,10 @@ func (ch *ConfigHandler) AddDEXConfig(c *gin.Context) {
 // @Tags 配置管理
 // @Produce json
 // @Param name path string true "DEX名称"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "DEX配置移除成功"
 // @Failure 400 {object} types.ErrorResponse "DEX不存在"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/dex/{name} [delete]
 func (ch *ConfigHandler) RemoveDEXConfig(c *gin.Context) {
@@ -192,7 +219,11 @@ func (ch *ConfigHandler) RemoveDEXConfig(c *gin.Context) {
 	}
 
 	// 移除DEX配置
-	if err := ch.configService.RemoveDEXConfig(dexName); err != nil {
+	err := ch.configService.RemoveDEXConfig(dexName, changeInfo(c))
+	if versionMismatch(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "Failed to remove DEX configuration",
 			Details: err.Error(),
@@ -212,8 +243,10 @@ func (ch *ConfigHandler) RemoveDEXConfig(c *gin.Context) {
 // @Tags 配置管理
 // @Produce json
 // @Param name path string true "DEX名称"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "DEX启用成功"
 // @Failure 400 {object} types.ErrorResponse "DEX不存在"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/dex/{name}/enable [post]
 func (ch *ConfigHandler) EnableDEX(c *gin.Context) {
@@ -227,7 +260,11 @@ func (ch *ConfigHandler) EnableDEX(c *gin.Context) {
 	}
 
 	// 启用DEX
-	if err := ch.configService.EnableDEX(dexName); err != nil {
+	err := ch.configService.EnableDEX(dexName, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "Failed to enable DEX",
 			Details: err.Error(),
@@ -247,8 +284,10 @@ func (ch *ConfigHandler) EnableDEX(c *gin.Context) {
 // @Tags 配置管理
 // @Produce json
 // @Param name path string true "DEX名称"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "DEX禁用成功"
 // @Failure 400 {object} types.ErrorResponse "DEX不存在"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/dex/{name}/disable [post]
 func (ch *ConfigHandler) DisableDEX(c *gin.Context) {
@@ -262,7 +301,11 @@ func (ch *ConfigHandler) DisableDEX(c *gin.Context) {
 	}
 
 	// 禁用DEX
-	if err := ch.configService.DisableDEX(dexName); err != nil {
+	err := ch.configService.DisableDEX(dexName, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "Failed to disable DEX",
 			Details: err.Error(),
@@ -300,8 +343,10 @@ func (ch *ConfigHandler) GetServerConfig(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param serverConfig body config.ServerConfig true "服务器配置"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "服务器配置更新成功"
 // @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/server [put]
 func (ch *ConfigHandler) UpdateServerConfig(c *gin.Context) {
@@ -317,7 +362,11 @@ func (ch *ConfigHandler) UpdateServerConfig(c *gin.Context) {
 	}
 
 	// 更新服务器配置
-	if err := ch.configService.UpdateServerConfig(&serverConfig); err != nil {
+	err := ch.configService.UpdateServerConfig(&serverConfig, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to update server configuration",
 			Details: err.Error(),
@@ -355,8 +404,10 @@ func (ch *ConfigHandler) GetSolanaConfig(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param solanaConfig body config.SolanaConfig true "Solana配置"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "Solana配置更新成功"
 // @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/solana [put]
 func (ch *ConfigHandler) UpdateSolanaConfig(c *gin.Context) {
@@ -372,7 +423,11 @@ func (ch *ConfigHandler) UpdateSolanaConfig(c *gin.Context) {
 	}
 
 	// 更新Solana配置
-	if err := ch.configService.UpdateSolanaConfig(&solanaConfig); err != nil {
+	err := ch.configService.UpdateSolanaConfig(&solanaConfig, changeInfo(c))
+	if versionMismatch(c, err) || invalidConfig(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to update Solana configuration",
 			Details: err.Error(),
@@ -391,11 +446,17 @@ func (ch *ConfigHandler) UpdateSolanaConfig(c *gin.Context) {
 // @Description 从配置文件重新加载系统配置
 // @Tags 配置管理
 // @Produce json
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
 // @Success 200 {object} types.SuccessResponse "配置重新加载成功"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/config/reload [post]
 func (ch *ConfigHandler) ReloadConfig(c *gin.Context) {
-	if err := ch.configService.ReloadConfig(); err != nil {
+	err := ch.configService.ReloadConfig(changeInfo(c))
+	if versionMismatch(c, err) {
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to reload configuration",
 			Details: err.Error(),
@@ -434,20 +495,46 @@ func (ch *ConfigHandler) BackupConfig(c *gin.Context) {
 
 // ValidateConfig 验证配置
 // @Summary 验证配置
-// @Description 验证当前配置的有效性
+// @Description 深度验证配置并列出所有问题及其字段路径；请求体为完整配置时验证该配置（不会应用），否则验证当前配置
 // @Tags 配置管理
+// @Accept json
 // @Produce json
-// @Success 200 {object} types.SuccessResponse "配置验证成功"
-// @Failure 400 {object} types.ErrorResponse "配置验证失败"
+// @Param config body config.Config false "待验证的配置"
+// @Success 200 {object} types.SuccessResponse "配置验证通过"
+// @Failure 400 {object} types.ErrorResponse "配置验证失败，validation_errors列出所有问题"
 // @Router /api/v1/config/validate [post]
 func (ch *ConfigHandler) ValidateConfig(c *gin.Context) {
-	if err := ch.configService.ValidateConfig(); err != nil {
+	// 没有请求体时验证当前配置
+	var candidate *config.Config
+	if c.Request.ContentLength != 0 {
+		candidate = &config.Config{}
+		if err := c.ShouldBindJSON(candidate); errors.Is(err, io.EOF) {
+			candidate = nil
+		} else if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid configuration parameters",
+				Details: err.Error(),
+			})
+			return
+		}
+	}
+
+	validationErrors, err := ch.configService.CheckConfig(candidate)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "Configuration validation failed",
 			Details: err.Error(),
 		})
 		return
 	}
+	if len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:            "Configuration validation failed",
+			Details:          validationErrors.Error(),
+			ValidationErrors: validationErrors,
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, types.SuccessResponse{
 		Success: true,
@@ -470,4 +557,189 @@ func (ch *ConfigHandler) GetConfigSummary(c *gin.Context) {
 		Data:    summary,
 		Message: "Configuration summary retrieved successfully",
 	})
+}
+
+// GetConfigSources 获取配置来源
+// @Summary 获取配置来源
+// @Description 列出每个配置字段的有效值来源（default、file、env、secret_file、runtime）及覆盖它的环境变量名，不返回字段的值
+// @Tags 配置管理
+// @Produce json
+// @Success 200 {object} types.SuccessResponse "配置来源获取成功"
+// @Router /api/v1/config/sources [get]
+func (ch *ConfigHandler) GetConfigSources(c *gin.Context) {
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    ch.configService.GetConfigSources(),
+		Message: "Configuration sources retrieved successfully",
+	})
+}
+
+// GetConfigHistory 获取配置版本历史
+// @Summary 获取配置版本历史
+// @Description 按版本号倒序列出已应用的配置版本，包括作者、时间和原因
+// @Tags 配置管理
+// @Produce json
+// @Success 200 {object} types.SuccessResponse "配置版本获取成功"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/config/history [get]
+func (ch *ConfigHandler) GetConfigHistory(c *gin.Context) {
+	history, err := ch.configService.GetHistory()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to get configuration history",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    history,
+		Message: "Configuration history retrieved successfully",
+	})
+}
+
+// GetConfigDiff 获取配置版本差异
+// @Summary 获取配置版本差异
+// @Description 按字段比较配置版本与上一个版本（或against指定的版本）
+// @Tags 配置管理
+// @Produce json
+// @Param v path int true "配置版本"
+// @Param against query int false "比较的基准版本，默认为上一个版本"
+// @Success 200 {object} types.SuccessResponse "配置差异获取成功"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 404 {object} types.ErrorResponse "配置版本不存在"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/config/history/{v}/diff [get]
+func (ch *ConfigHandler) GetConfigDiff(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("v"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid version parameter",
+			Details: "version must be a positive integer",
+		})
+		return
+	}
+	against := 0
+	if againstStr := c.Query("against"); againstStr != "" {
+		against, err = strconv.Atoi(againstStr)
+		if err != nil || against < 1 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid against parameter",
+				Details: "against must be a positive integer",
+			})
+			return
+		}
+	}
+
+	diff, err := ch.configService.DiffVersion(version, against)
+	if err != nil {
+		c.JSON(configHistoryStatus(err), types.ErrorResponse{
+			Error:   "Failed to get configuration diff",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    diff,
+		Message: "Configuration diff retrieved successfully",
+	})
+}
+
+// RollbackConfig 回滚配置
+// @Summary 回滚配置
+// @Description 回滚到指定版本的配置，立即生效并记录为新版本
+// @Tags 配置管理
+// @Produce json
+// @Param v path int true "配置版本"
+// @Param X-Config-Author header string false "变更作者"
+// @Param X-Config-Reason header string false "变更原因"
+// @Param If-Match header string false "获取配置时返回的ETag，配置已被修改时拒绝更新"
+// @Success 200 {object} types.SuccessResponse "配置回滚成功"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 404 {object} types.ErrorResponse "配置版本不存在"
+// @Failure 412 {object} types.ErrorResponse "配置已被其他请求修改"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/config/rollback/{v} [post]
+func (ch *ConfigHandler) RollbackConfig(c *gin.Context) {
+	version, err := strconv.Atoi(c.Param("v"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid version parameter",
+			Details: "version must be a positive integer",
+		})
+		return
+	}
+
+	newVersion, err := ch.configService.Rollback(version, changeInfo(c))
+	if versionMismatch(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(configHistoryStatus(err), types.ErrorResponse{
+			Error:   "Failed to rollback configuration",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    gin.H{"version": newVersion, "rolled_back_to": version},
+		Message: "Configuration rolled back successfully",
+	})
+}
+
+// changeInfo 从请求头读取配置变更的作者、原因和期望的配置版本
+func changeInfo(c *gin.Context) services.ChangeInfo {
+	author := c.GetHeader("X-Config-Author")
+	if author == "" {
+		author = "api"
+	}
+	return services.ChangeInfo{Author: author, Reason: c.GetHeader("X-Config-Reason"), IfMatch: ifMatchVersion(c)}
+}
+
+// ifMatchVersion 从 If-Match 请求头读取期望的配置版本，未提供或为*时不检查
+func ifMatchVersion(c *gin.Context) string {
+	version := strings.TrimPrefix(strings.TrimSpace(c.GetHeader("If-Match")), "W/")
+	if version == "*" {
+		return ""
+	}
+	return strings.Trim(version, `"`)
+}
+
+// versionMismatch If-Match 与当前配置版本不一致时返回412，说明配置已被其他请求修改
+func versionMismatch(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrConfigVersionMismatch) {
+		return false
+	}
+	c.JSON(http.StatusPreconditionFailed, types.ErrorResponse{
+		Error:   "Configuration has been modified",
+		Details: err.Error(),
+	})
+	return true
+}
+
+// invalidConfig 修改后的配置未通过验证时返回400并列出所有问题
+func invalidConfig(c *gin.Context, err error) bool {
+	var validationErrors config.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, types.ErrorResponse{
+		Error:            "Invalid configuration",
+		Details:          err.Error(),
+		ValidationErrors: validationErrors,
+	})
+	return true
+}
+
+// configHistoryStatus 配置版本不存在时返回404
+func configHistoryStatus(err error) int {
+	if errors.Is(err, store.ErrConfigVersionNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
 }
\ No newline at end of file
@@ -23,7 +23,7 @@ func NewDEXHandler(dexService *services.DEXService) *DEXHandler {
 
 // ListDEXes 获取所有DEX列表
 // @Summary 获取所有DEX列表
-// @Description 获取系统中配置的所有DEX信息
+// @Description 获取系统中配置的所有DEX信息，状态按熔断器返回online、degraded或offline
 // @Tags DEX管理
 // @Produce json
 // @Success 200 {object} types.SuccessResponse "DEX列表获取成功"
@@ -121,7 +121,7 @@ func (dh *DEXHandler) GetPools(c *gin.Context) {
 	}
 
 	// 获取流动性池信息
-	pools, err := dh.dexService.GetPools(dexName)
+	pools, err := dh.dexService.GetPools(c.Request.Context(), dexName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to get pools",
@@ -185,7 +185,7 @@ func (dh *DEXHandler) GetEnabledDEXes(c *gin.Context) {
 
 // CheckDEXStatus 检查DEX状态
 // @Summary 检查DEX状态
-// @Description 检查指定DEX的当前运行状态
+// @Description 检查指定DEX的当前运行状态：online、degraded（有失败、延迟过高或端点不可达）、offline（已熔断或程序账户不可用）或disabled，并返回熔断器状态和最近的主动健康检查结果
 // @Tags DEX管理
 // @Produce json
 // @Param name path string true "DEX名称"
@@ -216,6 +216,12 @@ func (dh *DEXHandler) CheckDEXStatus(c *gin.Context) {
 		"dex":    dexName,
 		"status": status,
 	}
+	if breaker := dh.dexService.GetBreakerStatus(dexName); breaker != nil {
+		response["breaker"] = breaker
+	}
+	if health := dh.dexService.GetDEXHealth(dexName); health != nil {
+		response["health"] = health
+	}
 
 	c.JSON(http.StatusOK, types.SuccessResponse{
 		Success: true,
@@ -234,6 +240,7 @@ func (dh *DEXHandler) CheckDEXStatus(c *gin.Context) {
 // @Param outputMint query string true "输出代币地址"
 // @Param amountIn query string true "输入金额"
 // @Success 200 {object} types.SuccessResponse "报价获取成功"
+// @Header 200 {string} X-Quote-Cache "报价缓存结果：hit、miss或coalesced"
 // @Failure 400 {object} types.ErrorResponse "请求参数错误"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/dex/{name}/quote [get]
@@ -263,7 +270,8 @@ func (dh *DEXHandler) GetQuote(c *gin.Context) {
 	}
 
 	// 获取报价
-	quote, err := dh.dexService.GetQuote(dexName, inputMint, outputMint, amountIn)
+	quote, cacheStatus, err := dh.dexService.GetQuoteWithCacheStatus(c.Request.Context(), dexName, inputMint, outputMint, amountIn)
+	setQuoteCacheHeader(c, cacheStatus)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to get quote",
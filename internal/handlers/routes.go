@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes 设置所有路由，服务和测试共用同一份路由表
+func SetupRoutes(router *gin.Engine, transactionHandler *TransactionHandler, dexHandler *DEXHandler, configHandler *ConfigHandler) {
+	// 健康检查
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok", "timestamp": time.Now().Unix()})
+	})
+
+	// API v1 路由组
+	v1 := router.Group("/api/v1")
+	{
+		// 交易编码相关路由
+		encode := v1.Group("/encode")
+		{
+			encode.POST("/swap", transactionHandler.EncodeSwap)
+			encode.POST("/liquidity", transactionHandler.EncodeLiquidity)
+			encode.POST("/batch", transactionHandler.EncodeBatch)
+			encode.POST("/multi-swap", transactionHandler.EncodeMultiSwap)
+		}
+
+		// 交易测试相关路由
+		test := v1.Group("/test")
+		{
+			test.POST("/transaction", transactionHandler.TestTransaction)
+			test.POST("/simulate", transactionHandler.SimulateTransaction)
+		}
+
+		// 交易状态相关路由
+		tx := v1.Group("/tx")
+		{
+			tx.GET("", transactionHandler.ListTransactions)
+			tx.GET("/:signature", transactionHandler.GetTransactionStatus)
+			tx.POST("/refresh-blockhash", transactionHandler.RefreshBlockhash)
+			tx.POST("/assemble", transactionHandler.AssembleTransaction)
+			tx.POST("/decode", transactionHandler.DecodeTransaction)
+		}
+
+		// 跨DEX路由相关路由
+		route := v1.Group("/route")
+		{
+			route.GET("/quote", transactionHandler.GetRouteQuote)
+			route.GET("/split", transactionHandler.GetSplitQuote)
+		}
+
+		// RPC端点状态
+		v1.GET("/rpc/status", transactionHandler.GetRPCStatus)
+
+		// 签名器相关路由
+		v1.GET("/signer/keys", transactionHandler.ListSignerKeys)
+
+		// DEX相关路由
+		dex := v1.Group("/dex")
+		{
+			dex.GET("/list", dexHandler.ListDEXes)
+			dex.GET("/:name", dexHandler.GetDEX)
+			dex.GET("/:name/pools", dexHandler.GetPools)
+			dex.GET("/:name/status", dexHandler.CheckDEXStatus)
+		}
+
+		// 配置管理相关路由
+		config := v1.Group("/config")
+		{
+			config.GET("/", configHandler.GetConfig)
+			config.PUT("/", configHandler.UpdateConfig)
+			config.GET("/dex", configHandler.GetDEXConfig)
+			config.PUT("/dex", configHandler.UpdateDEXConfig)
+			config.POST("/dex", configHandler.AddDEXConfig)
+			config.DELETE("/dex/:name", configHandler.RemoveDEXConfig)
+			config.POST("/dex/:name/enable", configHandler.EnableDEX)
+			config.POST("/dex/:name/disable", configHandler.DisableDEX)
+			config.POST("/reload", configHandler.ReloadConfig)
+			config.POST("/validate", configHandler.ValidateConfig)
+			config.GET("/sources", configHandler.GetConfigSources)
+			config.GET("/history", configHandler.GetConfigHistory)
+			config.GET("/history/:v/diff", configHandler.GetConfigDiff)
+			config.POST("/rollback/:v", configHandler.RollbackConfig)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"solana-dex-service/internal/adapters"
 	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/store"
 	"solana-dex-service/internal/types"
 
 	"github.com/gin-gonic/gin"
@@ -46,7 +50,7 @@ func (th *TransactionHandler) EncodeSwap(c *gin.Context) {
 	}
 
 	// 调用服务层编码交易
-	resp, err := th.transactionService.EncodeSwapTransaction(&req)
+	resp, err := th.transactionService.EncodeSwapTransaction(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to encode swap transaction",
@@ -60,8 +64,9 @@ func (th *TransactionHandler) EncodeSwap(c *gin.Context) {
 		c.JSON(http.StatusOK, resp)
 	} else {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
-			Error:   resp.Error,
-			Details: "Transaction encoding failed",
+			Error:      resp.Error,
+			Details:    "Transaction encoding failed",
+			Violations: limitViolations(resp.Preflight),
 		})
 	}
 }
@@ -90,7 +95,7 @@ func (th *TransactionHandler) EncodeLiquidity(c *gin.Context) {
 	}
 
 	// 调用服务层编码交易
-	resp, err := th.transactionService.EncodeLiquidityTransaction(&req)
+	resp, err := th.transactionService.EncodeLiquidityTransaction(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to encode liquidity transaction",
@@ -99,13 +104,103 @@ func (th *TransactionHandler) EncodeLiquidity(c *gin.Context) {
 		return
 	}
 
+	// 返回响应
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:      resp.Error,
+			Details:    "Transaction encoding failed",
+			Violations: limitViolations(resp.Preflight),
+		})
+	}
+}
+
+// EncodeMultiSwap 编码原子多交换交易
+// @Summary 编码原子多交换交易
+// @Description 将多个交换（可跨DEX和交易对）编码到同一笔交易中，输出代币账户去重创建，只设置一次计算预算，超过交易大小或账户数量限制时返回错误
+// @Tags 交易编码
+// @Accept json
+// @Produce json
+// @Param request body types.MultiSwapRequest true "多交换请求参数"
+// @Success 200 {object} types.MultiSwapResponse "交易编码成功"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/encode/multi-swap [post]
+func (th *TransactionHandler) EncodeMultiSwap(c *gin.Context) {
+	var req types.MultiSwapRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 调用服务层编码交易
+	resp, err := th.transactionService.EncodeMultiSwapTransaction(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to encode multi-swap transaction",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 返回响应
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:      resp.Error,
+			Details:    "Transaction encoding failed",
+			Violations: limitViolations(resp.Preflight),
+		})
+	}
+}
+
+// EncodeBatch 批量编码交易
+// @Summary 批量编码交易
+// @Description 并发编码多个交换和流动性请求，所有交易共用同一个区块哈希，按请求顺序返回每个条目的结果和错误
+// @Tags 交易编码
+// @Accept json
+// @Produce json
+// @Param request body types.BatchEncodeRequest true "批量编码请求参数"
+// @Success 200 {object} types.BatchEncodeResponse "批量编码完成（单个条目可能失败）"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/encode/batch [post]
+func (th *TransactionHandler) EncodeBatch(c *gin.Context) {
+	var req types.BatchEncodeRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 调用服务层批量编码
+	resp, err := th.transactionService.EncodeBatch(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to encode batch",
+			Details: err.Error(),
+		})
+		return
+	}
+
 	// 返回响应
 	if resp.Success {
 		c.JSON(http.StatusOK, resp)
 	} else {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   resp.Error,
-			Details: "Transaction encoding failed",
+			Details: "Batch encoding failed",
 		})
 	}
 }
@@ -134,7 +229,7 @@ func (th *TransactionHandler) TestTransaction(c *gin.Context) {
 	}
 
 	// 调用服务层测试交易
-	resp, err := th.transactionService.TestTransaction(&req)
+	resp, err := th.transactionService.TestTransaction(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to test transaction",
@@ -181,7 +276,7 @@ func (th *TransactionHandler) SimulateTransaction(c *gin.Context) {
 	req.SimulateOnly = true
 
 	// 调用服务层模拟交易
-	resp, err := th.transactionService.SimulateTransaction(&req)
+	resp, err := th.transactionService.SimulateTransaction(c.Request.Context(), &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to simulate transaction",
@@ -205,6 +300,7 @@ func (th *TransactionHandler) SimulateTransaction(c *gin.Context) {
 // @Param outputMint query string true "输出代币地址"
 // @Param amountIn query string true "输入金额"
 // @Success 200 {object} types.QuoteResponse "报价获取成功"
+// @Header 200 {string} X-Quote-Cache "报价缓存结果：hit、miss或coalesced"
 // @Failure 400 {object} types.ErrorResponse "请求参数错误"
 // @Failure 500 {object} types.ErrorResponse "服务器内部错误"
 // @Router /api/v1/quote [get]
@@ -235,8 +331,7 @@ func (th *TransactionHandler) GetQuote(c *gin.Context) {
 	}
 
 	// 获取DEX适配器
-	adapter, err := th.transactionService.GetDEXAdapter(dexName)
-	if err != nil {
+	if _, err := th.transactionService.GetDEXAdapter(dexName); err != nil {
 		c.JSON(http.StatusBadRequest, types.ErrorResponse{
 			Error:   "DEX not found",
 			Details: err.Error(),
@@ -245,7 +340,8 @@ func (th *TransactionHandler) GetQuote(c *gin.Context) {
 	}
 
 	// 获取报价
-	quote, err := adapter.GetQuote(inputMint, outputMint, amountIn)
+	quote, cacheStatus, err := th.transactionService.GetQuoteWithCacheStatus(c.Request.Context(), dexName, inputMint, outputMint, amountIn)
+	setQuoteCacheHeader(c, cacheStatus)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
 			Error:   "Failed to get quote",
@@ -257,6 +353,156 @@ func (th *TransactionHandler) GetQuote(c *gin.Context) {
 	c.JSON(http.StatusOK, quote)
 }
 
+// GetRouteQuote 获取跨DEX最优报价
+// @Summary 获取跨DEX最优报价
+// @Description 并发向所有已启用的DEX询价，按扣除手续费后的净输出排序，返回最优报价及其他报价
+// @Tags 交易查询
+// @Accept json
+// @Produce json
+// @Param inputMint query string true "输入代币地址"
+// @Param outputMint query string true "输出代币地址"
+// @Param amountIn query string true "输入金额"
+// @Param maxHops query int false "最大跳数（1-3），默认使用配置值"
+// @Success 200 {object} types.RouteQuoteResponse "报价获取成功"
+// @Header 200 {int} X-Quote-Cache-Hits "命中缓存或合并的询价次数"
+// @Header 200 {int} X-Quote-Cache-Misses "请求上游DEX的询价次数"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/route/quote [get]
+func (th *TransactionHandler) GetRouteQuote(c *gin.Context) {
+	// 获取查询参数
+	inputMint := c.Query("inputMint")
+	outputMint := c.Query("outputMint")
+	amountInStr := c.Query("amountIn")
+
+	// 验证必需参数
+	if inputMint == "" || outputMint == "" || amountInStr == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Missing required parameters",
+			Details: "inputMint, outputMint, and amountIn are required",
+		})
+		return
+	}
+
+	// 解析金额
+	amountIn, err := strconv.ParseUint(amountInStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid amountIn parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 解析最大跳数（可选）
+	maxHops := 0
+	if maxHopsStr := c.Query("maxHops"); maxHopsStr != "" {
+		maxHops, err = strconv.Atoi(maxHopsStr)
+		if err != nil || maxHops < 1 || maxHops > 3 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid maxHops parameter",
+				Details: "maxHops must be between 1 and 3",
+			})
+			return
+		}
+	}
+
+	resp, err := th.transactionService.RouteQuote(c.Request.Context(), inputMint, outputMint, amountIn, maxHops)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to get route quote",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	setRouteCacheHeaders(c, resp)
+
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   resp.Error,
+			Details: "No route found",
+		})
+	}
+}
+
+// GetSplitQuote 获取拆单报价
+// @Summary 获取拆单报价
+// @Description 将输入金额等分为若干份，逐份分配给边际价格最优的DEX，与不拆单的直接报价一起按净输出排序
+// @Tags 交易查询
+// @Accept json
+// @Produce json
+// @Param inputMint query string true "输入代币地址"
+// @Param outputMint query string true "输出代币地址"
+// @Param amountIn query string true "输入金额"
+// @Param parts query int false "等分份数（2-100），默认使用配置值"
+// @Success 200 {object} types.RouteQuoteResponse "报价获取成功"
+// @Header 200 {int} X-Quote-Cache-Hits "命中缓存或合并的询价次数"
+// @Header 200 {int} X-Quote-Cache-Misses "请求上游DEX的询价次数"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/route/split [get]
+func (th *TransactionHandler) GetSplitQuote(c *gin.Context) {
+	// 获取查询参数
+	inputMint := c.Query("inputMint")
+	outputMint := c.Query("outputMint")
+	amountInStr := c.Query("amountIn")
+
+	// 验证必需参数
+	if inputMint == "" || outputMint == "" || amountInStr == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Missing required parameters",
+			Details: "inputMint, outputMint, and amountIn are required",
+		})
+		return
+	}
+
+	// 解析金额
+	amountIn, err := strconv.ParseUint(amountInStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid amountIn parameter",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 解析等分份数（可选）
+	parts := 0
+	if partsStr := c.Query("parts"); partsStr != "" {
+		parts, err = strconv.Atoi(partsStr)
+		if err != nil || parts < 2 || parts > 100 {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid parts parameter",
+				Details: "parts must be between 2 and 100",
+			})
+			return
+		}
+	}
+
+	resp, err := th.transactionService.SplitQuote(c.Request.Context(), inputMint, outputMint, amountIn, parts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to get split quote",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	setRouteCacheHeaders(c, resp)
+
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   resp.Error,
+			Details: "No route found",
+		})
+	}
+}
+
 // GetSupportedDEXes 获取支持的DEX列表
 // @Summary 获取支持的DEX列表
 // @Description 获取当前支持的所有DEX名称列表
@@ -305,4 +551,322 @@ func (th *TransactionHandler) EstimateFee(c *gin.Context) {
 		"estimated_fee": 5000, // 默认5000 lamports
 		"message":       "Fee estimation completed",
 	})
-}
\ No newline at end of file
+}
+
+// GetTransactionStatus 获取交易状态
+// @Summary 获取交易状态
+// @Description 根据签名查询已发送交易的状态、slot、费用和计算单元消耗
+// @Tags 交易查询
+// @Produce json
+// @Param signature path string true "交易签名"
+// @Success 200 {object} types.SuccessResponse "交易状态获取成功"
+// @Failure 404 {object} types.ErrorResponse "交易不存在"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/tx/{signature} [get]
+func (th *TransactionHandler) GetTransactionStatus(c *gin.Context) {
+	signature := c.Param("signature")
+	if signature == "" {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Signature is required",
+			Details: "Please provide a valid transaction signature",
+		})
+		return
+	}
+
+	result, err := th.transactionService.GetTransactionStatus(c.Request.Context(), signature)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, store.ErrTransactionNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, types.ErrorResponse{
+			Error:   "Failed to get transaction status",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    result,
+		Message: "Transaction status retrieved successfully",
+	})
+}
+
+// ListTransactions 获取交易记录列表
+// @Summary 获取交易记录列表
+// @Description 按钱包、DEX和状态筛选已发送的交易记录
+// @Tags 交易查询
+// @Produce json
+// @Param wallet query string false "付款人钱包地址"
+// @Param dex query string false "DEX名称"
+// @Param status query string false "交易状态"
+// @Param limit query int false "返回数量限制" default(50)
+// @Param offset query int false "偏移量" default(0)
+// @Success 200 {object} types.SuccessResponse "交易记录获取成功"
+// @Failure 400 {object} types.ErrorResponse "limit或offset参数无效"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/tx [get]
+func (th *TransactionHandler) ListTransactions(c *gin.Context) {
+	filter := store.TransactionFilter{
+		Wallet: c.Query("wallet"),
+		DEX:    c.Query("dex"),
+		Status: c.Query("status"),
+		Limit:  50,
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := parseIntParam(limitStr, "limit")
+		if err == nil {
+			err = validateRange(limit, 1, 1000, "limit")
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid limit parameter",
+				Details: err.Error(),
+			})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err := parseIntParam(offsetStr, "offset")
+		if err == nil && offset < 0 {
+			err = errors.New("offset must not be negative")
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, types.ErrorResponse{
+				Error:   "Invalid offset parameter",
+				Details: err.Error(),
+			})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	results, total, err := th.transactionService.ListTransactions(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to list transactions",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	response := map[string]interface{}{
+		"transactions": results,
+		"total":        total,
+		"limit":        filter.Limit,
+		"offset":       filter.Offset,
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    response,
+		Message: "Transactions retrieved successfully",
+	})
+}
+
+// RefreshBlockhash 刷新交易区块哈希
+// @Summary 刷新交易区块哈希
+// @Description 使用最新区块哈希重新生成未签名交易，返回新的过期区块高度
+// @Tags 交易编码
+// @Accept json
+// @Produce json
+// @Param request body types.RefreshBlockhashRequest true "刷新区块哈希请求参数"
+// @Success 200 {object} types.RefreshBlockhashResponse "区块哈希刷新成功"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/tx/refresh-blockhash [post]
+func (th *TransactionHandler) RefreshBlockhash(c *gin.Context) {
+	var req types.RefreshBlockhashRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	resp, err := th.transactionService.RefreshTransactionBlockhash(c.Request.Context(), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to refresh blockhash",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   resp.Error,
+			Details: "Blockhash refresh failed",
+		})
+	}
+}
+
+// GetRPCStatus 获取RPC端点健康状态
+// @Summary 获取RPC端点健康状态
+// @Description 返回所有RPC端点的延迟、错误率、slot落后量和评分，按评分从高到低排列
+// @Tags 系统状态
+// @Produce json
+// @Success 200 {object} types.SuccessResponse "RPC端点状态获取成功"
+// @Router /api/v1/rpc/status [get]
+func (th *TransactionHandler) GetRPCStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    th.transactionService.GetRPCStatus(),
+		Message: "RPC status retrieved successfully",
+	})
+}
+
+// ListSignerKeys 获取签名密钥列表
+// @Summary 获取签名密钥列表
+// @Description 返回签名器中可用的密钥ID和公钥，不包含任何密钥材料
+// @Tags 交易测试
+// @Produce json
+// @Success 200 {object} types.SuccessResponse "密钥列表获取成功"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/signer/keys [get]
+func (th *TransactionHandler) ListSignerKeys(c *gin.Context) {
+	keys, err := th.transactionService.ListSignerKeys(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to list signer keys",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.SuccessResponse{
+		Success: true,
+		Data:    keys,
+		Message: "Signer keys retrieved successfully",
+	})
+}
+
+// AssembleTransaction 组装多方签名交易
+// @Summary 组装多方签名交易
+// @Description 接收交易消息和各签名方的分离签名，校验后返回完整签名的交易
+// @Tags 交易编码
+// @Accept json
+// @Produce json
+// @Param request body types.AssembleTransactionRequest true "组装交易请求参数"
+// @Success 200 {object} types.AssembleTransactionResponse "交易组装成功"
+// @Failure 400 {object} types.AssembleTransactionResponse "签名缺失或无效"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/tx/assemble [post]
+func (th *TransactionHandler) AssembleTransaction(c *gin.Context) {
+	var req types.AssembleTransactionRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	resp, err := th.transactionService.AssembleTransaction(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to assemble transaction",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	// 失败时返回完整响应，便于调用方获取缺少签名的账户
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, resp)
+	}
+}
+
+// DecodeTransaction 解码交易
+// @Summary 解码交易
+// @Description 解码Base64编码的交易（legacy或v0），列出签名账户、付款人、区块哈希、计算预算，并由对应DEX适配器解码指令参数
+// @Tags 交易查询
+// @Accept json
+// @Produce json
+// @Param request body types.DecodeTransactionRequest true "交易解码请求参数"
+// @Success 200 {object} types.DecodeTransactionResponse "交易解码成功"
+// @Failure 400 {object} types.ErrorResponse "请求参数错误"
+// @Failure 500 {object} types.ErrorResponse "服务器内部错误"
+// @Router /api/v1/tx/decode [post]
+func (th *TransactionHandler) DecodeTransaction(c *gin.Context) {
+	var req types.DecodeTransactionRequest
+
+	// 绑定请求参数
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   "Invalid request parameters",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	resp, err := th.transactionService.DecodeTransaction(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{
+			Error:   "Failed to decode transaction",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if resp.Success {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Error:   resp.Error,
+			Details: "Transaction decode failed",
+		})
+	}
+}
+
+// limitViolations 返回预检发现的超出限制，未预检时为空
+func limitViolations(report *types.PreflightReport) []types.LimitViolation {
+	if report == nil {
+		return nil
+	}
+	return report.Violations
+}
+
+// setQuoteCacheHeader 在响应头中返回报价缓存结果，未启用缓存时不设置
+func setQuoteCacheHeader(c *gin.Context, status string) {
+	if status != "" {
+		c.Header("X-Quote-Cache", status)
+	}
+}
+
+// setRouteCacheHeaders 在响应头中返回跨DEX询价命中和未命中缓存的次数，合并的询价计为命中
+func setRouteCacheHeaders(c *gin.Context, resp *types.RouteQuoteResponse) {
+	quotes := append(append([]types.VenueQuote{}, resp.Alternatives...), resp.Failed...)
+	if resp.Best != nil {
+		quotes = append(quotes, *resp.Best)
+	}
+
+	hits, misses := 0, 0
+	for _, quote := range quotes {
+		if quote.Cache == "" {
+			continue
+		}
+		for _, status := range strings.Split(quote.Cache, ",") {
+			if status == adapters.QuoteCacheMiss {
+				misses++
+			} else {
+				hits++
+			}
+		}
+	}
+	c.Header("X-Quote-Cache-Hits", strconv.Itoa(hits))
+	c.Header("X-Quote-Cache-Misses", strconv.Itoa(misses))
+}
@@ -0,0 +1,357 @@
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"solana-dex-service/internal/config"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/jsonrpc"
+)
+
+// 端点角色
+const (
+	RoleRead = "read" // 读请求（查询、模拟等）
+	RoleSend = "send" // sendTransaction
+)
+
+// ewmaAlpha 延迟和错误率的指数加权系数
+const ewmaAlpha = 0.2
+
+// Endpoint RPC端点及其健康统计
+type Endpoint struct {
+	url    string
+	weight int
+	roles  map[string]bool
+	client *rpc.Client
+
+	mu        sync.Mutex
+	latency   time.Duration // 延迟（指数加权平均）
+	errorRate float64       // 错误率（指数加权平均）
+	slot      uint64        // 最近一次健康检查的slot
+	slotLag   uint64        // 落后于最高slot的数量
+	lastError string
+}
+
+// EndpointStatus 端点健康状态
+type EndpointStatus struct {
+	URL       string   `json:"url"`
+	Roles     []string `json:"roles"`
+	Weight    int      `json:"weight"`
+	LatencyMs int64    `json:"latency_ms"`
+	ErrorRate float64  `json:"error_rate"`
+	Slot      uint64   `json:"slot"`
+	SlotLag   uint64   `json:"slot_lag"`
+	Score     float64  `json:"score"`
+	LastError string   `json:"last_error,omitempty"`
+}
+
+// Pool RPC端点池，读请求按健康评分自动故障转移，发送请求并发广播
+type Pool struct {
+	endpoints      []*Endpoint
+	healthInterval time.Duration
+	maxSlotLag     uint64
+
+	stopMu sync.Mutex
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// New 根据端点配置创建RPC端点池
+func New(endpoints []config.RPCEndpointConfig, healthInterval time.Duration, maxSlotLag uint64) (*Pool, error) {
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("at least one rpc endpoint is required")
+	}
+	if healthInterval <= 0 {
+		healthInterval = 10 * time.Second
+	}
+
+	pool := &Pool{
+		healthInterval: healthInterval,
+		maxSlotLag:     maxSlotLag,
+	}
+
+	hasRead, hasSend := false, false
+	for i, cfg := range endpoints {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("rpc endpoint[%d] url is required", i)
+		}
+
+		roles := make(map[string]bool)
+		if len(cfg.Roles) == 0 {
+			roles[RoleRead] = true
+			roles[RoleSend] = true
+		}
+		for _, role := range cfg.Roles {
+			if role != RoleRead && role != RoleSend {
+				return nil, fmt.Errorf("rpc endpoint[%d] has unknown role: %s", i, role)
+			}
+			roles[role] = true
+		}
+		hasRead = hasRead || roles[RoleRead]
+		hasSend = hasSend || roles[RoleSend]
+
+		weight := cfg.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		pool.endpoints = append(pool.endpoints, &Endpoint{
+			url:    cfg.URL,
+			weight: weight,
+			roles:  roles,
+			client: rpc.New(cfg.URL),
+		})
+	}
+
+	if !hasRead {
+		return nil, fmt.Errorf("no rpc endpoint with role %q", RoleRead)
+	}
+	if !hasSend {
+		return nil, fmt.Errorf("no rpc endpoint with role %q", RoleSend)
+	}
+
+	return pool, nil
+}
+
+// Read 依次在评分最高的读端点上执行请求，失败时切换到下一个端点
+func (p *Pool) Read(ctx context.Context, fn func(ctx context.Context, client *rpc.Client) error) error {
+	var errs []string
+	for _, ep := range p.ranked(RoleRead) {
+		start := time.Now()
+		err := fn(ctx, ep.client)
+		ep.record(time.Since(start), err)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Sprintf("%s: %v", ep.url, err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return fmt.Errorf("all rpc endpoints failed: %s", strings.Join(errs, "; "))
+}
+
+// Send 将交易并发发送到所有具备发送角色的端点，返回第一个成功的签名
+func (p *Pool) Send(ctx context.Context, tx *solana.Transaction) (solana.Signature, error) {
+	endpoints := p.ranked(RoleSend)
+
+	type sendResult struct {
+		url       string
+		signature solana.Signature
+		err       error
+	}
+	results := make(chan sendResult, len(endpoints))
+
+	for _, ep := range endpoints {
+		go func(ep *Endpoint) {
+			start := time.Now()
+			signature, err := ep.client.SendTransaction(ctx, tx)
+			ep.record(time.Since(start), err)
+			results <- sendResult{url: ep.url, signature: signature, err: err}
+		}(ep)
+	}
+
+	var errs []string
+	for range endpoints {
+		result := <-results
+		if result.err == nil {
+			return result.signature, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", result.url, result.err))
+	}
+
+	return solana.Signature{}, fmt.Errorf("all rpc endpoints failed to send transaction: %s", strings.Join(errs, "; "))
+}
+
+// Start 启动后台健康检查
+func (p *Pool) Start() {
+	p.stopMu.Lock()
+	defer p.stopMu.Unlock()
+	if p.stopCh != nil {
+		return
+	}
+	p.stopCh = make(chan struct{})
+	stopCh := p.stopCh
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.healthInterval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), p.healthInterval)
+			p.CheckHealth(ctx)
+			cancel()
+
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台健康检查
+func (p *Pool) Stop() {
+	p.stopMu.Lock()
+	if p.stopCh == nil {
+		p.stopMu.Unlock()
+		return
+	}
+	close(p.stopCh)
+	p.stopCh = nil
+	p.stopMu.Unlock()
+
+	p.wg.Wait()
+}
+
+// CheckHealth 查询所有端点的slot，更新延迟、错误率和slot落后量
+func (p *Pool) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *Endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			slot, err := ep.client.GetSlot(ctx, rpc.CommitmentProcessed)
+			ep.record(time.Since(start), err)
+			if err != nil {
+				log.Printf("rpc endpoint %s health check failed: %v", ep.url, err)
+				return
+			}
+			ep.mu.Lock()
+			ep.slot = slot
+			ep.mu.Unlock()
+		}(ep)
+	}
+	wg.Wait()
+
+	// 计算各端点落后于最高slot的数量
+	var maxSlot uint64
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		if ep.slot > maxSlot {
+			maxSlot = ep.slot
+		}
+		ep.mu.Unlock()
+	}
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		if ep.slot > 0 {
+			ep.slotLag = maxSlot - ep.slot
+		}
+		ep.mu.Unlock()
+	}
+}
+
+// Status 返回所有端点的健康状态，按评分从高到低排列
+func (p *Pool) Status() []EndpointStatus {
+	var statuses []EndpointStatus
+	for _, ep := range p.endpoints {
+		ep.mu.Lock()
+		status := EndpointStatus{
+			URL:       ep.url,
+			Weight:    ep.weight,
+			LatencyMs: ep.latency.Milliseconds(),
+			ErrorRate: ep.errorRate,
+			Slot:      ep.slot,
+			SlotLag:   ep.slotLag,
+			Score:     p.scoreLocked(ep),
+			LastError: ep.lastError,
+		}
+		ep.mu.Unlock()
+
+		for _, role := range []string{RoleRead, RoleSend} {
+			if ep.roles[role] {
+				status.Roles = append(status.Roles, role)
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Score > statuses[j].Score
+	})
+	return statuses
+}
+
+// ranked 返回具备指定角色的端点，按评分从高到低排列
+func (p *Pool) ranked(role string) []*Endpoint {
+	type scored struct {
+		ep    *Endpoint
+		score float64
+	}
+	var candidates []scored
+	for _, ep := range p.endpoints {
+		if !ep.roles[role] {
+			continue
+		}
+		ep.mu.Lock()
+		candidates = append(candidates, scored{ep: ep, score: p.scoreLocked(ep)})
+		ep.mu.Unlock()
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	endpoints := make([]*Endpoint, len(candidates))
+	for i, c := range candidates {
+		endpoints[i] = c.ep
+	}
+	return endpoints
+}
+
+// scoreLocked 计算端点健康评分，调用方需持有ep.mu
+// 评分 = 权重 × 延迟因子 × 成功率 × slot落后因子，落后超过上限的端点降到最低
+func (p *Pool) scoreLocked(ep *Endpoint) float64 {
+	latencyFactor := 1 / (1 + float64(ep.latency.Milliseconds())/100)
+	successRate := 1 - ep.errorRate
+	lagFactor := 1 / (1 + float64(ep.slotLag)/10)
+
+	score := float64(ep.weight) * latencyFactor * successRate * lagFactor
+	if p.maxSlotLag > 0 && ep.slotLag > p.maxSlotLag {
+		score *= 0.01
+	}
+	return score
+}
+
+// record 记录一次请求的延迟和结果
+func (ep *Endpoint) record(latency time.Duration, err error) {
+	// 节点返回的JSON-RPC错误、未找到结果和调用方取消都说明端点可用，不计入错误率
+	var rpcErr *jsonrpc.RPCError
+	failed := err != nil &&
+		!errors.As(err, &rpcErr) &&
+		!errors.Is(err, rpc.ErrNotFound) &&
+		!errors.Is(err, context.Canceled)
+
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency = time.Duration((1-ewmaAlpha)*float64(ep.latency) + ewmaAlpha*float64(latency))
+	}
+
+	sample := 0.0
+	if failed {
+		sample = 1
+		ep.lastError = err.Error()
+	}
+	ep.errorRate = (1-ewmaAlpha)*ep.errorRate + ewmaAlpha*sample
+}
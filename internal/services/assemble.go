@@ -0,0 +1,157 @@
+package services
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// AssembleTransaction 将交易消息与各签名方的分离签名组装成完整签名的交易
+func (ts *TransactionService) AssembleTransaction(req *types.AssembleTransactionRequest) (*types.AssembleTransactionResponse, error) {
+	var message solana.Message
+	var existing []solana.Signature
+	switch {
+	case req.Message != "" && req.Transaction != "":
+		return &types.AssembleTransactionResponse{
+			Success: false,
+			Error:   "only one of message and transaction may be provided",
+		}, nil
+	case req.Message != "":
+		if err := message.UnmarshalBase64(req.Message); err != nil {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to decode message: %v", err),
+			}, nil
+		}
+	case req.Transaction != "":
+		tx, err := decodeTransaction(req.Transaction)
+		if err != nil {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   err.Error(),
+			}, nil
+		}
+		message = tx.Message
+		existing = tx.Signatures
+	default:
+		return &types.AssembleTransactionResponse{
+			Success: false,
+			Error:   "message or transaction is required",
+		}, nil
+	}
+
+	messageData, err := message.MarshalBinary()
+	if err != nil {
+		return &types.AssembleTransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize message: %v", err),
+		}, nil
+	}
+
+	numSigners := int(message.Header.NumRequiredSignatures)
+	if numSigners > len(message.AccountKeys) {
+		return &types.AssembleTransactionResponse{
+			Success: false,
+			Error:   "message requires more signatures than it has accounts",
+		}, nil
+	}
+
+	tx := &solana.Transaction{
+		Signatures: make([]solana.Signature, numSigners),
+		Message:    message,
+	}
+
+	// 传入交易中已有的签名保留在对应的签名位置，同样需要与消息匹配
+	for i, signature := range existing {
+		if i >= numSigners || signature.IsZero() {
+			continue
+		}
+		if !signature.Verify(message.AccountKeys[i], messageData) {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Existing signature for %s does not match the message", message.AccountKeys[i]),
+			}, nil
+		}
+		tx.Signatures[i] = signature
+	}
+
+	for _, detached := range req.Signatures {
+		publicKey, err := solana.PublicKeyFromBase58(detached.PublicKey)
+		if err != nil {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid public key %s: %v", detached.PublicKey, err),
+			}, nil
+		}
+		signature, err := solana.SignatureFromBase58(detached.Signature)
+		if err != nil {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid signature for %s: %v", detached.PublicKey, err),
+			}, nil
+		}
+
+		index := signerIndex(&message, publicKey)
+		if index < 0 {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("%s is not a required signer of this transaction", publicKey),
+			}, nil
+		}
+		if !signature.Verify(publicKey, messageData) {
+			return &types.AssembleTransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Signature for %s does not match the message", publicKey),
+			}, nil
+		}
+		tx.Signatures[index] = signature
+	}
+
+	if missing := missingSigners(tx); len(missing) > 0 {
+		return &types.AssembleTransactionResponse{
+			Success:        false,
+			MissingSigners: missing,
+			Error:          fmt.Sprintf("missing signatures for %d signer(s)", len(missing)),
+		}, nil
+	}
+
+	txData, err := tx.MarshalBinary()
+	if err != nil {
+		return &types.AssembleTransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+
+	return &types.AssembleTransactionResponse{
+		Success:     true,
+		Transaction: base64.StdEncoding.EncodeToString(txData),
+		Signature:   tx.Signatures[0].String(),
+	}, nil
+}
+
+// signerIndex 返回公钥在签名账户中的位置，不是签名账户时返回-1
+func signerIndex(message *solana.Message, publicKey solana.PublicKey) int {
+	numSigners := int(message.Header.NumRequiredSignatures)
+	for i := 0; i < numSigners && i < len(message.AccountKeys); i++ {
+		if message.AccountKeys[i].Equals(publicKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// missingSigners 返回尚未签名的签名账户
+func missingSigners(tx *solana.Transaction) []string {
+	var missing []string
+	numSigners := int(tx.Message.Header.NumRequiredSignatures)
+	for i := 0; i < numSigners && i < len(tx.Message.AccountKeys); i++ {
+		if i >= len(tx.Signatures) || tx.Signatures[i].IsZero() {
+			missing = append(missing, tx.Message.AccountKeys[i].String())
+		}
+	}
+	return missing
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"solana-dex-service/internal/types"
+)
+
+// EncodeBatch 使用有界工作协程池并发编码多个交换和流动性请求
+// 所有条目共用一次获取的区块哈希，结果按请求顺序返回，单个条目失败不影响其他条目
+func (ts *TransactionService) EncodeBatch(ctx context.Context, req *types.BatchEncodeRequest) (*types.BatchEncodeResponse, error) {
+	cfg := ts.currentConfig()
+	maxItems, workers, timeout := cfg.Batch.MaxItems, cfg.Batch.Workers, cfg.Batch.Timeout
+
+	if len(req.Items) == 0 {
+		return &types.BatchEncodeResponse{
+			Success: false,
+			Error:   "batch must contain at least one item",
+		}, nil
+	}
+	if len(req.Items) > maxItems {
+		return &types.BatchEncodeResponse{
+			Success: false,
+			Error:   fmt.Sprintf("batch contains %d items, maximum is %d", len(req.Items), maxItems),
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	blockhash, err := ts.blockhashes.Get(ctx)
+	if err != nil {
+		return &types.BatchEncodeResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to get latest blockhash: %v", err),
+		}, nil
+	}
+
+	if workers > len(req.Items) {
+		workers = len(req.Items)
+	}
+
+	// 超时或取消后不再分发新的条目，已开始的条目随ctx取消后丢弃
+	jobs := make(chan int)
+	results := make(chan types.BatchEncodeResult, len(req.Items))
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				results <- ts.encodeBatchItem(ctx, i, &req.Items[i], blockhash)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range req.Items {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	resp := &types.BatchEncodeResponse{
+		Success:              true,
+		Results:              make([]types.BatchEncodeResult, len(req.Items)),
+		Blockhash:            blockhash.Blockhash.String(),
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+	}
+	done := make([]bool, len(req.Items))
+collect:
+	for remaining := len(req.Items); remaining > 0; remaining-- {
+		select {
+		case result := <-results:
+			resp.Results[result.Index] = result
+			done[result.Index] = true
+		case <-ctx.Done():
+			break collect
+		}
+	}
+
+	reason := fmt.Sprintf("batch timed out after %s", timeout)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		reason = "batch canceled"
+	}
+	for i := range resp.Results {
+		if !done[i] {
+			resp.Results[i] = types.BatchEncodeResult{
+				Index: i,
+				Type:  req.Items[i].Type,
+				Error: reason,
+			}
+		}
+		if resp.Results[i].Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+
+	return resp, nil
+}
+
+// encodeBatchItem 编码批量请求中的单个条目
+func (ts *TransactionService) encodeBatchItem(ctx context.Context, index int, item *types.BatchEncodeItem, blockhash *BlockhashInfo) types.BatchEncodeResult {
+	result := types.BatchEncodeResult{
+		Index: index,
+		Type:  item.Type,
+	}
+
+	var resp *types.TransactionResponse
+	var err error
+	switch item.Type {
+	case "swap":
+		if item.Swap == nil {
+			result.Error = "swap request is required for swap items"
+			return result
+		}
+		resp, err = ts.encodeSwap(ctx, item.Swap, blockhash)
+	case "liquidity":
+		if item.Liquidity == nil {
+			result.Error = "liquidity request is required for liquidity items"
+			return result
+		}
+		resp, err = ts.encodeLiquidity(ctx, item.Liquidity, blockhash)
+	default:
+		result.Error = fmt.Sprintf("unsupported item type: %s", item.Type)
+		return result
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if !resp.Success {
+		result.Error = resp.Error
+		return result
+	}
+	result.Success = true
+	result.Response = resp
+	return result
+}
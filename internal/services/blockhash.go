@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"solana-dex-service/internal/rpcpool"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// BlockhashInfo 缓存的区块哈希信息
+type BlockhashInfo struct {
+	Blockhash            solana.Hash // 区块哈希
+	LastValidBlockHeight uint64      // 该区块哈希可用的最后区块高度
+	FetchedAt            time.Time   // 获取时间
+}
+
+// BlockhashManager 区块哈希管理器，后台定期刷新并缓存最新区块哈希
+type BlockhashManager struct {
+	rpcPool         *rpcpool.Pool
+	commitment      rpc.CommitmentType
+	refreshInterval time.Duration
+
+	mu      sync.RWMutex
+	current *BlockhashInfo
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewBlockhashManager 创建区块哈希管理器
+func NewBlockhashManager(rpcPool *rpcpool.Pool, commitment string, refreshInterval time.Duration) *BlockhashManager {
+	return &BlockhashManager{
+		rpcPool:         rpcPool,
+		commitment:      rpc.CommitmentType(commitment),
+		refreshInterval: refreshInterval,
+	}
+}
+
+// Start 启动后台刷新，未配置刷新间隔时不启动，每次获取都从RPC查询
+func (m *BlockhashManager) Start() {
+	if m.refreshInterval <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	if m.stopCh != nil {
+		m.mu.Unlock()
+		return
+	}
+	m.stopCh = make(chan struct{})
+	stopCh := m.stopCh
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), m.refreshInterval)
+			if _, err := m.Refresh(ctx); err != nil {
+				log.Printf("failed to refresh blockhash: %v", err)
+			}
+			cancel()
+
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台刷新
+func (m *BlockhashManager) Stop() {
+	m.mu.Lock()
+	if m.stopCh == nil {
+		m.mu.Unlock()
+		return
+	}
+	close(m.stopCh)
+	m.stopCh = nil
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+// Get 获取区块哈希，缓存未过期时直接返回缓存
+func (m *BlockhashManager) Get(ctx context.Context) (*BlockhashInfo, error) {
+	m.mu.RLock()
+	current := m.current
+	m.mu.RUnlock()
+
+	// 后台刷新周期的两倍内视为新鲜
+	if current != nil && time.Since(current.FetchedAt) < 2*m.refreshInterval {
+		return current, nil
+	}
+
+	return m.Refresh(ctx)
+}
+
+// Refresh 从RPC获取最新区块哈希并更新缓存
+func (m *BlockhashManager) Refresh(ctx context.Context) (*BlockhashInfo, error) {
+	var result *rpc.GetLatestBlockhashResult
+	err := m.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+		result, err = client.GetLatestBlockhash(ctx, m.commitment)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest blockhash: %w", err)
+	}
+	if result == nil || result.Value == nil {
+		return nil, fmt.Errorf("latest blockhash returned null")
+	}
+
+	info := &BlockhashInfo{
+		Blockhash:            result.Value.Blockhash,
+		LastValidBlockHeight: result.Value.LastValidBlockHeight,
+		FetchedAt:            time.Now(),
+	}
+
+	m.mu.Lock()
+	m.current = info
+	m.mu.Unlock()
+
+	return info, nil
+}
@@ -1,27 +1,70 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/store"
 
 	"gopkg.in/yaml.v3"
 )
 
+// ErrConfigVersionMismatch 修改配置时指定的版本不是当前版本，配置已被其他请求修改
+var ErrConfigVersionMismatch = errors.New("config version mismatch")
+
 // ConfigService 配置服务
+// 配置以不可变快照的形式保存，读取无需加锁；修改串行执行，在副本上修改后整体替换
 type ConfigService struct {
-	config     *config.Config
+	state      atomic.Pointer[configState]
+	writeMu    sync.Mutex // 串行化配置修改和版本记录
 	configPath string
+	listeners  []func(cfg *config.Config)
+	history    store.ConfigHistoryStore
+
+	fileMu   sync.Mutex
+	fileHash [sha256.Size]byte // 最近一次读写的配置文件内容摘要，用于忽略自身写入触发的文件事件
+
+	stopMu sync.Mutex
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// configState 配置快照，发布后不再修改
+type configState struct {
+	config  *config.Config
+	version string // 配置内容摘要，用于 If-Match 乐观并发控制
 }
 
 // NewConfigService 创建配置服务
 func NewConfigService(cfg *config.Config) *ConfigService {
-	return &ConfigService{
-		config:     cfg,
+	cs := &ConfigService{
 		configPath: "config/config.yaml", // 默认配置文件路径
 	}
+	cs.state.Store(newConfigState(cfg))
+	return cs
+}
+
+// newConfigState 创建配置快照并计算版本
+func newConfigState(cfg *config.Config) *configState {
+	data, err := marshalConfig(cfg)
+	if err != nil {
+		return &configState{config: cfg}
+	}
+	return &configState{config: cfg, version: configVersion(data)}
+}
+
+// configVersion 配置版本：持久化内容的SHA-256摘要前16位十六进制
+func configVersion(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:8])
 }
 
 // SetConfigPath 设置配置文件路径
@@ -29,288 +72,376 @@ func (cs *ConfigService) SetConfigPath(path string) {
 	cs.configPath = path
 }
 
-// GetConfig 获取完整配置
-func (cs *ConfigService) GetConfig() *config.Config {
-	return cs.config
+// OnChange 注册配置变更通知，内存中的配置变更后按注册顺序调用
+// 传入的配置是只读快照，订阅者与处理中的请求并发读取，需通过atomic.Pointer等方式原子发布，不能赋值给普通字段
+func (cs *ConfigService) OnChange(listener func(cfg *config.Config)) {
+	cs.listeners = append(cs.listeners, listener)
 }
 
-// UpdateConfig 更新完整配置
-func (cs *ConfigService) UpdateConfig(newConfig *config.Config) error {
-	// 验证新配置
-	if err := newConfig.Validate(); err != nil {
-		return fmt.Errorf("invalid config: %w", err)
+// applied 记录配置版本并通知订阅者应用当前配置，返回记录的版本号（未记录时为0），调用方持有writeMu
+func (cs *ConfigService) applied(info ChangeInfo) int {
+	version := cs.recordVersion(info)
+	cfg := cs.current()
+	for _, listener := range cs.listeners {
+		listener(cfg)
 	}
+	return version
+}
+
+// current 获取当前配置快照
+func (cs *ConfigService) current() *config.Config {
+	return cs.state.Load().config
+}
 
-	// 设置默认值
-	newConfig.SetDefaults()
+// update 串行修改配置：change基于当前配置返回新配置（不能修改传入的配置），
+// 新配置通过验证并写入文件后替换当前快照并通知订阅者；persist为false时不写入文件（如从文件重新加载）
+func (cs *ConfigService) update(info ChangeInfo, persist bool, change func(current *config.Config) (*config.Config, error)) (string, int, error) {
+	cs.writeMu.Lock()
+	defer cs.writeMu.Unlock()
 
-	// 保存到文件
-	if err := cs.saveConfigToFile(newConfig); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	current := cs.state.Load()
+	if info.IfMatch != "" && info.IfMatch != current.version {
+		return "", 0, fmt.Errorf("%w: current version is %s", ErrConfigVersionMismatch, current.version)
 	}
 
-	// 更新内存中的配置
-	cs.config = newConfig
+	next, err := change(current.config)
+	if err != nil {
+		return "", 0, err
+	}
 
-	return nil
+	// 所有修改在写入前统一补全默认值并验证完整配置，部分修改不会留下零值超时或工作协程数
+	next.SetDefaults()
+	if err := next.Validate(); err != nil {
+		return "", 0, fmt.Errorf("invalid config: %w", err)
+	}
+	data, err := marshalConfig(next)
+	if err != nil {
+		return "", 0, err
+	}
+	if persist {
+		if err := cs.writeConfigFile(cs.configPath, data); err != nil {
+			return "", 0, fmt.Errorf("failed to save config: %w", err)
+		}
+	}
+
+	state := &configState{config: next, version: configVersion(data)}
+	cs.state.Store(state)
+	return state.version, cs.applied(info), nil
 }
 
-// GetDEXConfig 获取DEX配置
-func (cs *ConfigService) GetDEXConfig() []config.DEXConfig {
-	return cs.config.DEXes
+// GetConfig 获取完整配置，返回的是只读快照
+func (cs *ConfigService) GetConfig() *config.Config {
+	return cs.current()
 }
 
-// UpdateDEXConfig 更新DEX配置
-func (cs *ConfigService) UpdateDEXConfig(dexConfigs []config.DEXConfig) error {
-	// 验证DEX配置
-	for i, dex := range dexConfigs {
-		if dex.Name == "" {
-			return fmt.Errorf("dex[%d] name is required", i)
-		}
-		if dex.ProgramID == "" {
-			return fmt.Errorf("dex[%d] program_id is required", i)
-		}
-		// 设置更新时间
-		dexConfigs[i].UpdatedAt = time.Now()
-		if dexConfigs[i].CreatedAt.IsZero() {
-			dexConfigs[i].CreatedAt = time.Now()
-		}
-	}
+// Snapshot 获取当前配置快照及其版本
+func (cs *ConfigService) Snapshot() (*config.Config, string) {
+	state := cs.state.Load()
+	return state.config, state.version
+}
 
-	// 更新配置
-	cs.config.DEXes = dexConfigs
+// UpdateConfig 更新完整配置，返回新的配置版本
+func (cs *ConfigService) UpdateConfig(newConfig *config.Config, info ...ChangeInfo) (string, error) {
+	version, _, err := cs.update(changeInfo(info, "update config"), true, func(*config.Config) (*config.Config, error) {
+		next := newConfig.Clone()
 
-	// 保存到文件
-	if err := cs.saveConfigToFile(cs.config); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
+		// 环境变量的优先级高于接口提交的配置
+		if err := next.ApplyEnv(os.LookupEnv); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
 
-	return nil
+		// 设置默认值
+		next.SetDefaults()
+		return next, nil
+	})
+	return version, err
 }
 
-// AddDEXConfig 添加新的DEX配置
-func (cs *ConfigService) AddDEXConfig(dexConfig config.DEXConfig) error {
-	// 检查是否已存在同名DEX
-	for _, existing := range cs.config.DEXes {
-		if existing.Name == dexConfig.Name {
-			return fmt.Errorf("DEX with name '%s' already exists", dexConfig.Name)
-		}
-	}
+// GetDEXConfig 获取DEX配置，返回的是只读快照
+func (cs *ConfigService) GetDEXConfig() []config.DEXConfig {
+	return cs.current().DEXes
+}
 
-	// 验证新配置
-	if dexConfig.Name == "" {
-		return fmt.Errorf("DEX name is required")
-	}
-	if dexConfig.ProgramID == "" {
-		return fmt.Errorf("DEX program_id is required")
-	}
+// UpdateDEXConfig 更新DEX配置
+func (cs *ConfigService) UpdateDEXConfig(dexConfigs []config.DEXConfig, info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "update dex config"), true, func(current *config.Config) (*config.Config, error) {
+		next := *current
+		next.DEXes = dexConfigs
+		clone := next.Clone()
 
-	// 设置时间戳
-	dexConfig.CreatedAt = time.Now()
-	dexConfig.UpdatedAt = time.Now()
+		// 设置更新时间
+		for i := range clone.DEXes {
+			clone.DEXes[i].UpdatedAt = time.Now()
+			if clone.DEXes[i].CreatedAt.IsZero() {
+				clone.DEXes[i].CreatedAt = time.Now()
+			}
+		}
+		return clone, nil
+	})
+	return err
+}
 
-	// 设置默认值
-	if dexConfig.Timeout == 0 {
-		dexConfig.Timeout = 30 * time.Second
-	}
-	if dexConfig.RetryCount == 0 {
-		dexConfig.RetryCount = 3
-	}
+// AddDEXConfig 添加新的DEX配置
+func (cs *ConfigService) AddDEXConfig(dexConfig config.DEXConfig, info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "add dex "+dexConfig.Name), true, func(current *config.Config) (*config.Config, error) {
+		// 检查是否已存在同名DEX
+		for _, existing := range current.DEXes {
+			if existing.Name == dexConfig.Name {
+				return nil, fmt.Errorf("DEX with name '%s' already exists", dexConfig.Name)
+			}
+		}
 
-	// 添加到配置列表
-	cs.config.DEXes = append(cs.config.DEXes, dexConfig)
+		// 设置时间戳
+		dexConfig.CreatedAt = time.Now()
+		dexConfig.UpdatedAt = time.Now()
 
-	// 保存到文件
-	if err := cs.saveConfigToFile(cs.config); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
+		// 设置默认值
+		if dexConfig.Timeout == 0 {
+			dexConfig.Timeout = 30 * time.Second
+		}
+		if dexConfig.RetryCount == 0 {
+			dexConfig.RetryCount = 3
+		}
 
-	return nil
+		// 添加到配置列表
+		next := *current
+		next.DEXes = append(append([]config.DEXConfig(nil), current.DEXes...), dexConfig)
+		return next.Clone(), nil
+	})
+	return err
 }
 
 // RemoveDEXConfig 移除DEX配置
-func (cs *ConfigService) RemoveDEXConfig(dexName string) error {
-	// 查找要删除的DEX
-	index := -1
-	for i, dex := range cs.config.DEXes {
-		if dex.Name == dexName {
-			index = i
-			break
+func (cs *ConfigService) RemoveDEXConfig(dexName string, info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "remove dex "+dexName), true, func(current *config.Config) (*config.Config, error) {
+		// 查找要删除的DEX
+		index := -1
+		for i, dex := range current.DEXes {
+			if dex.Name == dexName {
+				index = i
+				break
+			}
 		}
-	}
-
-	if index == -1 {
-		return fmt.Errorf("DEX with name '%s' not found", dexName)
-	}
-
-	// 从切片中移除
-	cs.config.DEXes = append(cs.config.DEXes[:index], cs.config.DEXes[index+1:]...)
 
-	// 保存到文件
-	if err := cs.saveConfigToFile(cs.config); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
+		if index == -1 {
+			return nil, fmt.Errorf("DEX with name '%s' not found", dexName)
+		}
 
-	return nil
+		// 从副本中移除
+		next := current.Clone()
+		next.DEXes = append(next.DEXes[:index], next.DEXes[index+1:]...)
+		return next, nil
+	})
+	return err
 }
 
 // EnableDEX 启用DEX
-func (cs *ConfigService) EnableDEX(dexName string) error {
-	return cs.setDEXEnabled(dexName, true)
+func (cs *ConfigService) EnableDEX(dexName string, info ...ChangeInfo) error {
+	return cs.setDEXEnabled(dexName, true, changeInfo(info, "enable dex "+dexName))
 }
 
 // DisableDEX 禁用DEX
-func (cs *ConfigService) DisableDEX(dexName string) error {
-	return cs.setDEXEnabled(dexName, false)
+func (cs *ConfigService) DisableDEX(dexName string, info ...ChangeInfo) error {
+	return cs.setDEXEnabled(dexName, false, changeInfo(info, "disable dex "+dexName))
 }
 
 // setDEXEnabled 设置DEX启用状态
-func (cs *ConfigService) setDEXEnabled(dexName string, enabled bool) error {
-	// 查找DEX
-	for i, dex := range cs.config.DEXes {
-		if dex.Name == dexName {
-			cs.config.DEXes[i].Enabled = enabled
-			cs.config.DEXes[i].UpdatedAt = time.Now()
-
-			// 保存到文件
-			if err := cs.saveConfigToFile(cs.config); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
+func (cs *ConfigService) setDEXEnabled(dexName string, enabled bool, info ChangeInfo) error {
+	_, _, err := cs.update(info, true, func(current *config.Config) (*config.Config, error) {
+		// 查找DEX
+		for i, dex := range current.DEXes {
+			if dex.Name == dexName {
+				next := current.Clone()
+				next.DEXes[i].Enabled = enabled
+				next.DEXes[i].UpdatedAt = time.Now()
+				return next, nil
 			}
-
-			return nil
 		}
-	}
 
-	return fmt.Errorf("DEX with name '%s' not found", dexName)
+		return nil, fmt.Errorf("DEX with name '%s' not found", dexName)
+	})
+	return err
 }
 
 // GetServerConfig 获取服务器配置
 func (cs *ConfigService) GetServerConfig() *config.ServerConfig {
-	return &cs.config.Server
+	server := cs.current().Server
+	return &server
 }
 
 // UpdateServerConfig 更新服务器配置
-func (cs *ConfigService) UpdateServerConfig(serverConfig *config.ServerConfig) error {
-	// 验证服务器配置
-	if serverConfig.Port <= 0 || serverConfig.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", serverConfig.Port)
-	}
-
-	// 更新配置
-	cs.config.Server = *serverConfig
-
-	// 保存到文件
-	if err := cs.saveConfigToFile(cs.config); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
-
-	return nil
+func (cs *ConfigService) UpdateServerConfig(serverConfig *config.ServerConfig, info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "update server config"), true, func(current *config.Config) (*config.Config, error) {
+		next := current.Clone()
+		next.Server = *serverConfig
+		return next, nil
+	})
+	return err
 }
 
 // GetSolanaConfig 获取Solana配置
 func (cs *ConfigService) GetSolanaConfig() *config.SolanaConfig {
-	return &cs.config.Solana
+	solana := cs.current().Solana
+	return &solana
 }
 
 // UpdateSolanaConfig 更新Solana配置
-func (cs *ConfigService) UpdateSolanaConfig(solanaConfig *config.SolanaConfig) error {
-	// 验证Solana配置
-	if solanaConfig.RPCURL == "" {
-		return fmt.Errorf("solana rpc_url is required")
-	}
-	if solanaConfig.Network == "" {
-		return fmt.Errorf("solana network is required")
-	}
-
-	// 更新配置
-	cs.config.Solana = *solanaConfig
-
-	// 保存到文件
-	if err := cs.saveConfigToFile(cs.config); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
-	}
-
-	return nil
+func (cs *ConfigService) UpdateSolanaConfig(solanaConfig *config.SolanaConfig, info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "update solana config"), true, func(current *config.Config) (*config.Config, error) {
+		next := *current
+		next.Solana = *solanaConfig
+		return next.Clone(), nil
+	})
+	return err
 }
 
 // ReloadConfig 重新加载配置文件
-func (cs *ConfigService) ReloadConfig() error {
-	newConfig, err := config.LoadConfig(cs.configPath)
-	if err != nil {
-		return fmt.Errorf("failed to reload config: %w", err)
-	}
-
-	cs.config = newConfig
-	return nil
+func (cs *ConfigService) ReloadConfig(info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "reload config file"), false, func(*config.Config) (*config.Config, error) {
+		newConfig, err := config.LoadConfig(cs.configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reload config: %w", err)
+		}
+		return newConfig, nil
+	})
+	return err
 }
 
 // BackupConfig 备份当前配置
 func (cs *ConfigService) BackupConfig() error {
 	backupPath := fmt.Sprintf("%s.backup.%d", cs.configPath, time.Now().Unix())
-	return cs.saveConfigToPath(cs.config, backupPath)
+	return cs.saveConfigToPath(cs.current(), backupPath)
 }
 
 // RestoreConfig 从备份恢复配置
-func (cs *ConfigService) RestoreConfig(backupPath string) error {
-	// 加载备份配置
-	backupConfig, err := config.LoadConfig(backupPath)
-	if err != nil {
-		return fmt.Errorf("failed to load backup config: %w", err)
-	}
-
-	// 保存为当前配置
-	if err := cs.saveConfigToFile(backupConfig); err != nil {
-		return fmt.Errorf("failed to restore config: %w", err)
-	}
-
-	// 更新内存中的配置
-	cs.config = backupConfig
-
-	return nil
+func (cs *ConfigService) RestoreConfig(backupPath string, info ...ChangeInfo) error {
+	_, _, err := cs.update(changeInfo(info, "restore config from "+backupPath), true, func(*config.Config) (*config.Config, error) {
+		// 加载备份配置
+		backupConfig, err := config.LoadConfig(backupPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load backup config: %w", err)
+		}
+		return backupConfig, nil
+	})
+	return err
 }
 
 // ValidateConfig 验证配置
 func (cs *ConfigService) ValidateConfig() error {
-	return cs.config.Validate()
+	return cs.current().Validate()
+}
+
+// CheckConfig 深度验证配置并返回发现的所有问题，candidate为nil时验证当前配置
+// 候选配置与更新时一样先应用环境变量覆盖
+func (cs *ConfigService) CheckConfig(candidate *config.Config) (config.ValidationErrors, error) {
+	if candidate == nil {
+		return cs.current().ValidateAll(), nil
+	}
+
+	next := candidate.Clone()
+	if err := next.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return next.ValidateAll(), nil
 }
 
 // GetConfigSummary 获取配置摘要
 func (cs *ConfigService) GetConfigSummary() map[string]interface{} {
+	cfg, version := cs.Snapshot()
 	enabledDEXes := 0
-	for _, dex := range cs.config.DEXes {
+	for _, dex := range cfg.DEXes {
 		if dex.Enabled {
 			enabledDEXes++
 		}
 	}
 
 	return map[string]interface{}{
-		"server_port":    cs.config.Server.Port,
-		"server_mode":    cs.config.Server.Mode,
-		"solana_network": cs.config.Solana.Network,
-		"solana_rpc":     cs.config.Solana.RPCURL,
-		"total_dexes":    len(cs.config.DEXes),
+		"server_port":    cfg.Server.Port,
+		"server_mode":    cfg.Server.Mode,
+		"solana_network": cfg.Solana.Network,
+		"solana_rpc":     cfg.Solana.RPCURL,
+		"total_dexes":    len(cfg.DEXes),
 		"enabled_dexes":  enabledDEXes,
-		"log_level":      cs.config.Logging.Level,
+		"log_level":      cfg.Logging.Level,
+		"config_version": version,
 	}
 }
 
-// saveConfigToFile 保存配置到默认文件
-func (cs *ConfigService) saveConfigToFile(cfg *config.Config) error {
-	return cs.saveConfigToPath(cfg, cs.configPath)
-}
-
 // saveConfigToPath 保存配置到指定路径
 func (cs *ConfigService) saveConfigToPath(cfg *config.Config, path string) error {
 	// 序列化配置为YAML
-	data, err := yaml.Marshal(cfg)
+	data, err := marshalConfig(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
 
-	// 写入文件
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := cs.writeConfigFile(path, data); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
+	return nil
+}
+
+// writeConfigFile 原子写入配置文件：写入同目录的临时文件并fsync后重命名替换，
+// 写入过程中失败或进程退出不会留下不完整的配置文件
+func (cs *ConfigService) writeConfigFile(path string, data []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // 重命名成功后临时文件已不存在
 
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+
+	// 同步目录项，确保重命名在断电后仍然有效
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+
+	if path == cs.configPath {
+		cs.rememberFile(data)
+	}
 	return nil
+}
+
+// marshalConfig 序列化配置，环境变量覆盖的字段保留配置文件中的值，避免把密钥写入文件和版本历史
+func marshalConfig(cfg *config.Config) ([]byte, error) {
+	persistable, err := cfg.Persistable()
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(persistable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// GetConfigSources 获取每个配置字段的有效值来源
+func (cs *ConfigService) GetConfigSources() []config.ValueSource {
+	return cs.current().Sources()
 }
\ No newline at end of file
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/store"
+	"solana-dex-service/internal/types"
+	pkgtypes "solana-dex-service/pkg/types"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrConfigHistoryDisabled 未设置配置版本存储
+var ErrConfigHistoryDisabled = errors.New("config history is not enabled")
+
+// ChangeInfo 配置变更的作者和原因，记录到配置版本中
+type ChangeInfo struct {
+	Author  string
+	Reason  string
+	IfMatch string // 期望的当前配置版本，非空且与当前版本不同时拒绝修改
+}
+
+// changeInfo 合并调用方提供的变更信息和默认值
+func changeInfo(info []ChangeInfo, defaultReason string) ChangeInfo {
+	result := ChangeInfo{Author: "system", Reason: defaultReason}
+	if len(info) > 0 {
+		if info[0].Author != "" {
+			result.Author = info[0].Author
+		}
+		if info[0].Reason != "" {
+			result.Reason = info[0].Reason
+		}
+		result.IfMatch = info[0].IfMatch
+	}
+	return result
+}
+
+// SetHistoryStore 设置配置版本存储，当前配置与最新版本不同时记录为新版本
+func (cs *ConfigService) SetHistoryStore(s store.ConfigHistoryStore) {
+	cs.writeMu.Lock()
+	defer cs.writeMu.Unlock()
+	cs.history = s
+
+	current, err := marshalConfig(cs.current())
+	if err != nil {
+		log.Printf("failed to marshal config for history: %v", err)
+		return
+	}
+	latest, err := s.Latest()
+	if err == nil && latest.Config == string(current) {
+		return
+	}
+	if err != nil && !errors.Is(err, store.ErrConfigVersionNotFound) {
+		log.Printf("failed to read latest config version: %v", err)
+	}
+	cs.recordVersion(ChangeInfo{Author: "system", Reason: "startup"})
+}
+
+// recordVersion 把当前配置记录为新版本，未设置存储时忽略，调用方持有writeMu
+// 配置已经生效，记录失败只写日志
+func (cs *ConfigService) recordVersion(info ChangeInfo) int {
+	if cs.history == nil {
+		return 0
+	}
+
+	data, err := marshalConfig(cs.current())
+	if err != nil {
+		log.Printf("failed to marshal config for history: %v", err)
+		return 0
+	}
+	version := &pkgtypes.ConfigVersion{
+		Author: info.Author,
+		Reason: info.Reason,
+		Config: string(data),
+	}
+	if err := cs.history.Append(version); err != nil {
+		log.Printf("failed to record config version: %v", err)
+		return 0
+	}
+	return version.Version
+}
+
+// GetHistory 按版本号倒序列出配置版本
+func (cs *ConfigService) GetHistory() ([]pkgtypes.ConfigVersion, error) {
+	if cs.history == nil {
+		return nil, ErrConfigHistoryDisabled
+	}
+	return cs.history.List()
+}
+
+// DiffVersion 比较配置版本，against为0时与上一个版本比较
+func (cs *ConfigService) DiffVersion(version, against int) (*types.ConfigDiff, error) {
+	if cs.history == nil {
+		return nil, ErrConfigHistoryDisabled
+	}
+
+	to, err := cs.history.Get(version)
+	if err != nil {
+		return nil, err
+	}
+	if against == 0 {
+		against = version - 1
+	}
+
+	// 第一个版本与空配置比较
+	var fromYAML string
+	if against > 0 {
+		from, err := cs.history.Get(against)
+		if err != nil {
+			return nil, err
+		}
+		fromYAML = from.Config
+	}
+
+	changes, err := diffConfigYAML(fromYAML, to.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ConfigDiff{From: against, To: version, Changes: changes}, nil
+}
+
+// Rollback 回滚到指定版本的配置，回滚结果记录为新版本并返回新版本号
+func (cs *ConfigService) Rollback(version int, info ...ChangeInfo) (int, error) {
+	if cs.history == nil {
+		return 0, ErrConfigHistoryDisabled
+	}
+
+	target, err := cs.history.Get(version)
+	if err != nil {
+		return 0, err
+	}
+
+	_, newVersion, err := cs.update(changeInfo(info, fmt.Sprintf("rollback to version %d", version)), true, func(*config.Config) (*config.Config, error) {
+		var cfg config.Config
+		if err := yaml.Unmarshal([]byte(target.Config), &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config version %d: %w", version, err)
+		}
+		if err := cfg.ApplyEnv(os.LookupEnv); err != nil {
+			return nil, fmt.Errorf("config version %d is invalid: %w", version, err)
+		}
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("config version %d is invalid: %w", version, err)
+		}
+		cfg.SetDefaults()
+		return &cfg, nil
+	})
+	return newVersion, err
+}
+
+// diffConfigYAML 按字段比较两份YAML配置
+func diffConfigYAML(fromYAML, toYAML string) ([]types.ConfigChange, error) {
+	var from, to interface{}
+	if err := yaml.Unmarshal([]byte(fromYAML), &from); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	if err := yaml.Unmarshal([]byte(toYAML), &to); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	// 空配置按空映射比较，差异展开到各个字段
+	if from == nil {
+		from = map[string]interface{}{}
+	}
+	if to == nil {
+		to = map[string]interface{}{}
+	}
+
+	changes := []types.ConfigChange{}
+	diffNode("", from, to, &changes)
+	return changes, nil
+}
+
+// diffNode 递归比较YAML节点，映射按键比较，元素都有name字段的列表（如dexes）按名称比较，其他列表按下标比较
+func diffNode(path string, from, to interface{}, changes *[]types.ConfigChange) {
+	switch {
+	case from == nil && to == nil:
+		return
+	case from == nil:
+		*changes = append(*changes, types.ConfigChange{Path: path, Type: types.ConfigChangeAdded, New: to})
+		return
+	case to == nil:
+		*changes = append(*changes, types.ConfigChange{Path: path, Type: types.ConfigChangeRemoved, Old: from})
+		return
+	}
+
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make(map[string]bool)
+		for key := range fromMap {
+			keys[key] = true
+		}
+		for key := range toMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			diffNode(joinPath(path, key), fromMap[key], toMap[key], changes)
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		fromNamed, fromOK := namedItems(fromList)
+		toNamed, toOK := namedItems(toList)
+		if fromOK && toOK {
+			for _, name := range fromNamed.order {
+				diffNode(path+"["+name+"]", fromNamed.items[name], toNamed.items[name], changes)
+			}
+			for _, name := range toNamed.order {
+				if _, exists := fromNamed.items[name]; !exists {
+					diffNode(path+"["+name+"]", nil, toNamed.items[name], changes)
+				}
+			}
+			return
+		}
+		for i := 0; i < len(fromList) || i < len(toList); i++ {
+			var fromItem, toItem interface{}
+			if i < len(fromList) {
+				fromItem = fromList[i]
+			}
+			if i < len(toList) {
+				toItem = toList[i]
+			}
+			diffNode(path+"["+strconv.Itoa(i)+"]", fromItem, toItem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, types.ConfigChange{Path: path, Type: types.ConfigChangeChanged, Old: from, New: to})
+	}
+}
+
+// namedList 按name字段索引的列表元素
+type namedList struct {
+	order []string
+	items map[string]interface{}
+}
+
+// namedItems 列表元素都是带唯一name字段的映射时按名称索引
+func namedItems(list []interface{}) (namedList, bool) {
+	named := namedList{items: make(map[string]interface{})}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return named, false
+		}
+		name, ok := m["name"].(string)
+		if !ok || name == "" {
+			return named, false
+		}
+		if _, exists := named.items[name]; exists {
+			return named, false
+		}
+		named.order = append(named.order, name)
+		named.items[name] = item
+	}
+	return named, true
+}
+
+// joinPath 拼接字段路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
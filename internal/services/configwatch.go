@@ -0,0 +1,120 @@
+package services
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configWatchDebounce 配置文件事件的合并时间，编辑器保存时通常连续产生多个事件
+const configWatchDebounce = 200 * time.Millisecond
+
+// StartWatcher 监听配置文件，手动修改后自动重新加载并通知订阅者
+// 监听的是所在目录，编辑器通过重命名替换文件时也能收到事件
+func (cs *ConfigService) StartWatcher() error {
+	cs.stopMu.Lock()
+	defer cs.stopMu.Unlock()
+	if cs.stopCh != nil {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+	if err := watcher.Add(filepath.Dir(cs.configPath)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config file: %w", err)
+	}
+	if data, err := os.ReadFile(cs.configPath); err == nil {
+		cs.rememberFile(data)
+	}
+
+	cs.stopCh = make(chan struct{})
+	cs.wg.Add(1)
+	go cs.watchLoop(watcher, cs.stopCh)
+	return nil
+}
+
+// StopWatcher 停止监听配置文件
+func (cs *ConfigService) StopWatcher() {
+	cs.stopMu.Lock()
+	if cs.stopCh == nil {
+		cs.stopMu.Unlock()
+		return
+	}
+	close(cs.stopCh)
+	cs.stopCh = nil
+	cs.stopMu.Unlock()
+
+	cs.wg.Wait()
+}
+
+// watchLoop 合并短时间内的文件事件后重新加载配置
+func (cs *ConfigService) watchLoop(watcher *fsnotify.Watcher, stopCh chan struct{}) {
+	defer cs.wg.Done()
+	defer watcher.Close()
+
+	target := filepath.Clean(cs.configPath)
+	debounce := time.NewTimer(configWatchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != target || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+				continue
+			}
+			debounce.Reset(configWatchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("config watcher error: %v", err)
+		case <-debounce.C:
+			cs.reloadChangedFile()
+		}
+	}
+}
+
+// reloadChangedFile 配置文件内容与最近一次读写不同时重新加载，加载失败时保留当前配置
+func (cs *ConfigService) reloadChangedFile() {
+	data, err := os.ReadFile(cs.configPath)
+	if err != nil {
+		// 重命名替换的过程中文件可能暂时不存在，等待后续事件
+		return
+	}
+	if !cs.rememberFile(data) {
+		return
+	}
+
+	if err := cs.ReloadConfig(ChangeInfo{Author: "file", Reason: "config file changed"}); err != nil {
+		log.Printf("config file changed but reload failed, keeping current config: %v", err)
+		return
+	}
+	log.Printf("config file %s changed, configuration reloaded", cs.configPath)
+}
+
+// rememberFile 记录配置文件内容摘要，内容发生变化时返回true
+func (cs *ConfigService) rememberFile(data []byte) bool {
+	hash := sha256.Sum256(data)
+
+	cs.fileMu.Lock()
+	defer cs.fileMu.Unlock()
+	if hash == cs.fileHash {
+		return false
+	}
+	cs.fileHash = hash
+	return true
+}
@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// withTimeout 为一次操作设置超时，未配置超时时只继承调用方的取消
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// 系统程序指令解码器，按程序ID索引
+var builtinDecoders = map[solana.PublicKey]struct {
+	name   string
+	decode func(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error)
+}{
+	solana.ComputeBudget:                      {"compute_budget", decodeComputeBudgetInstruction},
+	solana.SystemProgramID:                    {"system", decodeSystemInstruction},
+	solana.TokenProgramID:                     {"token", decodeTokenInstruction},
+	solana.SPLAssociatedTokenAccountProgramID: {"associated_token_account", decodeAssociatedTokenInstruction},
+	solana.MemoProgramID:                      {"memo", decodeMemoInstruction},
+}
+
+// DecodeTransaction 解码交易，列出签名账户、付款人、区块哈希、计算预算及每条指令
+func (ts *TransactionService) DecodeTransaction(req *types.DecodeTransactionRequest) (*types.DecodeTransactionResponse, error) {
+	tx, err := decodeTransaction(req.Transaction)
+	if err != nil {
+		return &types.DecodeTransactionResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	message := &tx.Message
+	if len(message.AccountKeys) == 0 {
+		return &types.DecodeTransactionResponse{
+			Success: false,
+			Error:   "transaction has no accounts",
+		}, nil
+	}
+
+	resp := &types.DecodeTransactionResponse{
+		Success:         true,
+		Version:         "legacy",
+		FeePayer:        message.AccountKeys[0].String(),
+		RecentBlockhash: message.RecentBlockhash.String(),
+	}
+	if message.IsVersioned() {
+		resp.Version = "v0"
+	}
+
+	// 签名账户
+	numSigners := int(message.Header.NumRequiredSignatures)
+	numWritableSigners := numSigners - int(message.Header.NumReadonlySignedAccounts)
+	for i := 0; i < numSigners && i < len(message.AccountKeys); i++ {
+		signer := types.SignerInfo{
+			PublicKey: message.AccountKeys[i].String(),
+			Writable:  i < numWritableSigners,
+		}
+		if i < len(tx.Signatures) && !tx.Signatures[i].IsZero() {
+			signer.Signed = true
+			signer.Signature = tx.Signatures[i].String()
+		}
+		resp.Signers = append(resp.Signers, signer)
+	}
+
+	// 地址查找表
+	for _, lookup := range message.GetAddressTableLookups() {
+		entry := types.AddressTableLookup{AccountKey: lookup.AccountKey.String()}
+		for _, idx := range lookup.WritableIndexes {
+			entry.WritableIndexes = append(entry.WritableIndexes, int(idx))
+		}
+		for _, idx := range lookup.ReadonlyIndexes {
+			entry.ReadonlyIndexes = append(entry.ReadonlyIndexes, int(idx))
+		}
+		resp.AddressTableLookups = append(resp.AddressTableLookups, entry)
+	}
+
+	accountList := messageAccounts(message)
+
+	for i, inst := range message.Instructions {
+		decoded := ts.decodeInstruction(inst, accountList)
+		decoded.Index = i
+
+		// 汇总计算预算
+		if decoded.Program == "compute_budget" {
+			switch decoded.Name {
+			case "set_compute_unit_limit":
+				resp.ComputeBudget.UnitLimit = decoded.Fields["units"].(uint32)
+			case "set_compute_unit_price":
+				resp.ComputeBudget.UnitPrice = decoded.Fields["micro_lamports"].(uint64)
+			case "request_heap_frame":
+				resp.ComputeBudget.HeapFrameSize = decoded.Fields["bytes"].(uint32)
+			}
+		}
+
+		resp.Instructions = append(resp.Instructions, *decoded)
+	}
+
+	return resp, nil
+}
+
+// decodeInstruction 解码单条指令，DEX程序交给对应适配器，未知程序只列出账户和原始数据
+func (ts *TransactionService) decodeInstruction(inst solana.CompiledInstruction, accountList []types.DecodedAccount) *types.DecodedInstruction {
+	result := &types.DecodedInstruction{
+		Data: base64.StdEncoding.EncodeToString(inst.Data),
+	}
+
+	if int(inst.ProgramIDIndex) >= len(accountList) {
+		result.Error = fmt.Sprintf("program id index %d out of range", inst.ProgramIDIndex)
+		return result
+	}
+	result.ProgramID = accountList[inst.ProgramIDIndex].PublicKey
+
+	accounts := make([]types.DecodedAccount, 0, len(inst.Accounts))
+	for _, idx := range inst.Accounts {
+		if int(idx) >= len(accountList) {
+			result.Error = fmt.Sprintf("account index %d out of range", idx)
+			return result
+		}
+		accounts = append(accounts, accountList[idx])
+	}
+	result.Accounts = accounts
+
+	var decoded *types.DecodedInstruction
+	var err error
+	if name, adapter, ok := ts.registry().FindByProgramID(result.ProgramID); ok {
+		result.Program = name
+		decoded, err = adapter.DecodeInstruction(inst.Data, accounts)
+	} else if programID, parseErr := solana.PublicKeyFromBase58(result.ProgramID); parseErr == nil {
+		if builtin, ok := builtinDecoders[programID]; ok {
+			result.Program = builtin.name
+			decoded, err = builtin.decode(inst.Data, accounts)
+		}
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if decoded != nil {
+		result.Name = decoded.Name
+		result.Fields = decoded.Fields
+		if decoded.Accounts != nil {
+			result.Accounts = decoded.Accounts
+		}
+	}
+	return result
+}
+
+// messageAccounts 返回消息中按索引排列的全部账户，v0交易中来自查找表的账户无法在本地解析，以 查找表地址[索引] 表示
+func messageAccounts(message *solana.Message) []types.DecodedAccount {
+	numStatic := len(message.AccountKeys)
+	numSigners := int(message.Header.NumRequiredSignatures)
+	numWritableSigners := numSigners - int(message.Header.NumReadonlySignedAccounts)
+	numWritableUnsigned := numStatic - numSigners - int(message.Header.NumReadonlyUnsignedAccounts)
+
+	accounts := make([]types.DecodedAccount, 0, numStatic)
+	for i, key := range message.AccountKeys {
+		writable := i < numWritableSigners
+		if i >= numSigners {
+			writable = i-numSigners < numWritableUnsigned
+		}
+		accounts = append(accounts, types.DecodedAccount{
+			PublicKey:  key.String(),
+			IsSigner:   i < numSigners,
+			IsWritable: writable,
+		})
+	}
+
+	// 查找表账户顺序：所有查找表的可写账户，再是所有只读账户
+	lookups := message.GetAddressTableLookups()
+	for _, lookup := range lookups {
+		for _, idx := range lookup.WritableIndexes {
+			accounts = append(accounts, types.DecodedAccount{
+				PublicKey:  fmt.Sprintf("%s[%d]", lookup.AccountKey, idx),
+				IsWritable: true,
+			})
+		}
+	}
+	for _, lookup := range lookups {
+		for _, idx := range lookup.ReadonlyIndexes {
+			accounts = append(accounts, types.DecodedAccount{
+				PublicKey: fmt.Sprintf("%s[%d]", lookup.AccountKey, idx),
+			})
+		}
+	}
+
+	return accounts
+}
+
+// decodeComputeBudgetInstruction 解码计算预算指令
+func decodeComputeBudgetInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty instruction data")
+	}
+
+	switch data[0] {
+	case 1:
+		if len(data) != 5 {
+			return nil, fmt.Errorf("invalid request_heap_frame data length: %d", len(data))
+		}
+		return &types.DecodedInstruction{
+			Name:   "request_heap_frame",
+			Fields: map[string]interface{}{"bytes": binary.LittleEndian.Uint32(data[1:5])},
+		}, nil
+	case 2:
+		if len(data) != 5 {
+			return nil, fmt.Errorf("invalid set_compute_unit_limit data length: %d", len(data))
+		}
+		return &types.DecodedInstruction{
+			Name:   "set_compute_unit_limit",
+			Fields: map[string]interface{}{"units": binary.LittleEndian.Uint32(data[1:5])},
+		}, nil
+	case 3:
+		if len(data) != 9 {
+			return nil, fmt.Errorf("invalid set_compute_unit_price data length: %d", len(data))
+		}
+		return &types.DecodedInstruction{
+			Name:   "set_compute_unit_price",
+			Fields: map[string]interface{}{"micro_lamports": binary.LittleEndian.Uint64(data[1:9])},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown compute budget instruction: %d", data[0])
+	}
+}
+
+// decodeSystemInstruction 解码系统程序指令（仅转账）
+func decodeSystemInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("invalid system instruction data length: %d", len(data))
+	}
+
+	switch binary.LittleEndian.Uint32(data[0:4]) {
+	case 2:
+		if len(data) != 12 {
+			return nil, fmt.Errorf("invalid transfer data length: %d", len(data))
+		}
+		return &types.DecodedInstruction{
+			Name:     "transfer",
+			Fields:   map[string]interface{}{"lamports": binary.LittleEndian.Uint64(data[4:12])},
+			Accounts: adapters.NameAccounts(accounts, "from", "to"),
+		}, nil
+	default:
+		// 其他系统指令只列出账户
+		return nil, nil
+	}
+}
+
+// decodeTokenInstruction 解码SPL Token常用指令
+func decodeTokenInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty instruction data")
+	}
+
+	switch data[0] {
+	case 3:
+		if len(data) != 9 {
+			return nil, fmt.Errorf("invalid transfer data length: %d", len(data))
+		}
+		return &types.DecodedInstruction{
+			Name:     "transfer",
+			Fields:   map[string]interface{}{"amount": binary.LittleEndian.Uint64(data[1:9])},
+			Accounts: adapters.NameAccounts(accounts, "source", "destination", "owner"),
+		}, nil
+	case 9:
+		return &types.DecodedInstruction{
+			Name:     "close_account",
+			Accounts: adapters.NameAccounts(accounts, "account", "destination", "owner"),
+		}, nil
+	case 12:
+		if len(data) != 10 {
+			return nil, fmt.Errorf("invalid transfer_checked data length: %d", len(data))
+		}
+		return &types.DecodedInstruction{
+			Name: "transfer_checked",
+			Fields: map[string]interface{}{
+				"amount":   binary.LittleEndian.Uint64(data[1:9]),
+				"decimals": data[9],
+			},
+			Accounts: adapters.NameAccounts(accounts, "source", "mint", "destination", "owner"),
+		}, nil
+	case 17:
+		return &types.DecodedInstruction{
+			Name:     "sync_native",
+			Accounts: adapters.NameAccounts(accounts, "account"),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeAssociatedTokenInstruction 解码关联代币账户指令
+func decodeAssociatedTokenInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	name := "create"
+	if len(data) > 0 && data[0] == 1 {
+		name = "create_idempotent"
+	}
+	return &types.DecodedInstruction{
+		Name:     name,
+		Accounts: adapters.NameAccounts(accounts, "payer", "associated_account", "owner", "mint", "system_program", "token_program"),
+	}, nil
+}
+
+// decodeMemoInstruction 解码Memo指令
+func decodeMemoInstruction(data []byte, accounts []types.DecodedAccount) (*types.DecodedInstruction, error) {
+	return &types.DecodedInstruction{
+		Name:   "memo",
+		Fields: map[string]interface{}{"memo": string(data)},
+	}, nil
+}
@@ -1,23 +1,26 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 
+	"solana-dex-service/internal/adapters"
 	"solana-dex-service/internal/config"
 	"solana-dex-service/internal/types"
 )
 
 // DEXService DEX服务
 type DEXService struct {
-	config            *config.Config
+	config            atomic.Pointer[config.Config]
 	transactionService *TransactionService
 }
 
 // NewDEXService 创建DEX服务
 func NewDEXService(cfg *config.Config) *DEXService {
-	return &DEXService{
-		config: cfg,
-	}
+	ds := &DEXService{}
+	ds.config.Store(cfg)
+	return ds
 }
 
 // SetTransactionService 设置交易服务（避免循环依赖）
@@ -25,23 +28,27 @@ func (ds *DEXService) SetTransactionService(ts *TransactionService) {
 	ds.transactionService = ts
 }
 
+// ApplyConfig 应用变更后的配置
+func (ds *DEXService) ApplyConfig(cfg *config.Config) {
+	ds.config.Store(cfg)
+}
+
 // ListDEXes 获取所有DEX列表
 func (ds *DEXService) ListDEXes() ([]types.DEXInfo, error) {
 	var dexes []types.DEXInfo
 
-	for _, dexCfg := range ds.config.DEXes {
-		status := "offline"
-		if dexCfg.Enabled {
-			status = "online"
-		}
+	for _, dexCfg := range ds.config.Load().DEXes {
+		status := ds.dexStatus(&dexCfg)
 
 		dexes = append(dexes, types.DEXInfo{
 			Name:          dexCfg.Name,
+			Type:          dexCfg.AdapterType(),
 			ProgramID:     dexCfg.ProgramID,
 			RouterAddress: dexCfg.RouterAddress,
 			Endpoints:     dexCfg.Endpoints,
 			Enabled:       dexCfg.Enabled,
 			Status:        status,
+			Error:         ds.adapterError(&dexCfg),
 		})
 	}
 
@@ -50,28 +57,27 @@ func (ds *DEXService) ListDEXes() ([]types.DEXInfo, error) {
 
 // GetDEX 获取指定DEX信息
 func (ds *DEXService) GetDEX(name string) (*types.DEXInfo, error) {
-	dexCfg, err := ds.config.GetDEXConfig(name)
+	dexCfg, err := ds.config.Load().GetDEXConfig(name)
 	if err != nil {
 		return nil, err
 	}
 
-	status := "offline"
-	if dexCfg.Enabled {
-		status = "online"
-	}
+	status := ds.dexStatus(dexCfg)
 
 	return &types.DEXInfo{
 		Name:          dexCfg.Name,
+		Type:          dexCfg.AdapterType(),
 		ProgramID:     dexCfg.ProgramID,
 		RouterAddress: dexCfg.RouterAddress,
 		Endpoints:     dexCfg.Endpoints,
 		Enabled:       dexCfg.Enabled,
 		Status:        status,
+		Error:         ds.adapterError(dexCfg),
 	}, nil
 }
 
 // GetPools 获取指定DEX的流动性池信息
-func (ds *DEXService) GetPools(dexName string) ([]types.PoolInfo, error) {
+func (ds *DEXService) GetPools(ctx context.Context, dexName string) ([]types.PoolInfo, error) {
 	if ds.transactionService == nil {
 		return nil, fmt.Errorf("transaction service not initialized")
 	}
@@ -83,7 +89,9 @@ func (ds *DEXService) GetPools(dexName string) ([]types.PoolInfo, error) {
 	}
 
 	// 获取流动性池信息
-	pools, err := adapter.GetPools()
+	ctx, cancel := withTimeout(ctx, ds.config.Load().Server.QuoteTimeout)
+	defer cancel()
+	pools, err := adapter.GetPools(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pools: %w", err)
 	}
@@ -92,24 +100,32 @@ func (ds *DEXService) GetPools(dexName string) ([]types.PoolInfo, error) {
 }
 
 // GetQuote 获取交易报价
-func (ds *DEXService) GetQuote(dexName, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
+func (ds *DEXService) GetQuote(ctx context.Context, dexName, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, error) {
+	quote, _, err := ds.GetQuoteWithCacheStatus(ctx, dexName, inputMint, outputMint, amountIn)
+	return quote, err
+}
+
+// GetQuoteWithCacheStatus 获取交易报价及报价缓存结果
+func (ds *DEXService) GetQuoteWithCacheStatus(ctx context.Context, dexName, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, string, error) {
 	if ds.transactionService == nil {
-		return nil, fmt.Errorf("transaction service not initialized")
+		return nil, "", fmt.Errorf("transaction service not initialized")
 	}
 
 	// 获取DEX适配器
 	adapter, err := ds.transactionService.GetDEXAdapter(dexName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get DEX adapter: %w", err)
+		return nil, "", fmt.Errorf("failed to get DEX adapter: %w", err)
 	}
 
 	// 获取报价
-	quote, err := adapter.GetQuote(inputMint, outputMint, amountIn)
+	ctx, cancel := withTimeout(ctx, ds.config.Load().Server.QuoteTimeout)
+	defer cancel()
+	quote, cacheStatus, err := adapters.GetQuoteWithCacheStatus(ctx, adapter, inputMint, outputMint, amountIn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get quote: %w", err)
+		return nil, cacheStatus, fmt.Errorf("failed to get quote: %w", err)
 	}
 
-	return quote, nil
+	return quote, cacheStatus, nil
 }
 
 // ValidateDEXRequest 验证DEX请求
@@ -156,7 +172,78 @@ func (ds *DEXService) CheckDEXStatus(dexName string) (string, error) {
 		return "disabled", nil
 	}
 
-	// 这里可以添加更复杂的健康检查逻辑
-	// 比如检查DEX的API端点是否可访问
-	return "online", nil
+	return dexInfo.Status, nil
+}
+
+// GetBreakerStatus 获取DEX适配器的熔断器状态，未启用熔断时返回nil
+func (ds *DEXService) GetBreakerStatus(dexName string) *adapters.BreakerStatus {
+	if ds.transactionService == nil {
+		return nil
+	}
+	adapter, err := ds.transactionService.GetDEXAdapter(dexName)
+	if err != nil {
+		return nil
+	}
+	breaker := adapters.FindBreaker(adapter)
+	if breaker == nil {
+		return nil
+	}
+	status := breaker.Breaker()
+	return &status
+}
+
+// dexStatus 根据配置、熔断器和主动健康检查返回DEX状态，取其中较差的一个
+func (ds *DEXService) dexStatus(dexCfg *config.DEXConfig) string {
+	if !dexCfg.Enabled {
+		return adapters.StatusOffline
+	}
+	if ds.transactionService == nil {
+		return adapters.StatusOnline
+	}
+
+	adapter, err := ds.transactionService.GetDEXAdapter(dexCfg.Name)
+	if err != nil {
+		return adapters.StatusOffline
+	}
+
+	status := adapters.StatusOnline
+	if breaker := adapters.FindBreaker(adapter); breaker != nil {
+		status = breaker.Status()
+	}
+	if health := ds.transactionService.GetDEXHealth(dexCfg.Name); health != nil {
+		status = worseStatus(status, health.Status)
+	}
+	return status
+}
+
+// adapterError 返回DEX适配器创建失败的原因
+func (ds *DEXService) adapterError(dexCfg *config.DEXConfig) string {
+	if ds.transactionService == nil || !dexCfg.Enabled {
+		return ""
+	}
+	if err := ds.transactionService.GetAdapterError(dexCfg.Name); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// GetDEXHealth 获取DEX最近的主动健康检查结果，尚未检查时返回nil
+func (ds *DEXService) GetDEXHealth(dexName string) *types.DEXHealth {
+	if ds.transactionService == nil {
+		return nil
+	}
+	return ds.transactionService.GetDEXHealth(dexName)
+}
+
+// worseStatus 返回两个DEX状态中较差的一个
+func worseStatus(a, b string) string {
+	rank := map[string]int{
+		adapters.StatusOnline:   0,
+		adapters.StatusDegraded: 1,
+		adapters.StatusOffline:  2,
+	}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
 }
\ No newline at end of file
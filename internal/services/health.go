@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/rpcpool"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// ProbeTargetProgram 程序账户探测目标
+const ProbeTargetProgram = "program"
+
+// DEXHealthChecker DEX主动健康检查，后台定期检查程序账户是否为可执行账户、各端点是否可达，并保留最近的检查结果
+type DEXHealthChecker struct {
+	config  *config.Config
+	rpcPool *rpcpool.Pool
+	client  *http.Client
+
+	interval    time.Duration
+	timeout     time.Duration
+	historySize int
+	method      string
+	maxStatus   int
+
+	mu      sync.RWMutex // 保护history和config
+	history map[string][]types.HealthProbe
+
+	stopMu sync.Mutex
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewDEXHealthChecker 创建DEX健康检查器
+func NewDEXHealthChecker(cfg *config.Config, rpcPool *rpcpool.Pool) *DEXHealthChecker {
+	h := &DEXHealthChecker{
+		config:      cfg,
+		rpcPool:     rpcPool,
+		interval:    cfg.Health.Interval,
+		timeout:     cfg.Health.Timeout,
+		historySize: cfg.Health.History,
+		method:      cfg.Health.ProbeMethod,
+		maxStatus:   cfg.Health.MaxStatus,
+		history:     make(map[string][]types.HealthProbe),
+	}
+	h.client = &http.Client{
+		Timeout: h.timeout,
+		// 端点重定向也说明可达，不跟随跳转
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return h
+}
+
+// Start 启动后台健康检查，未配置检查周期时不启动
+func (h *DEXHealthChecker) Start() {
+	if h.interval <= 0 {
+		return
+	}
+
+	h.stopMu.Lock()
+	defer h.stopMu.Unlock()
+	if h.stopCh != nil {
+		return
+	}
+	h.stopCh = make(chan struct{})
+	stopCh := h.stopCh
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+			h.CheckAll(ctx)
+			cancel()
+
+			select {
+			case <-stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop 停止后台健康检查
+func (h *DEXHealthChecker) Stop() {
+	h.stopMu.Lock()
+	if h.stopCh == nil {
+		h.stopMu.Unlock()
+		return
+	}
+	close(h.stopCh)
+	h.stopCh = nil
+	h.stopMu.Unlock()
+
+	h.wg.Wait()
+}
+
+// CheckAll 并发检查所有启用的DEX并记录结果
+func (h *DEXHealthChecker) CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, dexCfg := range h.currentConfig().GetEnabledDEXes() {
+		wg.Add(1)
+		go func(dexCfg config.DEXConfig) {
+			defer wg.Done()
+			h.record(dexCfg.Name, h.Check(ctx, &dexCfg))
+		}(dexCfg)
+	}
+	wg.Wait()
+}
+
+// Check 检查一个DEX：程序账户不可用时为offline，有端点不可达时为degraded
+func (h *DEXHealthChecker) Check(ctx context.Context, dexCfg *config.DEXConfig) types.HealthProbe {
+	names := make([]string, 0, len(dexCfg.Endpoints))
+	for name := range dexCfg.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]types.ProbeResult, len(names)+1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0] = h.probeProgram(ctx, dexCfg.ProgramID)
+	}()
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i+1] = h.probeEndpoint(ctx, name, dexCfg.Endpoints[name])
+		}(i, name)
+	}
+	wg.Wait()
+
+	probe := types.HealthProbe{
+		CheckedAt: time.Now(),
+		Status:    adapters.StatusOnline,
+		Results:   results,
+	}
+	for _, result := range results {
+		switch {
+		case result.OK:
+		case result.Target == ProbeTargetProgram:
+			probe.Status = adapters.StatusOffline
+		case probe.Status == adapters.StatusOnline:
+			probe.Status = adapters.StatusDegraded
+		}
+	}
+	return probe
+}
+
+// Get 获取DEX最近的检查结果，尚未检查时返回nil
+func (h *DEXHealthChecker) Get(dexName string) *types.DEXHealth {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	history := h.history[dexName]
+	if len(history) == 0 {
+		return nil
+	}
+	return &types.DEXHealth{
+		DEX:    dexName,
+		Status: history[0].Status,
+		Probes: append([]types.HealthProbe(nil), history...),
+	}
+}
+
+// record 记录检查结果，最新的排在最前，超出保留数量的旧结果丢弃
+func (h *DEXHealthChecker) record(dexName string, probe types.HealthProbe) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	history := append([]types.HealthProbe{probe}, h.history[dexName]...)
+	if len(history) > h.historySize {
+		history = history[:h.historySize]
+	}
+	h.history[dexName] = history
+}
+
+// probeProgram 检查程序账户存在且可执行
+func (h *DEXHealthChecker) probeProgram(ctx context.Context, programID string) types.ProbeResult {
+	result := types.ProbeResult{Target: ProbeTargetProgram}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := h.checkProgram(ctx, programID)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// SetConfig 替换检查使用的配置，下一轮检查按新的DEX列表进行
+func (h *DEXHealthChecker) SetConfig(cfg *config.Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.config = cfg
+}
+
+// currentConfig 返回当前配置
+func (h *DEXHealthChecker) currentConfig() *config.Config {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.config
+}
+
+// checkProgram 查询程序账户，账户不存在或不可执行时返回错误
+func (h *DEXHealthChecker) checkProgram(ctx context.Context, programID string) error {
+	program, err := solana.PublicKeyFromBase58(programID)
+	if err != nil {
+		return fmt.Errorf("invalid program id: %w", err)
+	}
+
+	solanaCfg := h.currentConfig().Solana
+	var account *rpc.Account
+	err = h.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) error {
+		info, err := client.GetAccountInfoWithOpts(ctx, program, &rpc.GetAccountInfoOpts{
+			Encoding:   solana.EncodingBase64,
+			Commitment: rpc.CommitmentType(solanaCfg.Commitment),
+		})
+		// 账户不存在不是端点故障，不触发故障转移
+		if errors.Is(err, rpc.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		account = info.Value
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if account == nil {
+		return fmt.Errorf("program account %s not found on %s", programID, solanaCfg.Network)
+	}
+	if !account.Executable {
+		return fmt.Errorf("account %s is not executable on %s", programID, solanaCfg.Network)
+	}
+	return nil
+}
+
+// probeEndpoint 按配置的HTTP方法请求端点，状态码小于上限视为可达
+func (h *DEXHealthChecker) probeEndpoint(ctx context.Context, name, url string) types.ProbeResult {
+	result := types.ProbeResult{Target: name, URL: url}
+
+	req, err := http.NewRequestWithContext(ctx, h.method, url, nil)
+	if err != nil {
+		result.Error = fmt.Sprintf("invalid endpoint: %v", err)
+		return result
+	}
+	req.Header.Set("User-Agent", "solana-dex-service/1.0")
+
+	start := time.Now()
+	resp, err := h.client.Do(req)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode >= h.maxStatus {
+		result.Error = fmt.Sprintf("endpoint returned status %d", resp.StatusCode)
+		return result
+	}
+	result.OK = true
+	return result
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// encodeMultiHopSwap 将多跳路径的所有跳编码到同一笔交易中
+// 中间代币的关联账户按需创建，中间跳不限制最小输出，只在最后一跳校验最小输出
+func (ts *TransactionService) encodeMultiHopSwap(ctx context.Context, req *types.SwapRequest, route *types.VenueQuote, blockhash *BlockhashInfo) (*types.TransactionResponse, error) {
+	userWallet, err := solana.PublicKeyFromBase58(req.UserWallet)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid request: invalid user wallet address: %v", err),
+		}, nil
+	}
+	payerAddress := feePayerOrWallet(req.FeePayer, req.UserWallet)
+	payer, err := solana.PublicKeyFromBase58(payerAddress)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid request: invalid fee payer address: %v", err),
+		}, nil
+	}
+
+	// 最后一跳的最小输出，未指定时按报价净输出和滑点计算
+	minAmountOut := req.MinAmountOut
+	if minAmountOut == 0 {
+		minAmountOut = uint64(float64(route.NetAmountOut) * (1 - req.Slippage))
+	}
+
+	var instructions []solana.Instruction
+	for i, leg := range route.Legs {
+		final := i == len(route.Legs)-1
+
+		// 中间代币的关联账户不存在时创建
+		if !final {
+			instruction, err := createAssociatedTokenAccountIdempotent(payer, userWallet, leg.OutputMint)
+			if err != nil {
+				return &types.TransactionResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Failed to build intermediate token account for hop %d: %v", i+1, err),
+				}, nil
+			}
+			instructions = append(instructions, instruction)
+		}
+
+		adapter, err := ts.registry().Get(leg.DEX)
+		if err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("DEX adapter not found: %s", leg.DEX),
+			}, nil
+		}
+
+		legReq := &types.SwapRequest{
+			InputMint:  leg.InputMint,
+			OutputMint: leg.OutputMint,
+			AmountIn:   leg.AmountIn,
+			UserWallet: req.UserWallet,
+			FeePayer:   req.FeePayer,
+			Slippage:   1, // 中间跳的最小输出为0
+			DEXType:    leg.DEX,
+		}
+		if final {
+			legReq.Slippage = req.Slippage
+			legReq.MinAmountOut = minAmountOut
+		}
+
+		if err := adapter.ValidateRequest(legReq); err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid request for hop %d (%s): %v", i+1, leg.DEX, err),
+			}, nil
+		}
+		instructionData, err := adapter.BuildSwapInstruction(legReq)
+		if err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to build swap instruction for hop %d (%s): %v", i+1, leg.DEX, err),
+			}, nil
+		}
+		instructions = append(instructions, toSolanaInstruction(instructionData))
+	}
+
+	tx, blockhash, err := ts.buildTransaction(ctx, instructions, payerAddress, req.PriorityFee, blockhash)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to build transaction: %v", err),
+		}, nil
+	}
+
+	// 预检交易大小和账户数量，超出限制时不返回交易
+	report, err := preflightTransaction(tx)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+	if len(report.Violations) > 0 {
+		return &types.TransactionResponse{
+			Success:   false,
+			Error:     limitViolationError(report.Violations),
+			Preflight: report,
+		}, nil
+	}
+
+	estimatedFee, err := ts.estimateTransactionFee(ctx, tx)
+	if err != nil {
+		// 费用估算失败不影响交易构建，使用默认值
+		estimatedFee = 5000
+	}
+
+	txData, err := tx.MarshalBinary()
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+
+	return &types.TransactionResponse{
+		Success:              true,
+		Transaction:          base64.StdEncoding.EncodeToString(txData),
+		EstimatedFee:         estimatedFee,
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+		RequestID:            req.ID,
+		DEXType:              route.DEX,
+		QuotedAmountOut:      route.NetAmountOut,
+		Route:                route.Legs,
+		Preflight:            report,
+	}, nil
+}
+
+// createAssociatedTokenAccountIdempotent 构建幂等创建关联代币账户的指令，账户已存在时不报错
+func createAssociatedTokenAccountIdempotent(payer, owner solana.PublicKey, mintAddress string) (solana.Instruction, error) {
+	mint, err := solana.PublicKeyFromBase58(mintAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mint address: %w", err)
+	}
+	ata, _, err := solana.FindAssociatedTokenAddress(owner, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	return solana.NewInstruction(
+		solana.SPLAssociatedTokenAccountProgramID,
+		solana.AccountMetaSlice{
+			solana.Meta(payer).WRITE().SIGNER(),
+			solana.Meta(ata).WRITE(),
+			solana.Meta(owner),
+			solana.Meta(mint),
+			solana.Meta(solana.SystemProgramID),
+			solana.Meta(solana.TokenProgramID),
+		},
+		[]byte{1}, // CreateIdempotent
+	), nil
+}
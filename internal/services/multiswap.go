@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/google/uuid"
+)
+
+// EncodeMultiSwapTransaction 将多个交换编码到同一笔原子交易中
+// 各交换输出代币的关联账户按需创建并去重，整笔交易只设置一次计算预算
+func (ts *TransactionService) EncodeMultiSwapTransaction(ctx context.Context, req *types.MultiSwapRequest) (*types.MultiSwapResponse, error) {
+	requestID := uuid.New().String()
+
+	ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.EncodeTimeout)
+	defer cancel()
+
+	if len(req.Swaps) == 0 {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   "at least one swap is required",
+		}, nil
+	}
+	userWallet, err := solana.PublicKeyFromBase58(req.UserWallet)
+	if err != nil {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid request: invalid user wallet address: %v", err),
+		}, nil
+	}
+	payerAddress := feePayerOrWallet(req.FeePayer, req.UserWallet)
+	payer, err := solana.PublicKeyFromBase58(payerAddress)
+	if err != nil {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid request: invalid fee payer address: %v", err),
+		}, nil
+	}
+
+	var setup, swaps []solana.Instruction
+	var routes []types.Route
+	created := make(map[string]bool)
+	for i := range req.Swaps {
+		swap := req.Swaps[i]
+		if swap.UserWallet == "" {
+			swap.UserWallet = req.UserWallet
+		}
+		if swap.UserWallet != req.UserWallet {
+			return &types.MultiSwapResponse{
+				Success: false,
+				Error:   fmt.Sprintf("swap %d: user_wallet must match the request user_wallet", i+1),
+			}, nil
+		}
+		swap.FeePayer = req.FeePayer
+		swap.ID = requestID
+		swap.CreatedAt = time.Now()
+
+		route := types.Route{
+			InputMint:  swap.InputMint,
+			OutputMint: swap.OutputMint,
+			DEX:        swap.DEXType,
+			AmountIn:   swap.AmountIn,
+		}
+
+		// 自动选择时只比较直接报价，多跳和拆单不参与组合
+		if swap.DEXType == DEXTypeAuto {
+			quote, err := ts.RouteQuote(ctx, swap.InputMint, swap.OutputMint, swap.AmountIn, 1)
+			if err != nil {
+				return nil, err
+			}
+			if !quote.Success {
+				return &types.MultiSwapResponse{
+					Success: false,
+					Error:   fmt.Sprintf("swap %d: no route found: %s", i+1, quote.Error),
+				}, nil
+			}
+			swap.DEXType = quote.Best.DEX
+			route.DEX = quote.Best.DEX
+			route.AmountOut = quote.Best.NetAmountOut
+		}
+
+		adapter, err := ts.registry().Get(swap.DEXType)
+		if err != nil {
+			return &types.MultiSwapResponse{
+				Success: false,
+				Error:   fmt.Sprintf("swap %d: DEX adapter not found: %s", i+1, swap.DEXType),
+			}, nil
+		}
+		if err := adapter.ValidateRequest(&swap); err != nil {
+			return &types.MultiSwapResponse{
+				Success: false,
+				Error:   fmt.Sprintf("swap %d (%s): invalid request: %v", i+1, swap.DEXType, err),
+			}, nil
+		}
+		instructionData, err := adapter.BuildSwapInstruction(&swap)
+		if err != nil {
+			return &types.MultiSwapResponse{
+				Success: false,
+				Error:   fmt.Sprintf("swap %d (%s): failed to build swap instruction: %v", i+1, swap.DEXType, err),
+			}, nil
+		}
+
+		// 同一输出代币只创建一次关联账户
+		if !created[swap.OutputMint] {
+			instruction, err := createAssociatedTokenAccountIdempotent(payer, userWallet, swap.OutputMint)
+			if err != nil {
+				return &types.MultiSwapResponse{
+					Success: false,
+					Error:   fmt.Sprintf("swap %d (%s): failed to build output token account: %v", i+1, swap.DEXType, err),
+				}, nil
+			}
+			created[swap.OutputMint] = true
+			setup = append(setup, instruction)
+		}
+
+		swaps = append(swaps, toSolanaInstruction(instructionData))
+		routes = append(routes, route)
+	}
+
+	// 计算单元上限：未指定时按每个交换的默认上限估算
+	unitLimit := req.ComputeUnitLimit
+	if unitLimit == 0 {
+		limit := len(swaps) * defaultComputeUnitsPerInstruction
+		if limit > maxComputeUnits {
+			limit = maxComputeUnits
+		}
+		unitLimit = uint32(limit)
+	}
+	if unitLimit > maxComputeUnits {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   fmt.Sprintf("compute unit limit %d exceeds maximum of %d", unitLimit, maxComputeUnits),
+		}, nil
+	}
+
+	instructions := append([]solana.Instruction{createComputeUnitLimitInstruction(unitLimit)}, setup...)
+	instructions = append(instructions, swaps...)
+	tx, blockhash, err := ts.buildTransaction(ctx, instructions, payerAddress, req.PriorityFee, nil)
+	if err != nil {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to build transaction: %v", err),
+		}, nil
+	}
+
+	// 预检交易大小和账户数量
+	report, err := preflightTransaction(tx)
+	if err != nil {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+	if len(report.Violations) > 0 {
+		return &types.MultiSwapResponse{
+			Success:   false,
+			Error:     limitViolationError(report.Violations),
+			Preflight: report,
+		}, nil
+	}
+
+	estimatedFee, err := ts.estimateTransactionFee(ctx, tx)
+	if err != nil {
+		// 费用估算失败不影响交易构建，使用默认值
+		estimatedFee = 5000
+	}
+
+	txData, err := tx.MarshalBinary()
+	if err != nil {
+		return &types.MultiSwapResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+
+	return &types.MultiSwapResponse{
+		Success:              true,
+		Transaction:          base64.StdEncoding.EncodeToString(txData),
+		EstimatedFee:         estimatedFee,
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+		RequestID:            requestID,
+		Swaps:                routes,
+		ComputeUnitLimit:     unitLimit,
+		Preflight:            report,
+	}, nil
+}
+
+// createComputeUnitLimitInstruction 创建设置计算单元上限的指令
+func createComputeUnitLimitInstruction(units uint32) solana.Instruction {
+	data := make([]byte, 5)
+	data[0] = 2 // SetComputeUnitLimit指令ID
+	binary.LittleEndian.PutUint32(data[1:5], units)
+
+	return solana.NewInstruction(
+		solana.ComputeBudget,
+		solana.AccountMetaSlice{},
+		data,
+	)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"solana-dex-service/internal/types"
+)
+
+// poolEdge 池子图中的一条边，表示可以在某个DEX上从一个代币换到另一个代币
+type poolEdge struct {
+	DEX       string
+	PoolID    string
+	FromMint  string
+	ToMint    string
+	FeeRate   float64
+	Liquidity uint64
+}
+
+// poolGraph 由各适配器已知池子构建的代币图，按代币地址索引出边
+type poolGraph struct {
+	mu         sync.Mutex
+	edges      map[string][]poolEdge
+	builtAt    time.Time
+	refreshing chan struct{} // 正在进行的刷新完成时关闭
+}
+
+// newPoolGraph 创建空的池子图
+func newPoolGraph() *poolGraph {
+	return &poolGraph{}
+}
+
+// invalidate 使池子图过期，下次使用时按当前的适配器重建
+func (g *poolGraph) invalidate() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.builtAt = time.Time{}
+}
+
+// loadPoolGraph 返回池子图，缓存过期时触发后台刷新，最多等待到ctx取消或超时
+func (ts *TransactionService) loadPoolGraph(ctx context.Context) map[string][]poolEdge {
+	g := ts.poolGraph
+	ttl := ts.currentConfig().Routing.PoolGraphTTL
+
+	g.mu.Lock()
+	if g.edges != nil && time.Since(g.builtAt) < ttl {
+		edges := g.edges
+		g.mu.Unlock()
+		return edges
+	}
+	if g.refreshing == nil {
+		g.refreshing = make(chan struct{})
+		go ts.refreshPoolGraph(g.refreshing)
+	}
+	done := g.refreshing
+	g.mu.Unlock()
+
+	// 刷新未在截止时间前完成时使用旧的池子图（可能为空）
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.edges
+}
+
+// refreshPoolGraph 从所有已启用的适配器获取池子并重建代币图
+// 刷新由多个请求共享，不随单个请求取消，使用独立的查询超时
+func (ts *TransactionService) refreshPoolGraph(done chan struct{}) {
+	ctx, cancel := withTimeout(context.Background(), ts.currentConfig().Server.QuoteTimeout)
+	defer cancel()
+
+	type poolResult struct {
+		dex   string
+		pools []types.PoolInfo
+	}
+
+	var wg sync.WaitGroup
+	all := ts.registry().GetAll()
+	results := make(chan poolResult, len(all))
+	for name, adapter := range all {
+		if !adapter.GetConfig().Enabled {
+			continue
+		}
+		wg.Add(1)
+		go func(name string, adapter types.DEXAdapter) {
+			defer wg.Done()
+			pools, err := adapter.GetPools(ctx)
+			if err != nil {
+				log.Printf("failed to load pools from %s: %v", name, err)
+				return
+			}
+			results <- poolResult{dex: name, pools: pools}
+		}(name, adapter)
+	}
+	wg.Wait()
+	close(results)
+
+	// 同一DEX上同一代币对只保留流动性最高的池子
+	best := make(map[[3]string]poolEdge)
+	for result := range results {
+		for _, pool := range result.pools {
+			if pool.TokenAMint == "" || pool.TokenBMint == "" || pool.TokenAMint == pool.TokenBMint {
+				continue
+			}
+			for _, dir := range [][2]string{{pool.TokenAMint, pool.TokenBMint}, {pool.TokenBMint, pool.TokenAMint}} {
+				key := [3]string{result.dex, dir[0], dir[1]}
+				if existing, ok := best[key]; ok && existing.Liquidity >= pool.Liquidity {
+					continue
+				}
+				best[key] = poolEdge{
+					DEX:       result.dex,
+					PoolID:    pool.Address,
+					FromMint:  dir[0],
+					ToMint:    dir[1],
+					FeeRate:   pool.FeeRate,
+					Liquidity: pool.Liquidity,
+				}
+			}
+		}
+	}
+
+	edges := make(map[string][]poolEdge)
+	for _, edge := range best {
+		edges[edge.FromMint] = append(edges[edge.FromMint], edge)
+	}
+	for mint := range edges {
+		sort.Slice(edges[mint], func(i, j int) bool {
+			return edges[mint][i].Liquidity > edges[mint][j].Liquidity
+		})
+	}
+
+	g := ts.poolGraph
+	g.mu.Lock()
+	g.edges = edges
+	g.builtAt = time.Now()
+	g.refreshing = nil
+	g.mu.Unlock()
+	close(done)
+}
+
+// findPaths 查找从输入代币到输出代币的2至maxHops跳路径，中间代币限制在允许的列表中
+// 结果按跳数升序、路径最小流动性降序排列，最多返回maxPaths条
+func findPaths(edges map[string][]poolEdge, inputMint, outputMint string, maxHops, maxPaths int, intermediates []string) [][]poolEdge {
+	allowed := make(map[string]bool, len(intermediates))
+	for _, mint := range intermediates {
+		allowed[mint] = true
+	}
+
+	var paths [][]poolEdge
+	visited := map[string]bool{inputMint: true}
+	var path []poolEdge
+
+	var walk func(mint string)
+	walk = func(mint string) {
+		for _, edge := range edges[mint] {
+			if edge.ToMint == outputMint {
+				if len(path) > 0 {
+					paths = append(paths, append(append([]poolEdge{}, path...), edge))
+				}
+				continue
+			}
+			if len(path)+2 > maxHops || visited[edge.ToMint] {
+				continue
+			}
+			if len(allowed) > 0 && !allowed[edge.ToMint] {
+				continue
+			}
+			visited[edge.ToMint] = true
+			path = append(path, edge)
+			walk(edge.ToMint)
+			path = path[:len(path)-1]
+			delete(visited, edge.ToMint)
+		}
+	}
+	if maxHops >= 2 {
+		walk(inputMint)
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		if len(paths[i]) != len(paths[j]) {
+			return len(paths[i]) < len(paths[j])
+		}
+		return minLiquidity(paths[i]) > minLiquidity(paths[j])
+	})
+	if maxPaths > 0 && len(paths) > maxPaths {
+		paths = paths[:maxPaths]
+	}
+	return paths
+}
+
+// minLiquidity 路径上流动性最低的池子
+func minLiquidity(path []poolEdge) uint64 {
+	var min uint64
+	for i, edge := range path {
+		if i == 0 || edge.Liquidity < min {
+			min = edge.Liquidity
+		}
+	}
+	return min
+}
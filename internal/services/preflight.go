@@ -0,0 +1,127 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	// maxTransactionSize 序列化后的交易大小上限（IPv6最小MTU减去报文头）
+	maxTransactionSize = 1232
+	// maxTransactionAccounts 单笔交易可以锁定的账户数量上限（含查找表加载的账户）
+	maxTransactionAccounts = 64
+
+	// LimitTransactionSize 交易大小限制
+	LimitTransactionSize = "transaction_size"
+	// LimitAccountLocks 账户锁数量限制
+	LimitAccountLocks = "account_locks"
+)
+
+// preflightTransaction 计算交易签名后的序列化大小、唯一账户数量、写锁数量和签名数量，并检查Solana交易限制
+// 超过大小限制的legacy交易估算改用v0交易和地址查找表后的大小，能放下时给出建议
+func preflightTransaction(tx *solana.Transaction) (*types.PreflightReport, error) {
+	size, err := transactionSize(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
+	}
+
+	report := &types.PreflightReport{
+		Size:       size,
+		Signatures: int(tx.Message.Header.NumRequiredSignatures),
+	}
+	for _, account := range messageAccounts(&tx.Message) {
+		report.Accounts++
+		if account.IsWritable {
+			report.WritableAccounts++
+		}
+	}
+
+	if report.Accounts > maxTransactionAccounts {
+		report.Violations = append(report.Violations, types.LimitViolation{
+			Limit:  LimitAccountLocks,
+			Actual: report.Accounts,
+			Max:    maxTransactionAccounts,
+			Excess: report.Accounts - maxTransactionAccounts,
+			// 查找表只能缩小交易，加载的账户同样占用账户锁
+			Suggestion: "split the instructions across multiple transactions; address lookup tables do not reduce account locks",
+		})
+	}
+
+	if size > maxTransactionSize {
+		violation := types.LimitViolation{
+			Limit:  LimitTransactionSize,
+			Actual: size,
+			Max:    maxTransactionSize,
+			Excess: size - maxTransactionSize,
+		}
+		if !tx.Message.IsVersioned() {
+			report.EstimatedV0Size = estimateV0Size(tx, size)
+		}
+		if report.EstimatedV0Size > 0 && report.EstimatedV0Size <= maxTransactionSize && report.Accounts <= maxTransactionAccounts {
+			violation.Suggestion = fmt.Sprintf("use a v0 transaction with an address lookup table for the non-signer accounts (estimated %d bytes)", report.EstimatedV0Size)
+		} else {
+			violation.Suggestion = "split the instructions across multiple transactions"
+		}
+		report.Violations = append(report.Violations, violation)
+	}
+
+	return report, nil
+}
+
+// estimateV0Size 估算将非签名、非程序账户移入一个地址查找表后的v0交易大小
+// 每个移入查找表的账户由32字节公钥变为1字节索引，另加版本前缀和查找表本身的开销
+func estimateV0Size(tx *solana.Transaction, legacySize int) int {
+	programs := make(map[uint16]bool)
+	for _, inst := range tx.Message.Instructions {
+		programs[inst.ProgramIDIndex] = true
+	}
+
+	numSigners := int(tx.Message.Header.NumRequiredSignatures)
+	movable := 0
+	for i := numSigners; i < len(tx.Message.AccountKeys); i++ {
+		if !programs[uint16(i)] {
+			movable++
+		}
+	}
+	if movable == 0 {
+		return 0
+	}
+
+	// 版本前缀1字节，查找表数量1字节，查找表地址32字节，可写和只读索引数量各1字节
+	const lookupOverhead = 1 + 1 + 32 + 1 + 1
+	return legacySize - movable*(solana.PublicKeyLength-1) + lookupOverhead
+}
+
+// limitViolationError 汇总超出的限制作为错误信息
+func limitViolationError(violations []types.LimitViolation) string {
+	parts := make([]string, 0, len(violations))
+	for _, v := range violations {
+		switch v.Limit {
+		case LimitTransactionSize:
+			parts = append(parts, fmt.Sprintf("transaction size %d bytes exceeds limit of %d bytes by %d", v.Actual, v.Max, v.Excess))
+		case LimitAccountLocks:
+			parts = append(parts, fmt.Sprintf("transaction references %d accounts, exceeds limit of %d by %d", v.Actual, v.Max, v.Excess))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %d exceeds limit of %d by %d", v.Limit, v.Actual, v.Max, v.Excess))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// transactionSize 签名后交易的序列化大小
+func transactionSize(tx *solana.Transaction) (int, error) {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	signatures := int(tx.Message.Header.NumRequiredSignatures)
+	prefix := 1
+	if signatures >= 0x80 {
+		prefix = 2
+	}
+	return prefix + signatures*64 + len(message), nil
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+const (
+	// DEXTypeAuto 自动选择报价最优的DEX
+	DEXTypeAuto = "auto"
+)
+
+// RouteQuote 并发向所有已启用的适配器询价，并在池子图上搜索多跳路径，按扣除手续费后的净输出排序，返回最优报价及其他报价
+// maxHops为0时使用配置的最大跳数
+func (ts *TransactionService) RouteQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64, maxHops int) (*types.RouteQuoteResponse, error) {
+	cfg := ts.currentConfig()
+	resp := &types.RouteQuoteResponse{
+		InputMint:    inputMint,
+		OutputMint:   outputMint,
+		AmountIn:     amountIn,
+		Alternatives: []types.VenueQuote{},
+	}
+
+	if maxHops == 0 {
+		maxHops = cfg.Routing.MaxHops
+	}
+	if maxHops < 1 || maxHops > 3 {
+		resp.Error = "max hops must be between 1 and 3"
+		return resp, nil
+	}
+
+	if err := validateQuoteParams(inputMint, outputMint, amountIn); err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, cfg.Server.QuoteTimeout)
+	defer cancel()
+
+	quotes, failed := ts.quoteRoutes(ctx, inputMint, outputMint, amountIn, maxHops)
+	resp.Failed = failed
+	if len(quotes) == 0 {
+		resp.Error = "no DEX returned a quote"
+		if len(failed) > 0 {
+			reasons := make([]string, 0, len(failed))
+			for _, f := range failed {
+				reasons = append(reasons, fmt.Sprintf("%s: %s", f.DEX, f.Error))
+			}
+			resp.Error = fmt.Sprintf("%s (%s)", resp.Error, strings.Join(reasons, "; "))
+		}
+		return resp, nil
+	}
+
+	resp.Success = true
+	resp.Best = &quotes[0]
+	resp.Alternatives = append(resp.Alternatives, quotes[1:]...)
+	return resp, nil
+}
+
+// quoteRoutes 在截止时间内并发询价：每个DEX的直接报价，以及池子图上的多跳路径（每条路径按顺序逐跳询价）
+// 返回按净输出降序排列的报价和失败的路径，返回时取消仍未完成的询价
+func (ts *TransactionService) quoteRoutes(ctx context.Context, inputMint, outputMint string, amountIn uint64, maxHops int) ([]types.VenueQuote, []types.VenueQuote) {
+	cfg := ts.currentConfig()
+	timeout := cfg.Routing.QuoteTimeout
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type routeResult struct {
+		id    int
+		quote types.VenueQuote
+	}
+	results := make(chan routeResult)
+	done := make(chan struct{})
+	defer close(done)
+
+	// 超时后返回的询价协程通过done退出，不会阻塞
+	pending := make(map[int]types.VenueQuote)
+	launch := func(legs []poolEdge) {
+		id := len(pending)
+		pending[id] = types.VenueQuote{DEX: routeName(legs), Legs: plannedLegs(legs)}
+		go func() {
+			quote := ts.quotePath(ctx, legs, amountIn)
+			select {
+			case results <- routeResult{id: id, quote: quote}:
+			case <-done:
+			}
+		}()
+	}
+
+	// 直接报价
+	for name, adapter := range ts.registry().GetAll() {
+		if !adapter.GetConfig().Enabled {
+			continue
+		}
+		launch([]poolEdge{{DEX: name, FromMint: inputMint, ToMint: outputMint}})
+	}
+
+	// 多跳路径
+	if maxHops > 1 {
+		edges := ts.loadPoolGraph(ctx)
+		paths := findPaths(edges, inputMint, outputMint, maxHops, cfg.Routing.MaxPaths, cfg.Routing.IntermediateMints)
+		for _, path := range paths {
+			launch(path)
+		}
+	}
+
+	var quotes, failed []types.VenueQuote
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			delete(pending, result.id)
+			if result.quote.Error != "" {
+				failed = append(failed, result.quote)
+			} else {
+				quotes = append(quotes, result.quote)
+			}
+		case <-ctx.Done():
+			elapsed := time.Since(start)
+			reason := fmt.Sprintf("quote timed out after %s", elapsed.Truncate(time.Millisecond))
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				reason = fmt.Sprintf("quote canceled: %v", ctx.Err())
+			}
+			for _, quote := range pending {
+				quote.LatencyMs = elapsed.Milliseconds()
+				quote.Error = reason
+				failed = append(failed, quote)
+			}
+			pending = nil
+		}
+	}
+
+	rankQuotes(quotes)
+	sort.Slice(failed, func(i, j int) bool {
+		if len(failed[i].Legs) != len(failed[j].Legs) {
+			return len(failed[i].Legs) < len(failed[j].Legs)
+		}
+		return failed[i].DEX < failed[j].DEX
+	})
+	return quotes, failed
+}
+
+// quotePath 按顺序逐跳询价，每一跳的输入为上一跳扣除手续费后的输出
+func (ts *TransactionService) quotePath(ctx context.Context, legs []poolEdge, amountIn uint64) types.VenueQuote {
+	start := time.Now()
+	result := types.VenueQuote{DEX: routeName(legs)}
+
+	amount := amountIn
+	keepImpact := 1.0
+	var last *types.QuoteResponse
+	var cache []string
+	for _, leg := range legs {
+		adapter, err := ts.registry().Get(leg.DEX)
+		if err != nil {
+			result.Error = err.Error()
+			break
+		}
+		quote, status, err := adapters.GetQuoteWithCacheStatus(ctx, adapter, leg.FromMint, leg.ToMint, amount)
+		if status != "" {
+			cache = append(cache, status)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			break
+		}
+		if quote == nil || quote.AmountOut == 0 {
+			result.Error = "empty quote"
+			break
+		}
+
+		out := netAmountOut(quote)
+		result.Legs = append(result.Legs, types.Route{
+			InputMint:  leg.FromMint,
+			OutputMint: leg.ToMint,
+			PoolID:     leg.PoolID,
+			FeeRate:    leg.FeeRate,
+			DEX:        leg.DEX,
+			AmountIn:   amount,
+			AmountOut:  out,
+		})
+		keepImpact *= 1 - quote.PriceImpact
+		amount = out
+		last = quote
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.Cache = strings.Join(cache, ",")
+
+	if result.Error != "" {
+		if len(result.Legs) < len(legs) {
+			result.Legs = plannedLegs(legs)
+		}
+		return result
+	}
+
+	result.NetAmountOut = amount
+	if len(legs) == 1 {
+		result.Quote = last
+		return result
+	}
+
+	// 多跳报价汇总：最终输出和最小输出取最后一跳，价格影响按各跳累乘
+	result.Quote = &types.QuoteResponse{
+		InputMint:    legs[0].FromMint,
+		OutputMint:   legs[len(legs)-1].ToMint,
+		AmountIn:     amountIn,
+		AmountOut:    last.AmountOut,
+		MinAmountOut: last.MinAmountOut,
+		PriceImpact:  1 - keepImpact,
+		Fee:          last.Fee,
+		Route:        result.Legs,
+	}
+	return result
+}
+
+// routeName 路径经过的DEX名称，多跳时以逗号连接
+func routeName(legs []poolEdge) string {
+	names := make([]string, len(legs))
+	for i, leg := range legs {
+		names[i] = leg.DEX
+	}
+	return strings.Join(names, ",")
+}
+
+// plannedLegs 将路径转换为尚未询价的路由信息
+func plannedLegs(legs []poolEdge) []types.Route {
+	routes := make([]types.Route, len(legs))
+	for i, leg := range legs {
+		routes[i] = types.Route{
+			InputMint:  leg.FromMint,
+			OutputMint: leg.ToMint,
+			PoolID:     leg.PoolID,
+			FeeRate:    leg.FeeRate,
+			DEX:        leg.DEX,
+		}
+	}
+	return routes
+}
+
+// netAmountOut 扣除手续费后的净输出，适配器返回的手续费以输出代币计价
+func netAmountOut(quote *types.QuoteResponse) uint64 {
+	if quote.Fee >= quote.AmountOut {
+		return 0
+	}
+	return quote.AmountOut - quote.Fee
+}
+
+// rankQuotes 按净输出降序排列，净输出相同时跳数（或拆单份数）少、价格影响小的优先
+func rankQuotes(quotes []types.VenueQuote) {
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if quotes[i].NetAmountOut != quotes[j].NetAmountOut {
+			return quotes[i].NetAmountOut > quotes[j].NetAmountOut
+		}
+		if pi, pj := len(quotes[i].Legs)+len(quotes[i].Split), len(quotes[j].Legs)+len(quotes[j].Split); pi != pj {
+			return pi < pj
+		}
+		if quotes[i].Quote.PriceImpact != quotes[j].Quote.PriceImpact {
+			return quotes[i].Quote.PriceImpact < quotes[j].Quote.PriceImpact
+		}
+		return quotes[i].DEX < quotes[j].DEX
+	})
+}
+
+// validateQuoteParams 验证询价参数
+func validateQuoteParams(inputMint, outputMint string, amountIn uint64) error {
+	if _, err := solana.PublicKeyFromBase58(inputMint); err != nil {
+		return fmt.Errorf("invalid input mint address: %v", err)
+	}
+	if _, err := solana.PublicKeyFromBase58(outputMint); err != nil {
+		return fmt.Errorf("invalid output mint address: %v", err)
+	}
+	if inputMint == outputMint {
+		return fmt.Errorf("input and output mint must be different")
+	}
+	if amountIn == 0 {
+		return fmt.Errorf("amount must be positive")
+	}
+	return nil
+}
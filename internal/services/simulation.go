@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"sort"
+
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+const (
+	// lamportsPerSignature 每个签名的基础费用
+	lamportsPerSignature = 5000
+	// defaultComputeUnitsPerInstruction 未设置计算单元上限时每条指令的默认上限
+	defaultComputeUnitsPerInstruction = 200000
+	// maxComputeUnits 交易计算单元上限
+	maxComputeUnits = 1400000
+	// tokenAccountSize SPL Token账户数据长度
+	tokenAccountSize = 165
+)
+
+// token2022ProgramID Token-2022程序ID，账户前165字节与SPL Token布局相同
+var token2022ProgramID = solana.MustPublicKeyFromBase58("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+
+// accountSnapshot 模拟前后的账户状态
+type accountSnapshot struct {
+	exists   bool
+	lamports uint64
+	isToken  bool
+	mint     solana.PublicKey
+	owner    solana.PublicKey
+	amount   uint64
+}
+
+// simulateTransaction 模拟交易执行，并根据模拟前后的账户状态计算余额变化
+// 未签名的交易不校验签名，并由节点替换为最新区块哈希
+func (ts *TransactionService) simulateTransaction(ctx context.Context, tx *solana.Transaction, req *types.TransactionTestRequest, signed bool) (*types.TransactionTestResponse, error) {
+	// 节点要求签名数量与消息头一致，未签名的位置填充空签名
+	for len(tx.Signatures) < int(tx.Message.Header.NumRequiredSignatures) {
+		tx.Signatures = append(tx.Signatures, solana.Signature{})
+	}
+
+	user, err := simulationUser(tx, req.UserWallet)
+	if err != nil {
+		return &types.TransactionTestResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	watched := watchedAccounts(tx)
+	commitment := rpc.CommitmentType(ts.currentConfig().Solana.Commitment)
+
+	// 获取模拟前的账户状态，失败时仅返回模拟结果
+	var pre []*rpc.Account
+	err = ts.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) error {
+		result, err := client.GetMultipleAccountsWithOpts(ctx, watched, &rpc.GetMultipleAccountsOpts{
+			Encoding:   solana.EncodingBase64,
+			Commitment: commitment,
+		})
+		if err != nil {
+			return err
+		}
+		pre = result.Value
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to fetch pre-simulation accounts: %v", err)
+		pre = nil
+	}
+
+	// 使用RPC端点池模拟交易，同时返回模拟后的账户状态
+	opts := &rpc.SimulateTransactionOpts{
+		SigVerify:              false,
+		ReplaceRecentBlockhash: !signed,
+		Commitment:             commitment,
+		Accounts: &rpc.SimulateTransactionAccountsOpts{
+			Encoding:  solana.EncodingBase64,
+			Addresses: watched,
+		},
+	}
+	var simResult *rpc.SimulateTransactionResponse
+	err = ts.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+		simResult, err = client.SimulateTransactionWithOpts(ctx, tx, opts)
+		return err
+	})
+	if err != nil {
+		return &types.TransactionTestResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Simulation failed: %v", err),
+		}, nil
+	}
+
+	if simResult.Value.Err != nil {
+		return &types.TransactionTestResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Simulation error: %v", simResult.Value.Err),
+			Logs:    simResult.Value.Logs,
+		}, nil
+	}
+
+	resp := &types.TransactionTestResponse{
+		Success: true,
+		Logs:    simResult.Value.Logs,
+	}
+	if simResult.Value.UnitsConsumed != nil {
+		resp.GasUsed = *simResult.Value.UnitsConsumed
+	}
+
+	if pre != nil && len(pre) == len(watched) && len(simResult.Value.Accounts) == len(watched) {
+		resp.Simulation = buildSimulationResult(tx, req, user, watched, pre, simResult.Value.Accounts)
+		resp.Simulation.Signed = signed
+	}
+
+	return resp, nil
+}
+
+// simulationUser 返回统计余额变化的钱包，默认为交易的用户钱包
+func simulationUser(tx *solana.Transaction, userWallet string) (solana.PublicKey, error) {
+	if userWallet != "" {
+		user, err := solana.PublicKeyFromBase58(userWallet)
+		if err != nil {
+			return solana.PublicKey{}, fmt.Errorf("invalid user wallet: %v", err)
+		}
+		return user, nil
+	}
+	if len(tx.Message.AccountKeys) == 0 {
+		return solana.PublicKey{}, fmt.Errorf("transaction has no accounts")
+	}
+	return transactionWallet(tx), nil
+}
+
+// transactionWallet 交易的用户钱包：第一个不是手续费付款人的签名者，只有付款人签名时为付款人
+func transactionWallet(tx *solana.Transaction) solana.PublicKey {
+	keys := tx.Message.AccountKeys
+	if int(tx.Message.Header.NumRequiredSignatures) > 1 && len(keys) > 1 {
+		return keys[1]
+	}
+	return keys[0]
+}
+
+// watchedAccounts 返回交易中可写的静态账户，余额只可能在这些账户上变化
+func watchedAccounts(tx *solana.Transaction) []solana.PublicKey {
+	var accounts []solana.PublicKey
+	for i, account := range messageAccounts(&tx.Message)[:len(tx.Message.AccountKeys)] {
+		if account.IsWritable {
+			accounts = append(accounts, tx.Message.AccountKeys[i])
+		}
+	}
+	return accounts
+}
+
+// newAccountSnapshot 解析账户状态，SPL Token账户额外解析代币、持有人和余额
+func newAccountSnapshot(account *rpc.Account) accountSnapshot {
+	if account == nil || (account.Lamports == 0 && account.Owner.IsZero()) {
+		return accountSnapshot{}
+	}
+
+	snapshot := accountSnapshot{
+		exists:   account.Lamports > 0,
+		lamports: account.Lamports,
+	}
+	if account.Owner.Equals(solana.TokenProgramID) || account.Owner.Equals(token2022ProgramID) {
+		data := account.Data.GetBinary()
+		if len(data) >= tokenAccountSize {
+			snapshot.isToken = true
+			snapshot.mint = solana.PublicKeyFromBytes(data[0:32])
+			snapshot.owner = solana.PublicKeyFromBytes(data[32:64])
+			snapshot.amount = binary.LittleEndian.Uint64(data[64:72])
+		}
+	}
+	return snapshot
+}
+
+// buildSimulationResult 对比模拟前后的账户状态，计算钱包的代币和SOL变化
+func buildSimulationResult(tx *solana.Transaction, req *types.TransactionTestRequest, user solana.PublicKey, watched []solana.PublicKey, pre, post []*rpc.Account) *types.SimulationResult {
+	result := &types.SimulationResult{
+		UserWallet:      user.String(),
+		Fee:             estimateSimulationFee(tx),
+		QuotedAmountOut: req.QuotedAmountOut,
+	}
+
+	// 按代币汇总钱包持有的代币账户变化
+	mintChanges := make(map[string]int64)
+	for i, account := range watched {
+		before := newAccountSnapshot(pre[i])
+		after := newAccountSnapshot(post[i])
+
+		// 新建账户支付押金，关闭账户退还租金（原生SOL代币账户扣除包装的SOL）
+		if !before.exists && after.exists && !account.Equals(user) {
+			result.RentChange += int64(after.lamports - nativeAmount(after))
+		} else if before.exists && !after.exists {
+			result.RentChange -= int64(before.lamports - nativeAmount(before))
+		}
+
+		if account.Equals(user) {
+			change := int64(after.lamports) - int64(before.lamports)
+			result.SOLChange = change
+			if change != 0 {
+				result.BalanceChanges = append(result.BalanceChanges, types.BalanceChange{
+					Account:     account.String(),
+					Owner:       user.String(),
+					PreBalance:  before.lamports,
+					PostBalance: after.lamports,
+					Change:      change,
+				})
+			}
+			continue
+		}
+
+		tokenAccount := after
+		if !tokenAccount.isToken {
+			tokenAccount = before
+		}
+		if !tokenAccount.isToken || !tokenAccount.owner.Equals(user) {
+			continue
+		}
+
+		change := int64(after.amount) - int64(before.amount)
+		if change == 0 {
+			continue
+		}
+		mint := tokenAccount.mint.String()
+		mintChanges[mint] += change
+		result.BalanceChanges = append(result.BalanceChanges, types.BalanceChange{
+			Account:     account.String(),
+			Mint:        mint,
+			Owner:       user.String(),
+			PreBalance:  before.amount,
+			PostBalance: after.amount,
+			Change:      change,
+		})
+	}
+
+	result.InputMint, result.OutputMint = req.InputMint, req.OutputMint
+	inferSwapMints(result, mintChanges)
+
+	if change, ok := mintChanges[result.InputMint]; ok && change < 0 {
+		result.InputSpent = uint64(-change)
+	} else if result.InputMint == solana.SolMint.String() {
+		// 直接使用原生SOL支付时，从SOL变化中扣除手续费和租金
+		spent := -result.SOLChange - result.RentChange
+		if tx.Message.AccountKeys[0].Equals(user) {
+			spent -= int64(result.Fee)
+		}
+		if spent > 0 {
+			result.InputSpent = uint64(spent)
+		}
+	}
+
+	if change, ok := mintChanges[result.OutputMint]; ok && change > 0 {
+		result.OutputReceived = uint64(change)
+	} else if result.OutputMint == solana.SolMint.String() && result.SOLChange > 0 {
+		result.OutputReceived = uint64(result.SOLChange)
+	}
+
+	if result.QuotedAmountOut > 0 && result.OutputReceived > 0 {
+		result.Slippage = (float64(result.QuotedAmountOut) - float64(result.OutputReceived)) / float64(result.QuotedAmountOut)
+	}
+
+	return result
+}
+
+// inferSwapMints 未指定输入输出代币时，以唯一减少的代币为输入、唯一增加的代币为输出
+func inferSwapMints(result *types.SimulationResult, mintChanges map[string]int64) {
+	var decreased, increased []string
+	for mint, change := range mintChanges {
+		if change < 0 {
+			decreased = append(decreased, mint)
+		} else if change > 0 {
+			increased = append(increased, mint)
+		}
+	}
+	sort.Strings(decreased)
+	sort.Strings(increased)
+
+	if result.InputMint == "" && len(decreased) == 1 {
+		result.InputMint = decreased[0]
+	}
+	if result.OutputMint == "" && len(increased) == 1 {
+		result.OutputMint = increased[0]
+	}
+}
+
+// nativeAmount 原生SOL代币账户中包装的SOL数量，其余账户返回0
+func nativeAmount(snapshot accountSnapshot) uint64 {
+	if snapshot.isToken && snapshot.mint.Equals(solana.SolMint) && snapshot.amount <= snapshot.lamports {
+		return snapshot.amount
+	}
+	return 0
+}
+
+// estimateSimulationFee 根据签名数量和计算预算指令估算交易手续费
+func estimateSimulationFee(tx *solana.Transaction) uint64 {
+	fee := uint64(tx.Message.Header.NumRequiredSignatures) * lamportsPerSignature
+
+	var unitLimit uint32
+	var unitPrice uint64
+	hasLimit := false
+	otherInstructions := 0
+	for _, inst := range tx.Message.Instructions {
+		programID, err := tx.Message.Program(inst.ProgramIDIndex)
+		if err != nil || !programID.Equals(solana.ComputeBudget) {
+			otherInstructions++
+			continue
+		}
+		decoded, err := decodeComputeBudgetInstruction(inst.Data, nil)
+		if err != nil {
+			continue
+		}
+		switch decoded.Name {
+		case "set_compute_unit_limit":
+			unitLimit = decoded.Fields["units"].(uint32)
+			hasLimit = true
+		case "set_compute_unit_price":
+			unitPrice = decoded.Fields["micro_lamports"].(uint64)
+		}
+	}
+
+	if !hasLimit {
+		limit := otherInstructions * defaultComputeUnitsPerInstruction
+		if limit > maxComputeUnits {
+			limit = maxComputeUnits
+		}
+		unitLimit = uint32(limit)
+	}
+
+	// 优先费用 = 计算单元上限 × 单价（micro-lamports），向上取整
+	fee += (uint64(unitLimit)*unitPrice + 999999) / 1000000
+	return fee
+}
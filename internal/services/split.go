@@ -0,0 +1,409 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+	"time"
+
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// splitVenue 拆单时的候选DEX及其当前分配
+type splitVenue struct {
+	dex        string
+	allocated  uint64               // 已分配的输入金额
+	quote      *types.QuoteResponse // 已分配金额的报价
+	next       *types.QuoteResponse // 再分配一份后的报价
+	nextAmount uint64               // next对应的输入金额
+	err        error                // 询价失败后不再参与分配
+}
+
+// SplitQuote 将输入金额等分为若干份，逐份分配给边际净输出最高的DEX，并与不拆单的直接报价一起排序
+// parts为0时使用配置的份数
+func (ts *TransactionService) SplitQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64, parts int) (*types.RouteQuoteResponse, error) {
+	cfg := ts.currentConfig()
+	resp := &types.RouteQuoteResponse{
+		InputMint:    inputMint,
+		OutputMint:   outputMint,
+		AmountIn:     amountIn,
+		Alternatives: []types.VenueQuote{},
+	}
+
+	if parts == 0 {
+		parts = cfg.Routing.SplitParts
+	}
+	if parts < 2 || parts > 100 {
+		resp.Error = "split parts must be between 2 and 100"
+		return resp, nil
+	}
+
+	if err := validateQuoteParams(inputMint, outputMint, amountIn); err != nil {
+		resp.Error = err.Error()
+		return resp, nil
+	}
+	if uint64(parts) > amountIn {
+		parts = int(amountIn)
+	}
+
+	ctx, cancel := withTimeout(ctx, cfg.Server.QuoteTimeout)
+	defer cancel()
+
+	// 不拆单的直接报价与拆单并行计算
+	type directResult struct {
+		quotes, failed []types.VenueQuote
+	}
+	direct := make(chan directResult, 1)
+	go func() {
+		quotes, failed := ts.quoteRoutes(ctx, inputMint, outputMint, amountIn, 1)
+		direct <- directResult{quotes: quotes, failed: failed}
+	}()
+
+	var split *types.VenueQuote
+	var splitErr error
+	if parts >= 2 {
+		split, splitErr = ts.splitOrder(ctx, inputMint, outputMint, amountIn, parts)
+	}
+
+	result := <-direct
+	quotes, failed := result.quotes, result.failed
+	if splitErr != nil {
+		failed = append(failed, types.VenueQuote{DEX: "split", Error: splitErr.Error()})
+	} else if split != nil {
+		quotes = append(quotes, *split)
+		rankQuotes(quotes)
+	}
+
+	resp.Failed = failed
+	if len(quotes) == 0 {
+		resp.Error = "no DEX returned a quote"
+		return resp, nil
+	}
+
+	resp.Success = true
+	resp.Best = &quotes[0]
+	resp.Alternatives = append(resp.Alternatives, quotes[1:]...)
+	return resp, nil
+}
+
+// splitOrder 按边际价格逐份分配输入金额：每一份分配给再增加这一份后净输出增长最多的DEX
+// 只有一个DEX获得分配时返回nil，此时与直接报价相同
+func (ts *TransactionService) splitOrder(ctx context.Context, inputMint, outputMint string, amountIn uint64, parts int) (*types.VenueQuote, error) {
+	cfg := ts.currentConfig()
+	start := time.Now()
+
+	timeout := cfg.Routing.QuoteTimeout
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	maxVenues := cfg.Routing.MaxSplitVenues
+
+	var venues []*splitVenue
+	for name, adapter := range ts.registry().GetAll() {
+		if adapter.GetConfig().Enabled {
+			venues = append(venues, &splitVenue{dex: name})
+		}
+	}
+	sort.Slice(venues, func(i, j int) bool { return venues[i].dex < venues[j].dex })
+
+	// 除不尽的部分并入第一份
+	chunk := amountIn / uint64(parts)
+	first := amountIn - chunk*uint64(parts-1)
+
+	used := 0
+	for i := 0; i < parts; i++ {
+		size := chunk
+		if i == 0 {
+			size = first
+		}
+
+		// 已达到DEX数量上限时只在已使用的DEX之间分配
+		var candidates []*splitVenue
+		for _, venue := range venues {
+			if venue.err == nil && (venue.allocated > 0 || used < maxVenues) {
+				candidates = append(candidates, venue)
+			}
+		}
+		ts.quoteSplitVenues(ctx, candidates, inputMint, outputMint, size)
+
+		var best *splitVenue
+		var bestGain uint64
+		for _, venue := range candidates {
+			if venue.err != nil {
+				continue
+			}
+			gain := marginalGain(venue)
+			if best == nil || gain > bestGain || (gain == bestGain && venue.allocated > 0 && best.allocated == 0) {
+				best, bestGain = venue, gain
+			}
+		}
+		if best == nil {
+			reasons := make([]string, 0, len(candidates))
+			for _, venue := range candidates {
+				reasons = append(reasons, fmt.Sprintf("%s: %v", venue.dex, venue.err))
+			}
+			return nil, fmt.Errorf("no DEX could fill part %d of %d (%s)", i+1, parts, strings.Join(reasons, "; "))
+		}
+
+		if best.allocated == 0 {
+			used++
+		}
+		best.allocated = best.nextAmount
+		best.quote = best.next
+		best.next = nil
+	}
+	if used < 2 {
+		return nil, nil
+	}
+
+	var split []types.Route
+	var names []string
+	aggregate := &types.QuoteResponse{
+		InputMint:  inputMint,
+		OutputMint: outputMint,
+		AmountIn:   amountIn,
+	}
+	var netOut uint64
+	for _, venue := range venues {
+		if venue.allocated == 0 {
+			continue
+		}
+		out := netAmountOut(venue.quote)
+		split = append(split, types.Route{
+			InputMint:  inputMint,
+			OutputMint: outputMint,
+			DEX:        venue.dex,
+			AmountIn:   venue.allocated,
+			AmountOut:  out,
+		})
+		aggregate.AmountOut += venue.quote.AmountOut
+		aggregate.MinAmountOut += venue.quote.MinAmountOut
+		aggregate.Fee += venue.quote.Fee
+		aggregate.PriceImpact += venue.quote.PriceImpact * float64(venue.allocated) / float64(amountIn)
+		netOut += out
+	}
+	sort.SliceStable(split, func(i, j int) bool { return split[i].AmountIn > split[j].AmountIn })
+	for _, part := range split {
+		names = append(names, part.DEX)
+	}
+	aggregate.Route = split
+
+	return &types.VenueQuote{
+		DEX:          strings.Join(names, "+"),
+		Quote:        aggregate,
+		NetAmountOut: netOut,
+		Split:        split,
+		LatencyMs:    time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// quoteSplitVenues 并发为候选DEX询价再分配一份后的报价，ctx取消或超时前未返回的DEX视为失败
+func (ts *TransactionService) quoteSplitVenues(ctx context.Context, venues []*splitVenue, inputMint, outputMint string, size uint64) {
+	type quoteResult struct {
+		venue *splitVenue
+		quote *types.QuoteResponse
+		err   error
+	}
+
+	results := make(chan quoteResult, len(venues))
+	pending := make(map[*splitVenue]bool)
+	for _, venue := range venues {
+		amount := venue.allocated + size
+		if venue.next != nil && venue.nextAmount == amount {
+			continue
+		}
+		adapter, err := ts.registry().Get(venue.dex)
+		if err != nil {
+			venue.err = err
+			continue
+		}
+		venue.next, venue.nextAmount = nil, amount
+		pending[venue] = true
+		go func(venue *splitVenue, adapter types.DEXAdapter, amount uint64) {
+			quote, err := adapter.GetQuote(ctx, inputMint, outputMint, amount)
+			if err == nil && (quote == nil || quote.AmountOut == 0) {
+				err = fmt.Errorf("empty quote")
+			}
+			results <- quoteResult{venue: venue, quote: quote, err: err}
+		}(venue, adapter, amount)
+	}
+
+	for len(pending) > 0 {
+		select {
+		case result := <-results:
+			delete(pending, result.venue)
+			if result.err != nil {
+				result.venue.err = result.err
+			} else {
+				result.venue.next = result.quote
+			}
+		case <-ctx.Done():
+			for venue := range pending {
+				venue.err = fmt.Errorf("quote aborted: %w", ctx.Err())
+			}
+			return
+		}
+	}
+}
+
+// marginalGain 再分配一份后净输出的增量
+func marginalGain(venue *splitVenue) uint64 {
+	next := netAmountOut(venue.next)
+	var current uint64
+	if venue.quote != nil {
+		current = netAmountOut(venue.quote)
+	}
+	if next <= current {
+		return 0
+	}
+	return next - current
+}
+
+// encodeSplitSwap 将拆单的各部分编码为并列的交换指令，一笔交易放不下时按顺序拆分为多笔交易
+// 总最小输出按各部分报价的净输出比例分摊，每部分独立校验
+func (ts *TransactionService) encodeSplitSwap(ctx context.Context, req *types.SwapRequest, route *types.VenueQuote, blockhash *BlockhashInfo) (*types.TransactionResponse, error) {
+	payerAddress := feePayerOrWallet(req.FeePayer, req.UserWallet)
+
+	minAmountOut := req.MinAmountOut
+	if minAmountOut == 0 {
+		minAmountOut = uint64(float64(route.NetAmountOut) * (1 - req.Slippage))
+	}
+
+	var instructions []solana.Instruction
+	var allocated uint64
+	for i, part := range route.Split {
+		adapter, err := ts.registry().Get(part.DEX)
+		if err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("DEX adapter not found: %s", part.DEX),
+			}, nil
+		}
+
+		// 最后一部分承担按比例分摊后的余数，保证各部分最小输出之和等于总最小输出
+		partMin := mulDiv(minAmountOut, part.AmountOut, route.NetAmountOut)
+		if i == len(route.Split)-1 {
+			partMin = minAmountOut - allocated
+		}
+		allocated += partMin
+
+		partReq := &types.SwapRequest{
+			InputMint:    part.InputMint,
+			OutputMint:   part.OutputMint,
+			AmountIn:     part.AmountIn,
+			UserWallet:   req.UserWallet,
+			FeePayer:     req.FeePayer,
+			Slippage:     req.Slippage,
+			MinAmountOut: partMin,
+			DEXType:      part.DEX,
+		}
+		if err := adapter.ValidateRequest(partReq); err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid request for split part %d (%s): %v", i+1, part.DEX, err),
+			}, nil
+		}
+		instructionData, err := adapter.BuildSwapInstruction(partReq)
+		if err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to build swap instruction for split part %d (%s): %v", i+1, part.DEX, err),
+			}, nil
+		}
+		instructions = append(instructions, toSolanaInstruction(instructionData))
+	}
+
+	txs, blockhash, err := ts.packTransactions(ctx, instructions, payerAddress, req.PriorityFee, blockhash)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to build transaction: %v", err),
+		}, nil
+	}
+
+	resp := &types.TransactionResponse{
+		Success:              true,
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+		RequestID:            req.ID,
+		DEXType:              route.DEX,
+		QuotedAmountOut:      route.NetAmountOut,
+		Split:                route.Split,
+	}
+	for _, tx := range txs {
+		estimatedFee, err := ts.estimateTransactionFee(ctx, tx)
+		if err != nil {
+			// 费用估算失败不影响交易构建，使用默认值
+			estimatedFee = 5000
+		}
+		resp.EstimatedFee += estimatedFee
+
+		txData, err := tx.MarshalBinary()
+		if err != nil {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+			}, nil
+		}
+		resp.Transactions = append(resp.Transactions, base64.StdEncoding.EncodeToString(txData))
+	}
+	resp.Transaction = resp.Transactions[0]
+	if len(resp.Transactions) == 1 {
+		resp.Transactions = nil
+	}
+	return resp, nil
+}
+
+// packTransactions 按顺序将指令装入交易，超过交易大小或账户数量限制时开始新的交易，所有交易使用同一个区块哈希
+func (ts *TransactionService) packTransactions(ctx context.Context, instructions []solana.Instruction, payerAddress string, priorityFee uint64, blockhash *BlockhashInfo) ([]*solana.Transaction, *BlockhashInfo, error) {
+	var txs []*solana.Transaction
+	var current []solana.Instruction
+	var currentTx *solana.Transaction
+
+	for i := 0; i < len(instructions); i++ {
+		candidate := append(append([]solana.Instruction{}, current...), instructions[i])
+		tx, bh, err := ts.buildTransaction(ctx, candidate, payerAddress, priorityFee, blockhash)
+		if err != nil {
+			return nil, nil, err
+		}
+		blockhash = bh
+
+		report, err := preflightTransaction(tx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(report.Violations) == 0 {
+			current, currentTx = candidate, tx
+			continue
+		}
+		if len(current) == 0 {
+			return nil, nil, fmt.Errorf("instruction %d does not fit in a transaction: %s", i+1, limitViolationError(report.Violations))
+		}
+
+		// 当前交易已满，从这条指令开始新的交易
+		txs = append(txs, currentTx)
+		current, currentTx = nil, nil
+		i--
+	}
+	if currentTx != nil {
+		txs = append(txs, currentTx)
+	}
+	if len(txs) == 0 {
+		return nil, nil, fmt.Errorf("no instructions to encode")
+	}
+	return txs, blockhash, nil
+}
+
+// mulDiv 计算a*b/c，中间结果不溢出（要求b<=c）
+func mulDiv(a, b, c uint64) uint64 {
+	if c == 0 {
+		return 0
+	}
+	hi, lo := bits.Mul64(a, b)
+	quo, _ := bits.Div64(hi, lo, c)
+	return quo
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+
+	"solana-dex-service/internal/store"
+	"solana-dex-service/internal/types"
+	pkgtypes "solana-dex-service/pkg/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// GetTransactionStatus 获取交易状态，未最终确认的交易会先从链上刷新
+func (ts *TransactionService) GetTransactionStatus(ctx context.Context, signature string) (*pkgtypes.TransactionResult, error) {
+	if ts.txStore == nil {
+		return nil, fmt.Errorf("transaction store not initialized")
+	}
+
+	result, err := ts.txStore.Get(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Status == pkgtypes.TransactionStatusSubmitted || result.Status == pkgtypes.TransactionStatusConfirmed {
+		ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.SubmitTimeout)
+		defer cancel()
+		if err := ts.refreshTransactionStatus(ctx, result); err != nil {
+			// 刷新失败时返回已存储的状态
+			log.Printf("failed to refresh transaction %s: %v", signature, err)
+			return result, nil
+		}
+		if err := ts.txStore.Save(result); err != nil {
+			return nil, fmt.Errorf("failed to save transaction status: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// ListTransactions 按钱包、DEX和状态列出交易记录
+func (ts *TransactionService) ListTransactions(filter store.TransactionFilter) ([]pkgtypes.TransactionResult, int, error) {
+	if ts.txStore == nil {
+		return nil, 0, fmt.Errorf("transaction store not initialized")
+	}
+	return ts.txStore.List(filter)
+}
+
+// recordTransaction 持久化已发送的交易
+func (ts *TransactionService) recordTransaction(req *types.TransactionTestRequest, tx *solana.Transaction, resp *types.TransactionTestResponse) {
+	if ts.txStore == nil || len(tx.Signatures) == 0 {
+		return
+	}
+
+	result := &pkgtypes.TransactionResult{
+		RequestID:       req.RequestID,
+		TransactionData: req.Transaction,
+		Signature:       tx.Signatures[0].String(),
+		Wallet:          transactionWallet(tx).String(),
+		FeePayer:        tx.Message.AccountKeys[0].String(),
+		DEX:             ts.detectDEX(tx),
+		Status:          pkgtypes.TransactionStatusSubmitted,
+		Success:         resp.Success,
+		ExecutedAt:      time.Now(),
+	}
+	if resp.Signature != "" {
+		result.Signature = resp.Signature
+	}
+	if req.UserWallet != "" {
+		result.Wallet = req.UserWallet
+	}
+	if !resp.Success {
+		result.Status = pkgtypes.TransactionStatusFailed
+		result.ErrorMessage = resp.Error
+	}
+
+	// 重新序列化已签名的交易
+	if txData, err := tx.MarshalBinary(); err == nil {
+		result.TransactionData = base64.StdEncoding.EncodeToString(txData)
+	}
+
+	if err := ts.txStore.Save(result); err != nil {
+		log.Printf("failed to record transaction %s: %v", result.Signature, err)
+	}
+}
+
+// refreshTransactionStatus 从链上查询交易状态并更新记录
+func (ts *TransactionService) refreshTransactionStatus(ctx context.Context, result *pkgtypes.TransactionResult) error {
+	signature, err := solana.SignatureFromBase58(result.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	var statuses *rpc.GetSignatureStatusesResult
+	err = ts.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+		statuses, err = client.GetSignatureStatuses(ctx, true, signature)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get signature status: %w", err)
+	}
+	if len(statuses.Value) == 0 || statuses.Value[0] == nil {
+		// 节点尚未看到该交易
+		return nil
+	}
+
+	status := statuses.Value[0]
+	result.Slot = status.Slot
+	switch {
+	case status.Err != nil:
+		result.Status = pkgtypes.TransactionStatusFailed
+		result.Success = false
+		result.ErrorMessage = fmt.Sprintf("%v", status.Err)
+	case status.ConfirmationStatus == rpc.ConfirmationStatusFinalized:
+		result.Status = pkgtypes.TransactionStatusFinalized
+	case status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed:
+		result.Status = pkgtypes.TransactionStatusConfirmed
+	default:
+		return nil
+	}
+
+	// 已上链的交易补充费用和计算单元信息
+	maxVersion := uint64(0)
+	var txDetails *rpc.GetTransactionResult
+	err = ts.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+		txDetails, err = client.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+			Commitment:                     rpc.CommitmentConfirmed,
+			MaxSupportedTransactionVersion: &maxVersion,
+		})
+		return err
+	})
+	if err == nil && txDetails != nil && txDetails.Meta != nil {
+		result.Fee = txDetails.Meta.Fee
+		if txDetails.Meta.ComputeUnitsConsumed != nil {
+			result.GasUsed = *txDetails.Meta.ComputeUnitsConsumed
+		}
+		if txDetails.BlockTime != nil {
+			result.ExecutedAt = txDetails.BlockTime.Time()
+		}
+	}
+
+	return nil
+}
+
+// detectDEX 根据交易中调用的程序ID识别DEX
+func (ts *TransactionService) detectDEX(tx *solana.Transaction) string {
+	for _, inst := range tx.Message.Instructions {
+		programID, err := tx.Message.Program(inst.ProgramIDIndex)
+		if err != nil {
+			continue
+		}
+		if name, _, ok := ts.registry().FindByProgramID(programID.String()); ok {
+			return name
+		}
+	}
+	return ""
+}
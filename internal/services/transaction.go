@@ -4,11 +4,20 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"solana-dex-service/internal/adapters"
 	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/rpcpool"
+	"solana-dex-service/internal/signer"
+	"solana-dex-service/internal/store"
 	"solana-dex-service/internal/types"
+	"solana-dex-service/internal/upstream"
 
 	bin "github.com/gagliardetto/binary"
 	"github.com/gagliardetto/solana-go"
@@ -18,56 +27,221 @@ import (
 
 // TransactionService 交易服务
 type TransactionService struct {
-	config          *config.Config
-	adapterRegistry *adapters.AdapterRegistry
-	rpcClient       *rpc.Client
+	adapterSet  atomic.Pointer[adapterSet]
+	reloadMu    sync.Mutex
+	rpcPool     *rpcpool.Pool
+	blockhashes *BlockhashManager
+	health      *DEXHealthChecker
+	txStore     store.TransactionStore
+	signer      signer.Signer
+	poolGraph   *poolGraph
+}
+
+// adapterSet 由同一份配置创建的适配器，配置变更时整体替换
+type adapterSet struct {
+	config   *config.Config
+	registry *adapters.AdapterRegistry
+	errors   map[string]error            // 创建失败的DEX及原因
+	configs  map[string]config.DEXConfig // 创建适配器使用的DEX配置
+	breaker  config.BreakerConfig
+	upstream config.UpstreamConfig
+	client   *upstream.Client
 }
 
 // NewTransactionService 创建交易服务
 func NewTransactionService(cfg *config.Config) *TransactionService {
-	// 创建RPC客户端
-	rpcClient := rpc.New(cfg.Solana.RPCURL)
+	// 创建RPC端点池
+	rpcPool, err := rpcpool.New(cfg.Solana.GetRPCEndpoints(), cfg.Solana.HealthCheckInterval, cfg.Solana.MaxSlotLag)
+	if err != nil {
+		log.Printf("invalid rpc endpoints, falling back to rpc_url: %v", err)
+		rpcPool, _ = rpcpool.New([]config.RPCEndpointConfig{{URL: cfg.Solana.RPCURL}}, cfg.Solana.HealthCheckInterval, cfg.Solana.MaxSlotLag)
+	}
+
+	ts := &TransactionService{
+		poolGraph:   newPoolGraph(),
+		rpcPool:     rpcPool,
+		blockhashes: NewBlockhashManager(rpcPool, cfg.Solana.BlockhashCommitment, cfg.Solana.BlockhashRefreshInterval),
+		health:      NewDEXHealthChecker(cfg, rpcPool),
+	}
+	ts.adapterSet.Store(buildAdapterSet(cfg, nil))
+	return ts
+}
 
-	// 创建适配器注册表
-	adapterRegistry := adapters.NewAdapterRegistry()
+// buildAdapterSet 按DEX配置的类型创建适配器，创建失败的DEX记录错误原因
+// 与prev相比配置未变化的DEX复用原适配器，保留熔断状态和报价缓存
+func buildAdapterSet(cfg *config.Config, prev *adapterSet) *adapterSet {
+	set := &adapterSet{
+		config:   cfg,
+		registry: adapters.NewAdapterRegistry(),
+		errors:   make(map[string]error),
+		configs:  make(map[string]config.DEXConfig),
+		breaker:  cfg.Breaker,
+		upstream: cfg.Upstream,
+	}
+	reusable := prev != nil && prev.breaker == cfg.Breaker && prev.upstream == cfg.Upstream
+
+	// 所有适配器共享上游连接池，同一主机的连接可以复用
+	if reusable {
+		set.client = prev.client
+	} else {
+		set.client = upstream.New(&cfg.Upstream)
+	}
 
-	// 注册所有DEX适配器
 	for _, dexCfg := range cfg.DEXes {
+		dexCfg := dexCfg
 		if !dexCfg.Enabled {
 			continue
 		}
+		set.configs[dexCfg.Name] = dexCfg
 
-		switch dexCfg.Name {
-		case "raydium":
-			if adapter, err := adapters.NewRaydiumAdapter(&dexCfg); err == nil {
-				adapterRegistry.Register(dexCfg.Name, adapter)
-			}
-		case "pumpfun":
-			if adapter, err := adapters.NewPumpfunAdapter(&dexCfg); err == nil {
-				adapterRegistry.Register(dexCfg.Name, adapter)
-			}
-		case "pumpswap":
-			if adapter, err := adapters.NewPumpSwapAdapter(&dexCfg); err == nil {
-				adapterRegistry.Register(dexCfg.Name, adapter)
+		if reusable && sameDEXConfig(prev.configs[dexCfg.Name], dexCfg) {
+			if adapter, err := prev.registry.Get(dexCfg.Name); err == nil {
+				set.registry.Register(dexCfg.Name, adapter)
+				continue
 			}
 		}
+
+		adapter, err := adapters.NewAdapter(&dexCfg)
+		if err != nil {
+			log.Printf("failed to create DEX adapter %s: %v", dexCfg.Name, err)
+			set.errors[dexCfg.Name] = err
+			continue
+		}
+		if setter, ok := adapter.(interface{ SetUpstreamClient(*upstream.Client) }); ok {
+			setter.SetUpstreamClient(set.client)
+		}
+
+		// 缓存命中的报价不经过熔断器，熔断器只统计实际的上游调用
+		adapter = adapters.WithBreaker(adapter, &cfg.Breaker)
+		set.registry.Register(dexCfg.Name, adapters.WithQuoteCache(adapter, &dexCfg))
 	}
 
-	return &TransactionService{
-		config:          cfg,
-		adapterRegistry: adapterRegistry,
-		rpcClient:       rpcClient,
+	return set
+}
+
+// sameDEXConfig 比较两个DEX配置，忽略创建和更新时间
+func sameDEXConfig(a, b config.DEXConfig) bool {
+	a.CreatedAt, a.UpdatedAt = time.Time{}, time.Time{}
+	b.CreatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return reflect.DeepEqual(a, b)
+}
+
+// ApplyConfig 应用变更后的配置：重建适配器注册表并原子替换，进行中的请求继续使用原适配器
+func (ts *TransactionService) ApplyConfig(cfg *config.Config) {
+	ts.reloadMu.Lock()
+	defer ts.reloadMu.Unlock()
+
+	set := buildAdapterSet(cfg, ts.adapterSet.Load())
+	ts.health.SetConfig(cfg)
+	ts.adapterSet.Store(set)
+	ts.poolGraph.invalidate()
+	log.Printf("config applied, %d DEX adapters active", len(set.registry.GetAll()))
+}
+
+// currentConfig 返回当前配置，与适配器注册表一起原子替换，不能保存到字段中
+func (ts *TransactionService) currentConfig() *config.Config {
+	return ts.adapterSet.Load().config
+}
+
+// registry 返回当前的适配器注册表
+func (ts *TransactionService) registry() *adapters.AdapterRegistry {
+	return ts.adapterSet.Load().registry
+}
+
+// Start 启动后台任务（RPC健康检查、区块哈希刷新、DEX健康检查）
+func (ts *TransactionService) Start() {
+	ts.rpcPool.Start()
+	ts.blockhashes.Start()
+	ts.health.Start()
+}
+
+// Stop 停止后台任务
+func (ts *TransactionService) Stop() {
+	ts.health.Stop()
+	ts.blockhashes.Stop()
+	ts.rpcPool.Stop()
+}
+
+// GetRPCStatus 获取RPC端点健康状态
+func (ts *TransactionService) GetRPCStatus() []rpcpool.EndpointStatus {
+	return ts.rpcPool.Status()
+}
+
+// CheckDEXHealth 立即检查所有启用的DEX
+func (ts *TransactionService) CheckDEXHealth(ctx context.Context) {
+	ts.health.CheckAll(ctx)
+}
+
+// GetDEXHealth 获取DEX最近的主动健康检查结果，尚未检查时返回nil
+func (ts *TransactionService) GetDEXHealth(dexName string) *types.DEXHealth {
+	return ts.health.Get(dexName)
+}
+
+// SetTransactionStore 设置交易记录存储
+func (ts *TransactionService) SetTransactionStore(s store.TransactionStore) {
+	ts.txStore = s
+}
+
+// SetSigner 设置交易签名器
+func (ts *TransactionService) SetSigner(s signer.Signer) {
+	ts.signer = s
+}
+
+// ListSignerKeys 列出签名器中可用的密钥
+func (ts *TransactionService) ListSignerKeys(ctx context.Context) ([]signer.KeyInfo, error) {
+	if ts.signer == nil {
+		return nil, fmt.Errorf("no signer configured")
 	}
+	return ts.signer.ListKeys(ctx)
 }
 
 // EncodeSwapTransaction 编码交换交易
-func (ts *TransactionService) EncodeSwapTransaction(req *types.SwapRequest) (*types.TransactionResponse, error) {
+func (ts *TransactionService) EncodeSwapTransaction(ctx context.Context, req *types.SwapRequest) (*types.TransactionResponse, error) {
+	ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.EncodeTimeout)
+	defer cancel()
+	return ts.encodeSwap(ctx, req, nil)
+}
+
+// encodeSwap 编码交换交易，blockhash为nil时使用缓存的最新区块哈希
+func (ts *TransactionService) encodeSwap(ctx context.Context, req *types.SwapRequest, blockhash *BlockhashInfo) (*types.TransactionResponse, error) {
 	// 生成请求ID
 	req.ID = uuid.New().String()
 	req.CreatedAt = time.Now()
 
+	// 自动路由：选择扣除手续费后净输出最高的DEX，允许拆单时同时比较拆分到多个DEX的方案
+	var routed *types.VenueQuote
+	if req.DEXType == DEXTypeAuto {
+		var route *types.RouteQuoteResponse
+		var err error
+		if req.Split {
+			route, err = ts.SplitQuote(ctx, req.InputMint, req.OutputMint, req.AmountIn, 0)
+		} else {
+			route, err = ts.RouteQuote(ctx, req.InputMint, req.OutputMint, req.AmountIn, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !route.Success {
+			return &types.TransactionResponse{
+				Success: false,
+				Error:   fmt.Sprintf("No route found: %s", route.Error),
+			}, nil
+		}
+		routed = route.Best
+
+		// 多跳路径的所有跳编码到同一笔交易中
+		if len(routed.Legs) > 1 {
+			return ts.encodeMultiHopSwap(ctx, req, routed, blockhash)
+		}
+		// 拆单的各部分编码为并列的交换指令
+		if len(routed.Split) > 1 {
+			return ts.encodeSplitSwap(ctx, req, routed, blockhash)
+		}
+		req.DEXType = routed.DEX
+	}
+
 	// 获取对应的DEX适配器
-	adapter, err := ts.adapterRegistry.Get(req.DEXType)
+	adapter, err := ts.registry().Get(req.DEXType)
 	if err != nil {
 		return &types.TransactionResponse{
 			Success: false,
@@ -93,18 +267,10 @@ func (ts *TransactionService) EncodeSwapTransaction(req *types.SwapRequest) (*ty
 	}
 
 	// 创建Solana指令
-	accounts := make(solana.AccountMetaSlice, len(instructionData.Accounts))
-	for i, acc := range instructionData.Accounts {
-		accounts[i] = &acc
-	}
-	instruction := solana.NewInstruction(
-		instructionData.ProgramID,
-		accounts,
-		instructionData.Data,
-	)
+	instruction := toSolanaInstruction(instructionData)
 
 	// 创建交易
-	tx, err := ts.buildTransaction([]solana.Instruction{instruction}, req.UserWallet, req.PriorityFee)
+	tx, blockhash, err := ts.buildTransaction(ctx, []solana.Instruction{instruction}, feePayerOrWallet(req.FeePayer, req.UserWallet), req.PriorityFee, blockhash)
 	if err != nil {
 		return &types.TransactionResponse{
 			Success: false,
@@ -112,8 +278,24 @@ func (ts *TransactionService) EncodeSwapTransaction(req *types.SwapRequest) (*ty
 		}, nil
 	}
 
+	// 预检交易大小和账户数量，超出限制时不返回交易
+	report, err := preflightTransaction(tx)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+	if len(report.Violations) > 0 {
+		return &types.TransactionResponse{
+			Success:   false,
+			Error:     limitViolationError(report.Violations),
+			Preflight: report,
+		}, nil
+	}
+
 	// 估算费用
-	estimatedFee, err := ts.estimateTransactionFee(tx)
+	estimatedFee, err := ts.estimateTransactionFee(ctx, tx)
 	if err != nil {
 		// 费用估算失败不影响交易构建，使用默认值
 		estimatedFee = 5000 // 默认5000 lamports
@@ -128,22 +310,36 @@ func (ts *TransactionService) EncodeSwapTransaction(req *types.SwapRequest) (*ty
 		}, nil
 	}
 
-	return &types.TransactionResponse{
-		Success:      true,
-		Transaction:  base64.StdEncoding.EncodeToString(txData),
-		EstimatedFee: estimatedFee,
-		RequestID:    req.ID,
-	}, nil
+	resp := &types.TransactionResponse{
+		Success:              true,
+		Transaction:          base64.StdEncoding.EncodeToString(txData),
+		EstimatedFee:         estimatedFee,
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+		RequestID:            req.ID,
+		Preflight:            report,
+	}
+	if routed != nil {
+		resp.DEXType = routed.DEX
+		resp.QuotedAmountOut = routed.NetAmountOut
+	}
+	return resp, nil
 }
 
 // EncodeLiquidityTransaction 编码流动性交易
-func (ts *TransactionService) EncodeLiquidityTransaction(req *types.LiquidityRequest) (*types.TransactionResponse, error) {
+func (ts *TransactionService) EncodeLiquidityTransaction(ctx context.Context, req *types.LiquidityRequest) (*types.TransactionResponse, error) {
+	ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.EncodeTimeout)
+	defer cancel()
+	return ts.encodeLiquidity(ctx, req, nil)
+}
+
+// encodeLiquidity 编码流动性交易，blockhash为nil时使用缓存的最新区块哈希
+func (ts *TransactionService) encodeLiquidity(ctx context.Context, req *types.LiquidityRequest, blockhash *BlockhashInfo) (*types.TransactionResponse, error) {
 	// 生成请求ID
 	req.ID = uuid.New().String()
 	req.CreatedAt = time.Now()
 
 	// 获取对应的DEX适配器
-	adapter, err := ts.adapterRegistry.Get(req.DEXType)
+	adapter, err := ts.registry().Get(req.DEXType)
 	if err != nil {
 		return &types.TransactionResponse{
 			Success: false,
@@ -169,18 +365,10 @@ func (ts *TransactionService) EncodeLiquidityTransaction(req *types.LiquidityReq
 	}
 
 	// 创建Solana指令
-	accounts := make(solana.AccountMetaSlice, len(instructionData.Accounts))
-	for i, acc := range instructionData.Accounts {
-		accounts[i] = &acc
-	}
-	instruction := solana.NewInstruction(
-		instructionData.ProgramID,
-		accounts,
-		instructionData.Data,
-	)
+	instruction := toSolanaInstruction(instructionData)
 
 	// 创建交易
-	tx, err := ts.buildTransaction([]solana.Instruction{instruction}, req.UserWallet, req.PriorityFee)
+	tx, blockhash, err := ts.buildTransaction(ctx, []solana.Instruction{instruction}, feePayerOrWallet(req.FeePayer, req.UserWallet), req.PriorityFee, blockhash)
 	if err != nil {
 		return &types.TransactionResponse{
 			Success: false,
@@ -188,8 +376,24 @@ func (ts *TransactionService) EncodeLiquidityTransaction(req *types.LiquidityReq
 		}, nil
 	}
 
+	// 预检交易大小和账户数量，超出限制时不返回交易
+	report, err := preflightTransaction(tx)
+	if err != nil {
+		return &types.TransactionResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+	if len(report.Violations) > 0 {
+		return &types.TransactionResponse{
+			Success:   false,
+			Error:     limitViolationError(report.Violations),
+			Preflight: report,
+		}, nil
+	}
+
 	// 估算费用
-	estimatedFee, err := ts.estimateTransactionFee(tx)
+	estimatedFee, err := ts.estimateTransactionFee(ctx, tx)
 	if err != nil {
 		estimatedFee = 5000 // 默认5000 lamports
 	}
@@ -204,86 +408,195 @@ func (ts *TransactionService) EncodeLiquidityTransaction(req *types.LiquidityReq
 	}
 
 	return &types.TransactionResponse{
-		Success:      true,
-		Transaction:  base64.StdEncoding.EncodeToString(txData),
-		EstimatedFee: estimatedFee,
-		RequestID:    req.ID,
+		Success:              true,
+		Transaction:          base64.StdEncoding.EncodeToString(txData),
+		EstimatedFee:         estimatedFee,
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+		RequestID:            req.ID,
+		Preflight:            report,
 	}, nil
 }
 
 // TestTransaction 测试交易上链
-func (ts *TransactionService) TestTransaction(req *types.TransactionTestRequest) (*types.TransactionTestResponse, error) {
+func (ts *TransactionService) TestTransaction(ctx context.Context, req *types.TransactionTestRequest) (*types.TransactionTestResponse, error) {
 	// 解码交易数据
-	txData, err := base64.StdEncoding.DecodeString(req.Transaction)
+	tx, err := decodeTransaction(req.Transaction)
 	if err != nil {
 		return &types.TransactionTestResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to decode transaction: %v", err),
+			Error:   err.Error(),
 		}, nil
 	}
 
-	// 反序列化交易
-	var tx solana.Transaction
-	if err := bin.NewBorshDecoder(txData).Decode(&tx); err != nil {
+	ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.SubmitTimeout)
+	defer cancel()
+
+	signerIDs := signerRefs(req)
+	if len(signerIDs) == 0 {
+		// 未指定签名密钥时只允许模拟，不校验签名并替换区块哈希
+		if req.SimulateOnly {
+			return ts.simulateTransaction(ctx, tx, req, false)
+		}
 		return &types.TransactionTestResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Failed to deserialize transaction: %v", err),
+			Error:   "signer_id is required",
 		}, nil
 	}
-
-	// 解析私钥
-	privateKey, err := solana.PrivateKeyFromBase58(req.PrivateKey)
-	if err != nil {
+	if ts.signer == nil {
 		return &types.TransactionTestResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Invalid private key: %v", err),
+			Error:   "No signer configured",
 		}, nil
 	}
 
-	// 签名交易
-	if _, err := tx.Sign(func(key solana.PublicKey) *solana.PrivateKey {
-		if key.Equals(privateKey.PublicKey()) {
-			return &privateKey
-		}
-		return nil
-	}); err != nil {
+	// 使用签名器签名交易，请求中不包含私钥
+	if err := signer.SignTransaction(ctx, ts.signer, tx, signerIDs...); err != nil {
 		return &types.TransactionTestResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to sign transaction: %v", err),
 		}, nil
 	}
 
-	ctx := context.Background()
+	// 发送前必须集齐所有签名，模拟时不校验签名
+	if missing := missingSigners(tx); len(missing) > 0 && !req.SimulateOnly {
+		return &types.TransactionTestResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Missing signatures for: %s", strings.Join(missing, ", ")),
+		}, nil
+	}
 
 	if req.SimulateOnly {
 		// 仅模拟执行
-		return ts.simulateTransaction(ctx, &tx)
-	} else {
-		// 实际发送交易
-		return ts.sendTransaction(ctx, &tx)
+		return ts.simulateTransaction(ctx, tx, req, true)
 	}
+
+	// 实际发送交易并记录结果
+	resp, err := ts.sendTransaction(ctx, tx)
+	if err == nil {
+		ts.recordTransaction(req, tx, resp)
+	}
+	return resp, err
 }
 
 // SimulateTransaction 模拟交易执行
-func (ts *TransactionService) SimulateTransaction(req *types.TransactionTestRequest) (*types.TransactionTestResponse, error) {
+func (ts *TransactionService) SimulateTransaction(ctx context.Context, req *types.TransactionTestRequest) (*types.TransactionTestResponse, error) {
 	// 强制设置为仅模拟
 	req.SimulateOnly = true
-	return ts.TestTransaction(req)
+	return ts.TestTransaction(ctx, req)
+}
+
+// RefreshTransactionBlockhash 使用最新区块哈希重新生成未签名交易
+func (ts *TransactionService) RefreshTransactionBlockhash(ctx context.Context, req *types.RefreshBlockhashRequest) (*types.RefreshBlockhashResponse, error) {
+	tx, err := decodeTransaction(req.Transaction)
+	if err != nil {
+		return &types.RefreshBlockhashResponse{
+			Success: false,
+			Error:   err.Error(),
+		}, nil
+	}
+
+	// 已签名的交易更换区块哈希后签名失效，要求调用方在签名前刷新
+	for _, sig := range tx.Signatures {
+		if !sig.IsZero() {
+			return &types.RefreshBlockhashResponse{
+				Success: false,
+				Error:   "transaction is already signed; refresh the blockhash before signing",
+			}, nil
+		}
+	}
+
+	ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.EncodeTimeout)
+	defer cancel()
+
+	blockhash, err := ts.blockhashes.Refresh(ctx)
+	if err != nil {
+		return &types.RefreshBlockhashResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to get latest blockhash: %v", err),
+		}, nil
+	}
+
+	tx.Message.RecentBlockhash = blockhash.Blockhash
+	txData, err := tx.MarshalBinary()
+	if err != nil {
+		return &types.RefreshBlockhashResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to serialize transaction: %v", err),
+		}, nil
+	}
+
+	return &types.RefreshBlockhashResponse{
+		Success:              true,
+		Transaction:          base64.StdEncoding.EncodeToString(txData),
+		Blockhash:            blockhash.Blockhash.String(),
+		LastValidBlockHeight: blockhash.LastValidBlockHeight,
+	}, nil
+}
+
+// decodeTransaction 解码Base64编码的交易
+func decodeTransaction(encoded string) (*solana.Transaction, error) {
+	txData, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decode transaction: %v", err)
+	}
+
+	var tx solana.Transaction
+	if err := bin.NewBorshDecoder(txData).Decode(&tx); err != nil {
+		return nil, fmt.Errorf("Failed to deserialize transaction: %v", err)
+	}
+
+	return &tx, nil
+}
+
+// signerRefs 合并signer_id和signer_ids并去重
+func signerRefs(req *types.TransactionTestRequest) []string {
+	var refs []string
+	seen := make(map[string]bool)
+	for _, ref := range append([]string{req.SignerID}, req.SignerIDs...) {
+		if ref == "" || seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		refs = append(refs, ref)
+	}
+	return refs
 }
 
-// buildTransaction 构建交易
-func (ts *TransactionService) buildTransaction(instructions []solana.Instruction, payerAddress string, priorityFee uint64) (*solana.Transaction, error) {
+// toSolanaInstruction 将适配器构建的指令数据转换为Solana指令
+func toSolanaInstruction(instructionData *types.InstructionData) solana.Instruction {
+	accounts := make(solana.AccountMetaSlice, len(instructionData.Accounts))
+	for i := range instructionData.Accounts {
+		accounts[i] = &instructionData.Accounts[i]
+	}
+	return solana.NewInstruction(
+		instructionData.ProgramID,
+		accounts,
+		instructionData.Data,
+	)
+}
+
+// feePayerOrWallet 返回手续费付款人，未指定时由用户钱包支付
+func feePayerOrWallet(feePayer, userWallet string) string {
+	if feePayer != "" {
+		return feePayer
+	}
+	return userWallet
+}
+
+// buildTransaction 构建交易，blockhash为nil时使用缓存的最新区块哈希
+func (ts *TransactionService) buildTransaction(ctx context.Context, instructions []solana.Instruction, payerAddress string, priorityFee uint64, blockhash *BlockhashInfo) (*solana.Transaction, *BlockhashInfo, error) {
 	// 解析付款人地址
 	payer, err := solana.PublicKeyFromBase58(payerAddress)
 	if err != nil {
-		return nil, fmt.Errorf("invalid payer address: %w", err)
+		return nil, nil, fmt.Errorf("invalid payer address: %w", err)
 	}
 
-	// 获取最新的区块哈希
-	ctx := context.Background()
-	recentBlockhash, err := ts.rpcClient.GetRecentBlockhash(ctx, rpc.CommitmentFinalized)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get recent blockhash: %w", err)
+	// 获取缓存的最新区块哈希
+	if blockhash == nil {
+		blockhash, err = ts.blockhashes.Get(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// 如果设置了优先费用，添加优先费用指令
@@ -295,14 +608,14 @@ func (ts *TransactionService) buildTransaction(instructions []solana.Instruction
 	// 创建交易
 	tx, err := solana.NewTransaction(
 		instructions,
-		recentBlockhash.Value.Blockhash,
+		blockhash.Blockhash,
 		solana.TransactionPayer(payer),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
-	return tx, nil
+	return tx, blockhash, nil
 }
 
 // createPriorityFeeInstruction 创建优先费用指令
@@ -327,15 +640,13 @@ func (ts *TransactionService) createPriorityFeeInstruction(priorityFee uint64) s
 }
 
 // estimateTransactionFee 估算交易费用
-func (ts *TransactionService) estimateTransactionFee(tx *solana.Transaction) (uint64, error) {
-	ctx := context.Background()
-	
-	// 使用RPC客户端获取费用估算
-	feeResponse, err := ts.rpcClient.GetFeeForMessage(
-		ctx,
-		tx.Message.ToBase64(),
-		rpc.CommitmentProcessed,
-	)
+func (ts *TransactionService) estimateTransactionFee(ctx context.Context, tx *solana.Transaction) (uint64, error) {
+	// 使用RPC端点池获取费用估算
+	var feeResponse *rpc.GetFeeForMessageResult
+	err := ts.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+		feeResponse, err = client.GetFeeForMessage(ctx, tx.Message.ToBase64(), rpc.CommitmentProcessed)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get fee estimate: %w", err)
 	}
@@ -347,36 +658,10 @@ func (ts *TransactionService) estimateTransactionFee(tx *solana.Transaction) (ui
 	return *feeResponse.Value, nil
 }
 
-// simulateTransaction 模拟交易执行
-func (ts *TransactionService) simulateTransaction(ctx context.Context, tx *solana.Transaction) (*types.TransactionTestResponse, error) {
-	// 使用RPC客户端模拟交易
-	simResult, err := ts.rpcClient.SimulateTransaction(ctx, tx)
-	if err != nil {
-		return &types.TransactionTestResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Simulation failed: %v", err),
-		}, nil
-	}
-
-	if simResult.Value.Err != nil {
-		return &types.TransactionTestResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Simulation error: %v", simResult.Value.Err),
-			Logs:    simResult.Value.Logs,
-		}, nil
-	}
-
-	return &types.TransactionTestResponse{
-		Success: true,
-		Logs:    simResult.Value.Logs,
-		GasUsed: *simResult.Value.UnitsConsumed,
-	}, nil
-}
-
 // sendTransaction 发送交易到链上
 func (ts *TransactionService) sendTransaction(ctx context.Context, tx *solana.Transaction) (*types.TransactionTestResponse, error) {
-	// 发送交易
-	signature, err := ts.rpcClient.SendTransaction(ctx, tx)
+	// 并发发送到所有发送端点
+	signature, err := ts.rpcPool.Send(ctx, tx)
 	if err != nil {
 		return &types.TransactionTestResponse{
 			Success: false,
@@ -387,10 +672,14 @@ func (ts *TransactionService) sendTransaction(ctx context.Context, tx *solana.Tr
 	// 简化处理，不等待确认
 
 	// 获取交易详情
-	txDetails, err := ts.rpcClient.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
-		Commitment: rpc.CommitmentConfirmed,
+	var txDetails *rpc.GetTransactionResult
+	err = ts.rpcPool.Read(ctx, func(ctx context.Context, client *rpc.Client) (err error) {
+		txDetails, err = client.GetTransaction(ctx, signature, &rpc.GetTransactionOpts{
+			Commitment: rpc.CommitmentConfirmed,
+		})
+		return err
 	})
-	if err == nil && txDetails != nil {
+	if err == nil && txDetails != nil && txDetails.Meta != nil {
 		return &types.TransactionTestResponse{
 			Success:   true,
 			Signature: signature.String(),
@@ -406,10 +695,27 @@ func (ts *TransactionService) sendTransaction(ctx context.Context, tx *solana.Tr
 
 // GetSupportedDEXes 获取支持的DEX列表
 func (ts *TransactionService) GetSupportedDEXes() []string {
-	return ts.adapterRegistry.List()
+	return ts.registry().List()
 }
 
 // GetDEXAdapter 获取DEX适配器
 func (ts *TransactionService) GetDEXAdapter(name string) (types.DEXAdapter, error) {
-	return ts.adapterRegistry.Get(name)
+	return ts.registry().Get(name)
+}
+
+// GetQuoteWithCacheStatus 获取指定DEX的报价及报价缓存结果
+func (ts *TransactionService) GetQuoteWithCacheStatus(ctx context.Context, dexName, inputMint, outputMint string, amountIn uint64) (*types.QuoteResponse, string, error) {
+	adapter, err := ts.registry().Get(dexName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, cancel := withTimeout(ctx, ts.currentConfig().Server.QuoteTimeout)
+	defer cancel()
+	return adapters.GetQuoteWithCacheStatus(ctx, adapter, inputMint, outputMint, amountIn)
+}
+
+// GetAdapterError 获取DEX适配器创建失败的原因，创建成功或未启用时返回nil
+func (ts *TransactionService) GetAdapterError(name string) error {
+	return ts.adapterSet.Load().errors[name]
 }
\ No newline at end of file
@@ -0,0 +1,309 @@
+package signer
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gagliardetto/solana-go"
+	"golang.org/x/crypto/scrypt"
+)
+
+// scrypt参数
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// keyIDPattern 密钥ID只允许字母、数字、下划线和连字符，同时作为文件名使用
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// keyFile 密钥库文件格式，私钥使用scrypt派生的密钥进行AES-256-GCM加密
+type keyFile struct {
+	ID        string    `json:"id"`
+	PublicKey string    `json:"public_key"`
+	Crypto    keyCrypto `json:"crypto"`
+}
+
+// keyCrypto 加密参数
+type keyCrypto struct {
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Keystore 本地加密密钥库，使用口令解锁后才能签名
+type Keystore struct {
+	dir string
+
+	mu       sync.RWMutex
+	files    map[string]*keyFile          // 按ID索引的密钥文件
+	unlocked map[string]solana.PrivateKey // 解锁后的私钥，按ID索引
+}
+
+// NewKeystore 打开密钥库目录，读取所有密钥的元数据
+func NewKeystore(dir string) (*Keystore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("keystore dir is required")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore dir: %w", err)
+	}
+
+	ks := &Keystore{
+		dir:   dir,
+		files: make(map[string]*keyFile),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file %s: %w", entry.Name(), err)
+		}
+		var kf keyFile
+		if err := json.Unmarshal(data, &kf); err != nil {
+			return nil, fmt.Errorf("invalid key file %s: %w", entry.Name(), err)
+		}
+		ks.files[kf.ID] = &kf
+	}
+
+	return ks, nil
+}
+
+// Unlock 使用口令解密所有密钥
+func (ks *Keystore) Unlock(passphrase string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	unlocked := make(map[string]solana.PrivateKey, len(ks.files))
+	for id, kf := range ks.files {
+		key, err := decryptKey(kf, passphrase)
+		if err != nil {
+			return fmt.Errorf("failed to unlock key %s: %w", id, err)
+		}
+		unlocked[id] = key
+	}
+	ks.unlocked = unlocked
+	return nil
+}
+
+// Lock 清除内存中的私钥
+func (ks *Keystore) Lock() {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.unlocked = nil
+}
+
+// Locked 密钥库是否处于锁定状态
+func (ks *Keystore) Locked() bool {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.unlocked == nil
+}
+
+// Import 使用口令加密私钥并写入密钥库
+func (ks *Keystore) Import(id string, key solana.PrivateKey, passphrase string) (*KeyInfo, error) {
+	if !keyIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid key id %q: only letters, digits, '_' and '-' are allowed", id)
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required")
+	}
+	if len(key) != 64 {
+		return nil, fmt.Errorf("invalid private key length: %d", len(key))
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if _, exists := ks.files[id]; exists {
+		return nil, fmt.Errorf("key %s already exists", id)
+	}
+
+	kf, err := encryptKey(id, key, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(ks.dir, id+".json"), data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write key file: %w", err)
+	}
+
+	ks.files[id] = kf
+	if ks.unlocked != nil {
+		ks.unlocked[id] = key
+	}
+	return &KeyInfo{ID: id, PublicKey: key.PublicKey()}, nil
+}
+
+// ListKeys 列出密钥库中的密钥
+func (ks *Keystore) ListKeys(ctx context.Context) ([]KeyInfo, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	keys := make([]KeyInfo, 0, len(ks.files))
+	for id, kf := range ks.files {
+		pub, err := solana.PublicKeyFromBase58(kf.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("key %s has invalid public key: %w", id, err)
+		}
+		keys = append(keys, KeyInfo{ID: id, PublicKey: pub})
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys, nil
+}
+
+// Resolve 根据ID或公钥查找密钥
+func (ks *Keystore) Resolve(ctx context.Context, ref string) (*KeyInfo, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kf := ks.lookup(ref)
+	if kf == nil {
+		return nil, ErrKeyNotFound
+	}
+	pub, err := solana.PublicKeyFromBase58(kf.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("key %s has invalid public key: %w", kf.ID, err)
+	}
+	return &KeyInfo{ID: kf.ID, PublicKey: pub}, nil
+}
+
+// Sign 使用已解锁的私钥签名
+func (ks *Keystore) Sign(ctx context.Context, ref string, message []byte) (solana.Signature, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	kf := ks.lookup(ref)
+	if kf == nil {
+		return solana.Signature{}, ErrKeyNotFound
+	}
+	if ks.unlocked == nil {
+		return solana.Signature{}, ErrLocked
+	}
+	return ks.unlocked[kf.ID].Sign(message)
+}
+
+// lookup 按ID或公钥查找密钥文件，调用方需持有读锁
+func (ks *Keystore) lookup(ref string) *keyFile {
+	if kf, ok := ks.files[ref]; ok {
+		return kf
+	}
+	for _, kf := range ks.files {
+		if kf.PublicKey == ref {
+			return kf
+		}
+	}
+	return nil
+}
+
+// encryptKey 加密私钥
+func encryptKey(id string, key solana.PrivateKey, passphrase string) (*keyFile, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	publicKey := key.PublicKey().String()
+	// 公钥作为附加认证数据，防止密钥文件中的公钥被篡改
+	ciphertext := gcm.Seal(nil, nonce, key, []byte(publicKey))
+
+	return &keyFile{
+		ID:        id,
+		PublicKey: publicKey,
+		Crypto: keyCrypto{
+			KDF:        "scrypt",
+			N:          scryptN,
+			R:          scryptR,
+			P:          scryptP,
+			Salt:       hex.EncodeToString(salt),
+			Nonce:      hex.EncodeToString(nonce),
+			Ciphertext: hex.EncodeToString(ciphertext),
+		},
+	}, nil
+}
+
+// decryptKey 解密私钥
+func decryptKey(kf *keyFile, passphrase string) (solana.PrivateKey, error) {
+	if kf.Crypto.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported kdf: %s", kf.Crypto.KDF)
+	}
+	salt, err := hex.DecodeString(kf.Crypto.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+	nonce, err := hex.DecodeString(kf.Crypto.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	ciphertext, err := hex.DecodeString(kf.Crypto.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt, kf.Crypto.N, kf.Crypto.R, kf.Crypto.P)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length: %d", len(nonce))
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte(kf.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid passphrase")
+	}
+
+	key := solana.PrivateKey(plaintext)
+	if key.PublicKey().String() != kf.PublicKey {
+		return nil, fmt.Errorf("decrypted key does not match public key")
+	}
+	return key, nil
+}
+
+// newGCM 使用scrypt从口令派生AES-256-GCM
+func newGCM(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	derived, err := scrypt.Key([]byte(passphrase), salt, n, r, p, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
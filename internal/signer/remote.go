@@ -0,0 +1,184 @@
+package signer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// RemoteSigner 远程签名服务客户端
+//
+// 协议：
+//
+//	GET  {url}/keys  -> {"keys": [{"id": "...", "public_key": "..."}]}
+//	POST {url}/sign  {"key": "<id或公钥>", "message": "<base64>"} -> {"signature": "<base58>"}
+type RemoteSigner struct {
+	baseURL    string
+	authToken  string
+	httpClient *http.Client
+
+	// 密钥列表缓存，签名时不必每次都请求 /keys
+	mu        sync.Mutex
+	keys      []KeyInfo
+	fetchedAt time.Time
+}
+
+// remoteKeysTTL 远程密钥列表的缓存时间
+const remoteKeysTTL = time.Minute
+
+// remoteSignRequest 远程签名请求
+type remoteSignRequest struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+}
+
+// remoteSignResponse 远程签名响应
+type remoteSignResponse struct {
+	Signature string `json:"signature"`
+}
+
+// remoteKeysResponse 远程密钥列表响应
+type remoteKeysResponse struct {
+	Keys []KeyInfo `json:"keys"`
+}
+
+// NewRemoteSigner 创建远程签名服务客户端
+func NewRemoteSigner(baseURL, authToken string, timeout time.Duration) (*RemoteSigner, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("remote signer url is required")
+	}
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &RemoteSigner{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		authToken:  authToken,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// ListKeys 列出远程签名服务提供的密钥，并刷新密钥列表缓存
+func (rs *RemoteSigner) ListKeys(ctx context.Context) ([]KeyInfo, error) {
+	var resp remoteKeysResponse
+	if err := rs.do(ctx, http.MethodGet, "/keys", nil, &resp); err != nil {
+		return nil, err
+	}
+
+	rs.mu.Lock()
+	rs.keys, rs.fetchedAt = resp.Keys, time.Now()
+	rs.mu.Unlock()
+	return resp.Keys, nil
+}
+
+// Resolve 根据ID或公钥查找远程密钥
+// 优先使用缓存的密钥列表，缓存过期或找不到密钥时重新获取，以便发现远程新增的密钥
+func (rs *RemoteSigner) Resolve(ctx context.Context, ref string) (*KeyInfo, error) {
+	rs.mu.Lock()
+	keys, fresh := rs.keys, time.Since(rs.fetchedAt) < remoteKeysTTL
+	rs.mu.Unlock()
+
+	if fresh {
+		if key := findKey(keys, ref); key != nil {
+			return key, nil
+		}
+	}
+
+	keys, err := rs.ListKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if key := findKey(keys, ref); key != nil {
+		return key, nil
+	}
+	return nil, ErrKeyNotFound
+}
+
+// findKey 在密钥列表中按ID或公钥查找
+func findKey(keys []KeyInfo, ref string) *KeyInfo {
+	for _, key := range keys {
+		if key.ID == ref || key.PublicKey.String() == ref {
+			key := key
+			return &key
+		}
+	}
+	return nil
+}
+
+// Sign 请求远程签名服务签名，并校验返回的签名
+func (rs *RemoteSigner) Sign(ctx context.Context, ref string, message []byte) (solana.Signature, error) {
+	key, err := rs.Resolve(ctx, ref)
+	if err != nil {
+		return solana.Signature{}, err
+	}
+
+	var resp remoteSignResponse
+	req := remoteSignRequest{
+		Key:     ref,
+		Message: base64.StdEncoding.EncodeToString(message),
+	}
+	if err := rs.do(ctx, http.MethodPost, "/sign", req, &resp); err != nil {
+		return solana.Signature{}, err
+	}
+
+	signature, err := solana.SignatureFromBase58(resp.Signature)
+	if err != nil {
+		return solana.Signature{}, fmt.Errorf("remote signer returned invalid signature: %w", err)
+	}
+	if !signature.Verify(key.PublicKey, message) {
+		return solana.Signature{}, fmt.Errorf("remote signer returned a signature that does not verify for %s", key.PublicKey)
+	}
+	return signature, nil
+}
+
+// do 发送请求并解析JSON响应
+func (rs *RemoteSigner) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rs.baseURL+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if rs.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+rs.authToken)
+	}
+
+	resp, err := rs.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote signer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read remote signer response: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound && path == "/sign" {
+		return ErrKeyNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote signer returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse remote signer response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"solana-dex-service/internal/config"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// 签名器后端类型
+const (
+	BackendKeystore = "keystore" // 本地加密密钥库
+	BackendRemote   = "remote"   // 远程签名服务
+)
+
+var (
+	// ErrKeyNotFound 密钥不存在
+	ErrKeyNotFound = errors.New("signer key not found")
+	// ErrLocked 密钥库未解锁
+	ErrLocked = errors.New("keystore is locked")
+)
+
+// KeyInfo 签名密钥信息（不包含密钥材料）
+type KeyInfo struct {
+	ID        string           `json:"id"`
+	PublicKey solana.PublicKey `json:"public_key"`
+}
+
+// Signer 签名器接口，密钥通过ID或公钥引用
+type Signer interface {
+	// ListKeys 列出可用的签名密钥
+	ListKeys(ctx context.Context) ([]KeyInfo, error)
+	// Resolve 根据ID或Base58公钥查找密钥
+	Resolve(ctx context.Context, ref string) (*KeyInfo, error)
+	// Sign 使用指定密钥对消息签名
+	Sign(ctx context.Context, ref string, message []byte) (solana.Signature, error)
+}
+
+// New 根据配置创建签名器
+func New(cfg config.SignerConfig) (Signer, error) {
+	switch cfg.Backend {
+	case BackendKeystore, "":
+		ks, err := NewKeystore(cfg.KeystoreDir)
+		if err != nil {
+			return nil, err
+		}
+
+		passphrase, err := readSecret(cfg.PassphraseEnv, cfg.PassphraseFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore passphrase: %w", err)
+		}
+		// 未提供口令时密钥库保持锁定，签名请求会返回ErrLocked
+		if passphrase != "" {
+			if err := ks.Unlock(passphrase); err != nil {
+				return nil, err
+			}
+		}
+		return ks, nil
+	case BackendRemote:
+		token, err := readSecret(cfg.RemoteTokenEnv, "")
+		if err != nil {
+			return nil, err
+		}
+		return NewRemoteSigner(cfg.RemoteURL, token, cfg.Timeout)
+	default:
+		return nil, fmt.Errorf("unknown signer backend: %s", cfg.Backend)
+	}
+}
+
+// SignTransaction 使用签名器为交易添加签名，每个引用对应的公钥必须是交易的签名账户
+func SignTransaction(ctx context.Context, s Signer, tx *solana.Transaction, refs ...string) error {
+	message, err := tx.Message.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to serialize message: %w", err)
+	}
+
+	numSigners := int(tx.Message.Header.NumRequiredSignatures)
+	if len(tx.Signatures) != numSigners {
+		signatures := make([]solana.Signature, numSigners)
+		copy(signatures, tx.Signatures)
+		tx.Signatures = signatures
+	}
+
+	for _, ref := range refs {
+		key, err := s.Resolve(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("signer %s: %w", ref, err)
+		}
+
+		index := -1
+		for i := 0; i < numSigners && i < len(tx.Message.AccountKeys); i++ {
+			if tx.Message.AccountKeys[i].Equals(key.PublicKey) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("signer %s (%s) is not a required signer of this transaction", ref, key.PublicKey)
+		}
+
+		signature, err := s.Sign(ctx, ref, message)
+		if err != nil {
+			return fmt.Errorf("signer %s: %w", ref, err)
+		}
+		tx.Signatures[index] = signature
+	}
+
+	return nil
+}
+
+// readSecret 从环境变量或文件读取密钥，环境变量优先
+func readSecret(envName, filePath string) (string, error) {
+	if envName != "" {
+		if value := os.Getenv(envName); value != "" {
+			return value, nil
+		}
+	}
+	if filePath != "" {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"solana-dex-service/pkg/types"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrConfigVersionNotFound 配置版本不存在
+var ErrConfigVersionNotFound = errors.New("config version not found")
+
+// configVersionsBucket 配置版本bucket名称
+var configVersionsBucket = []byte("config_versions")
+
+// ConfigHistoryStore 配置版本存储接口，版本号从1开始递增
+type ConfigHistoryStore interface {
+	Append(version *types.ConfigVersion) error
+	Get(version int) (*types.ConfigVersion, error)
+	Latest() (*types.ConfigVersion, error)
+	List() ([]types.ConfigVersion, error)
+	Close() error
+}
+
+// BoltConfigHistoryStore 基于bbolt的配置版本存储
+type BoltConfigHistoryStore struct {
+	db *bolt.DB
+}
+
+// NewBoltConfigHistoryStore 打开（或创建）配置版本数据库
+func NewBoltConfigHistoryStore(path string) (*BoltConfigHistoryStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config history store: %w", err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(configVersionsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize config history store: %w", err)
+	}
+
+	return &BoltConfigHistoryStore{db: db}, nil
+}
+
+// Append 保存新的配置版本，分配下一个版本号并写回version.Version
+func (s *BoltConfigHistoryStore) Append(version *types.ConfigVersion) error {
+	if version.AppliedAt.IsZero() {
+		version.AppliedAt = time.Now()
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(configVersionsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		version.Version = int(seq)
+
+		data, err := json.Marshal(version)
+		if err != nil {
+			return fmt.Errorf("failed to marshal config version: %w", err)
+		}
+		return bucket.Put(versionKey(version.Version), data)
+	})
+}
+
+// Get 获取指定版本（包括完整配置）
+func (s *BoltConfigHistoryStore) Get(version int) (*types.ConfigVersion, error) {
+	var result types.ConfigVersion
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(configVersionsBucket).Get(versionKey(version))
+		if data == nil {
+			return ErrConfigVersionNotFound
+		}
+		return json.Unmarshal(data, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Latest 获取最新版本，没有任何版本时返回ErrConfigVersionNotFound
+func (s *BoltConfigHistoryStore) Latest() (*types.ConfigVersion, error) {
+	var result types.ConfigVersion
+	err := s.db.View(func(tx *bolt.Tx) error {
+		_, data := tx.Bucket(configVersionsBucket).Cursor().Last()
+		if data == nil {
+			return ErrConfigVersionNotFound
+		}
+		return json.Unmarshal(data, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// List 按版本号倒序列出所有版本，不包含完整配置
+func (s *BoltConfigHistoryStore) List() ([]types.ConfigVersion, error) {
+	versions := []types.ConfigVersion{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(configVersionsBucket).Cursor()
+		for _, data := cursor.Last(); data != nil; _, data = cursor.Prev() {
+			var version types.ConfigVersion
+			if err := json.Unmarshal(data, &version); err != nil {
+				return err
+			}
+			version.Config = ""
+			versions = append(versions, version)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config versions: %w", err)
+	}
+	return versions, nil
+}
+
+// Close 关闭数据库
+func (s *BoltConfigHistoryStore) Close() error {
+	return s.db.Close()
+}
+
+// versionKey 版本号按大端编码，保证按版本号顺序遍历
+func versionKey(version int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(version))
+	return key
+}
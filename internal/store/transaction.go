@@ -0,0 +1,159 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"solana-dex-service/pkg/types"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrTransactionNotFound 交易记录不存在
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+// transactionsBucket 交易记录bucket名称
+var transactionsBucket = []byte("transactions")
+
+// TransactionFilter 交易记录查询条件
+type TransactionFilter struct {
+	Wallet string // 用户钱包地址
+	DEX    string // DEX名称
+	Status string // 交易状态
+	Limit  int    // 返回数量限制，0表示不限制
+	Offset int    // 偏移量
+}
+
+// TransactionStore 交易记录存储接口
+type TransactionStore interface {
+	Save(result *types.TransactionResult) error
+	Get(signature string) (*types.TransactionResult, error)
+	List(filter TransactionFilter) ([]types.TransactionResult, int, error)
+	Close() error
+}
+
+// BoltTransactionStore 基于bbolt的交易记录存储
+type BoltTransactionStore struct {
+	db *bolt.DB
+}
+
+// NewBoltTransactionStore 打开（或创建）交易记录数据库
+func NewBoltTransactionStore(path string) (*BoltTransactionStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create storage directory: %w", err)
+		}
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transaction store: %w", err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(transactionsBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize transaction store: %w", err)
+	}
+
+	return &BoltTransactionStore{db: db}, nil
+}
+
+// Save 保存交易记录，以签名为键，已存在时覆盖
+func (s *BoltTransactionStore) Save(result *types.TransactionResult) error {
+	if result.Signature == "" {
+		return fmt.Errorf("transaction signature is required")
+	}
+
+	now := time.Now()
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = now
+	}
+	result.UpdatedAt = now
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal transaction result: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(transactionsBucket).Put([]byte(result.Signature), data)
+	})
+}
+
+// Get 根据签名获取交易记录
+func (s *BoltTransactionStore) Get(signature string) (*types.TransactionResult, error) {
+	var result types.TransactionResult
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(transactionsBucket).Get([]byte(signature))
+		if data == nil {
+			return ErrTransactionNotFound
+		}
+		return json.Unmarshal(data, &result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// List 按条件列出交易记录，按创建时间倒序，返回当前页和匹配总数
+func (s *BoltTransactionStore) List(filter TransactionFilter) ([]types.TransactionResult, int, error) {
+	var results []types.TransactionResult
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(transactionsBucket).ForEach(func(_, data []byte) error {
+			var result types.TransactionResult
+			if err := json.Unmarshal(data, &result); err != nil {
+				return err
+			}
+			if filter.matches(&result) {
+				results = append(results, result)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
+	total := len(results)
+	if filter.Offset >= total {
+		return []types.TransactionResult{}, total, nil
+	}
+	end := total
+	if filter.Limit > 0 && filter.Offset+filter.Limit < total {
+		end = filter.Offset + filter.Limit
+	}
+
+	return results[filter.Offset:end], total, nil
+}
+
+// Close 关闭数据库
+func (s *BoltTransactionStore) Close() error {
+	return s.db.Close()
+}
+
+// matches 判断交易记录是否满足查询条件
+func (f TransactionFilter) matches(result *types.TransactionResult) bool {
+	if f.Wallet != "" && result.Wallet != f.Wallet {
+		return false
+	}
+	if f.DEX != "" && result.DEX != f.DEX {
+		return false
+	}
+	if f.Status != "" && result.Status != f.Status {
+		return false
+	}
+	return true
+}
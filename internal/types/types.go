@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"time"
 
 	"github.com/gagliardetto/solana-go"
@@ -9,15 +10,18 @@ import (
 
 // SwapRequest 交换请求结构
 type SwapRequest struct {
-	InputMint    string    `json:"input_mint"`    // 输入代币地址
-	OutputMint   string    `json:"output_mint"`   // 输出代币地址
-	AmountIn     uint64    `json:"amount_in"`     // 输入金额
-	UserWallet   string    `json:"user_wallet"`   // 用户钱包地址
-	Slippage     float64   `json:"slippage"`      // 滑点容忍度
-	PriorityFee  uint64    `json:"priority_fee"`  // 优先费用
-	DEXType      string    `json:"dex_type"`      // DEX类型
-	ID           string    `json:"id"`            // 请求ID
-	CreatedAt    time.Time `json:"created_at"`    // 创建时间
+	InputMint    string    `json:"input_mint"`     // 输入代币地址
+	OutputMint   string    `json:"output_mint"`    // 输出代币地址
+	AmountIn     uint64    `json:"amount_in"`      // 输入金额
+	UserWallet   string    `json:"user_wallet"`    // 用户钱包地址
+	FeePayer     string    `json:"fee_payer"`      // 手续费付款人地址（可选，默认为用户钱包）
+	Slippage     float64   `json:"slippage"`       // 滑点容忍度
+	MinAmountOut uint64    `json:"min_amount_out"` // 最小输出金额（可选，设置后覆盖按滑点计算的值）
+	PriorityFee  uint64    `json:"priority_fee"`   // 优先费用
+	DEXType      string    `json:"dex_type"`       // DEX类型，auto表示按最优报价自动选择
+	Split        bool      `json:"split"`          // 自动路由时是否允许拆单到多个DEX
+	ID           string    `json:"id"`             // 请求ID
+	CreatedAt    time.Time `json:"created_at"`     // 创建时间
 }
 
 // LiquidityRequest 流动性请求结构
@@ -27,6 +31,7 @@ type LiquidityRequest struct {
 	AmountA      uint64    `json:"amount_a"`       // 代币A数量
 	AmountB      uint64    `json:"amount_b"`       // 代币B数量
 	UserWallet   string    `json:"user_wallet"`    // 用户钱包地址
+	FeePayer     string    `json:"fee_payer"`      // 手续费付款人地址（可选，默认为用户钱包）
 	Slippage     float64   `json:"slippage"`       // 滑点容忍度
 	PriorityFee  uint64    `json:"priority_fee"`   // 优先费用
 	Operation    string    `json:"operation"`      // 操作类型: "add" 或 "remove"
@@ -66,13 +71,39 @@ type PoolInfo struct {
 
 // DEXInfo DEX信息结构
 type DEXInfo struct {
-	Name          string            `json:"name"`           // DEX名称
-	ProgramID     string            `json:"program_id"`     // 程序ID
-	RouterAddress string            `json:"router_address"` // 路由地址
-	Endpoints     map[string]string `json:"endpoints"`      // 端点配置
-	Enabled       bool              `json:"enabled"`        // 是否启用
-	Status        string            `json:"status"`         // 状态
-	Description   string            `json:"description"`    // 描述
+	Name          string            `json:"name"`            // DEX名称
+	Type          string            `json:"type"`            // 适配器类型
+	ProgramID     string            `json:"program_id"`      // 程序ID
+	RouterAddress string            `json:"router_address"`  // 路由地址
+	Endpoints     map[string]string `json:"endpoints"`       // 端点配置
+	Enabled       bool              `json:"enabled"`         // 是否启用
+	Status        string            `json:"status"`          // 状态：online、degraded、offline
+	Description   string            `json:"description"`     // 描述
+	Error         string            `json:"error,omitempty"` // 适配器创建失败的原因
+}
+
+// ProbeResult 单个目标的探测结果
+type ProbeResult struct {
+	Target     string `json:"target"`                // 探测目标：program或端点名称
+	URL        string `json:"url,omitempty"`         // 端点地址
+	OK         bool   `json:"ok"`                    // 是否通过
+	LatencyMs  int64  `json:"latency_ms"`            // 探测耗时（毫秒）
+	StatusCode int    `json:"status_code,omitempty"` // 端点返回的HTTP状态码
+	Error      string `json:"error,omitempty"`       // 失败原因
+}
+
+// HealthProbe 一次健康检查的结果
+type HealthProbe struct {
+	CheckedAt time.Time     `json:"checked_at"` // 检查时间
+	Status    string        `json:"status"`     // online、degraded、offline
+	Results   []ProbeResult `json:"results"`    // 程序账户和各端点的探测结果
+}
+
+// DEXHealth DEX主动健康检查状态
+type DEXHealth struct {
+	DEX    string        `json:"dex"`    // DEX名称
+	Status string        `json:"status"` // 最近一次检查的状态
+	Probes []HealthProbe `json:"probes"` // 最近的检查结果，按时间从新到旧排列
 }
 
 // TransactionResult 交易结果结构
@@ -94,9 +125,11 @@ type QuoteResult struct {
 
 // ErrorResponse 错误响应结构
 type ErrorResponse struct {
-	Error   string `json:"error"`   // 错误信息
-	Code    int    `json:"code"`    // 错误代码
-	Details string `json:"details"` // 详细信息
+	Error            string              `json:"error"`                       // 错误信息
+	Code             int                 `json:"code"`                        // 错误代码
+	Details          string              `json:"details"`                     // 详细信息
+	Violations       []LimitViolation    `json:"violations,omitempty"`        // 超出的交易限制
+	ValidationErrors []config.FieldError `json:"validation_errors,omitempty"` // 配置验证发现的问题
 }
 
 // SuccessResponse 成功响应结构
@@ -115,27 +148,238 @@ type InstructionData struct {
 
 // TransactionResponse 交易响应结构
 type TransactionResponse struct {
-	Success      bool   `json:"success"`       // 是否成功
-	Transaction  string `json:"transaction"`   // Base64编码的交易
-	EstimatedFee uint64 `json:"estimated_fee"` // 估算费用
-	RequestID    string `json:"request_id"`    // 请求ID
-	Error        string `json:"error"`         // 错误信息
+	Success              bool             `json:"success"`                     // 是否成功
+	Transaction          string           `json:"transaction"`                 // Base64编码的交易
+	EstimatedFee         uint64           `json:"estimated_fee"`               // 估算费用
+	LastValidBlockHeight uint64           `json:"last_valid_block_height"`     // 交易过期的区块高度
+	RequestID            string           `json:"request_id"`                  // 请求ID
+	DEXType              string           `json:"dex_type,omitempty"`          // 自动路由选中的DEX
+	QuotedAmountOut      uint64           `json:"quoted_amount_out,omitempty"` // 自动路由选中报价的净输出
+	Route                []Route          `json:"route,omitempty"`             // 多跳路由的每一跳
+	Split                []Route          `json:"split,omitempty"`             // 拆单的各部分
+	Transactions         []string         `json:"transactions,omitempty"`      // 拆单无法放入一笔交易时的所有交易，按顺序发送
+	Preflight            *PreflightReport `json:"preflight,omitempty"`         // 交易大小和账户数量预检结果
+	Error                string           `json:"error"`                       // 错误信息
+}
+
+// BatchEncodeItem 批量编码中的单个条目，type为swap时使用swap，为liquidity时使用liquidity
+type BatchEncodeItem struct {
+	Type      string            `json:"type"`                // 条目类型：swap, liquidity
+	Swap      *SwapRequest      `json:"swap,omitempty"`      // 交换请求
+	Liquidity *LiquidityRequest `json:"liquidity,omitempty"` // 流动性请求
+}
+
+// BatchEncodeRequest 批量编码请求结构
+type BatchEncodeRequest struct {
+	Items []BatchEncodeItem `json:"items"` // 待编码的条目
+}
+
+// BatchEncodeResult 批量编码中单个条目的结果
+type BatchEncodeResult struct {
+	Index    int                  `json:"index"`              // 条目在请求中的位置
+	Type     string               `json:"type"`               // 条目类型
+	Success  bool                 `json:"success"`            // 是否编码成功
+	Response *TransactionResponse `json:"response,omitempty"` // 编码结果
+	Error    string               `json:"error,omitempty"`    // 错误信息
+}
+
+// BatchEncodeResponse 批量编码响应结构
+type BatchEncodeResponse struct {
+	Success              bool                `json:"success"`                 // 批量请求是否被处理
+	Results              []BatchEncodeResult `json:"results"`                 // 按请求顺序排列的条目结果
+	Succeeded            int                 `json:"succeeded"`               // 编码成功的条目数
+	Failed               int                 `json:"failed"`                  // 编码失败的条目数
+	Blockhash            string              `json:"blockhash"`               // 所有交易共用的区块哈希
+	LastValidBlockHeight uint64              `json:"last_valid_block_height"` // 交易过期的区块高度
+	Error                string              `json:"error"`                   // 错误信息
+}
+
+// MultiSwapRequest 原子多交换请求结构，所有交换编码到同一笔交易中
+type MultiSwapRequest struct {
+	Swaps            []SwapRequest `json:"swaps"`              // 交换列表，user_wallet为空时使用请求的钱包
+	UserWallet       string        `json:"user_wallet"`        // 用户钱包地址
+	FeePayer         string        `json:"fee_payer"`          // 手续费付款人地址（可选，默认为用户钱包）
+	PriorityFee      uint64        `json:"priority_fee"`       // 优先费用（计算单元单价，micro-lamports）
+	ComputeUnitLimit uint32        `json:"compute_unit_limit"` // 计算单元上限（可选，默认按交换数量估算）
+}
+
+// MultiSwapResponse 原子多交换响应结构
+type MultiSwapResponse struct {
+	Success              bool             `json:"success"`                 // 是否成功
+	Transaction          string           `json:"transaction"`             // Base64编码的交易
+	EstimatedFee         uint64           `json:"estimated_fee"`           // 估算费用
+	LastValidBlockHeight uint64           `json:"last_valid_block_height"` // 交易过期的区块高度
+	RequestID            string           `json:"request_id"`              // 请求ID
+	Swaps                []Route          `json:"swaps,omitempty"`         // 按顺序编码的交换
+	ComputeUnitLimit     uint32           `json:"compute_unit_limit"`      // 交易的计算单元上限
+	Preflight            *PreflightReport `json:"preflight,omitempty"`     // 交易大小和账户数量预检结果
+	Error                string           `json:"error"`                   // 错误信息
+}
+
+// LimitViolation 超出的Solana交易限制
+type LimitViolation struct {
+	Limit      string `json:"limit"`                // 限制名称：transaction_size, account_locks
+	Actual     int    `json:"actual"`               // 实际值
+	Max        int    `json:"max"`                  // 上限
+	Excess     int    `json:"excess"`               // 超出的数量
+	Suggestion string `json:"suggestion,omitempty"` // 修复建议
+}
+
+// PreflightReport 交易预检结果
+type PreflightReport struct {
+	Size             int              `json:"size"`                        // 签名后交易的字节数
+	Accounts         int              `json:"accounts"`                    // 交易引用的唯一账户数量（含查找表账户）
+	WritableAccounts int              `json:"writable_accounts"`           // 可写账户数量（写锁）
+	Signatures       int              `json:"signatures"`                  // 需要的签名数量
+	EstimatedV0Size  int              `json:"estimated_v0_size,omitempty"` // 改用v0交易和地址查找表后的估算字节数
+	Violations       []LimitViolation `json:"violations,omitempty"`        // 超出的限制
+}
+
+// RefreshBlockhashRequest 刷新区块哈希请求结构
+type RefreshBlockhashRequest struct {
+	Transaction string `json:"transaction" binding:"required"` // Base64编码的未签名交易
+}
+
+// RefreshBlockhashResponse 刷新区块哈希响应结构
+type RefreshBlockhashResponse struct {
+	Success              bool   `json:"success"`                 // 是否成功
+	Transaction          string `json:"transaction"`             // 使用新区块哈希的Base64编码交易
+	Blockhash            string `json:"blockhash"`               // 新区块哈希
+	LastValidBlockHeight uint64 `json:"last_valid_block_height"` // 交易过期的区块高度
+	Error                string `json:"error"`                   // 错误信息
 }
 
 // TransactionTestRequest 交易测试请求结构
 type TransactionTestRequest struct {
-	Transaction   string `json:"transaction"`    // Base64编码的交易数据
-	PrivateKey    string `json:"private_key"`    // 私钥
-	SimulateOnly  bool   `json:"simulate_only"`  // 是否仅模拟
+	Transaction   string   `json:"transaction"`    // Base64编码的交易数据
+	SignerID      string   `json:"signer_id"`      // 签名密钥ID或公钥，私钥由服务端签名器管理
+	SignerIDs     []string `json:"signer_ids"`     // 其他签名密钥（如代付手续费的中继账户）
+	SimulateOnly  bool     `json:"simulate_only"`  // 是否仅模拟
+	RequestID     string   `json:"request_id"`     // 编码时返回的请求ID（可选，用于关联交易记录）
+
+	// 以下字段用于模拟，计算余额变化和实际成交结果；user_wallet同时作为交易记录的钱包
+	UserWallet      string `json:"user_wallet,omitempty"`       // 用户钱包，默认为第一个非付款人签名者
+	InputMint       string `json:"input_mint,omitempty"`        // 输入代币地址，未指定时根据余额变化推断
+	OutputMint      string `json:"output_mint,omitempty"`       // 输出代币地址，未指定时根据余额变化推断
+	QuotedAmountOut uint64 `json:"quoted_amount_out,omitempty"` // 报价输出金额，用于计算实际滑点
+}
+
+// DetachedSignature 分离签名
+type DetachedSignature struct {
+	PublicKey string `json:"public_key" binding:"required"` // 签名者公钥
+	Signature string `json:"signature" binding:"required"`  // Base58编码的签名
+}
+
+// AssembleTransactionRequest 组装交易请求结构
+type AssembleTransactionRequest struct {
+	Message     string              `json:"message"`                                 // Base64编码的交易消息
+	Transaction string              `json:"transaction"`                             // Base64编码的交易（与message二选一），已有签名会保留
+	Signatures  []DetachedSignature `json:"signatures" binding:"required,min=1,dive"` // 各签名方提供的签名
+}
+
+// AssembleTransactionResponse 组装交易响应结构
+type AssembleTransactionResponse struct {
+	Success        bool     `json:"success"`                   // 是否成功
+	Transaction    string   `json:"transaction"`               // Base64编码的已签名交易
+	Signature      string   `json:"signature"`                 // 交易签名（付款人签名）
+	MissingSigners []string `json:"missing_signers,omitempty"` // 缺少签名的账户
+	Error          string   `json:"error"`                     // 错误信息
+}
+
+// DecodeTransactionRequest 交易解码请求结构
+type DecodeTransactionRequest struct {
+	Transaction string `json:"transaction" binding:"required"` // Base64编码的交易（legacy或v0）
+}
+
+// DecodeTransactionResponse 交易解码响应结构
+type DecodeTransactionResponse struct {
+	Success             bool                 `json:"success"`                         // 是否成功
+	Version             string               `json:"version"`                         // 消息版本: legacy, v0
+	Signers             []SignerInfo         `json:"signers"`                         // 签名账户
+	FeePayer            string               `json:"fee_payer"`                       // 手续费付款人
+	RecentBlockhash     string               `json:"recent_blockhash"`                // 区块哈希
+	ComputeBudget       ComputeBudgetInfo    `json:"compute_budget"`                  // 计算预算
+	Instructions        []DecodedInstruction `json:"instructions"`                    // 解码后的指令
+	AddressTableLookups []AddressTableLookup `json:"address_table_lookups,omitempty"` // 地址查找表（v0）
+	Error               string               `json:"error"`                           // 错误信息
+}
+
+// SignerInfo 签名账户信息
+type SignerInfo struct {
+	PublicKey string `json:"public_key"`          // 公钥
+	Writable  bool   `json:"writable"`            // 是否可写
+	Signed    bool   `json:"signed"`              // 是否已签名
+	Signature string `json:"signature,omitempty"` // 签名
+}
+
+// ComputeBudgetInfo 计算预算信息
+type ComputeBudgetInfo struct {
+	UnitLimit     uint32 `json:"unit_limit,omitempty"`      // 计算单元上限
+	UnitPrice     uint64 `json:"unit_price,omitempty"`      // 计算单元价格（micro-lamports）
+	HeapFrameSize uint32 `json:"heap_frame_size,omitempty"` // 堆大小
+}
+
+// AddressTableLookup 地址查找表引用
+type AddressTableLookup struct {
+	AccountKey      string `json:"account_key"`      // 查找表地址
+	WritableIndexes []int  `json:"writable_indexes"` // 可写账户索引
+	ReadonlyIndexes []int  `json:"readonly_indexes"` // 只读账户索引
+}
+
+// DecodedInstruction 解码后的指令
+type DecodedInstruction struct {
+	Index     int                    `json:"index"`            // 指令序号
+	ProgramID string                 `json:"program_id"`       // 程序ID
+	Program   string                 `json:"program"`          // 程序名称，未知程序为空
+	Name      string                 `json:"name"`             // 指令名称，无法解码时为空
+	Fields    map[string]interface{} `json:"fields,omitempty"` // 解码后的参数
+	Accounts  []DecodedAccount       `json:"accounts"`         // 指令账户
+	Data      string                 `json:"data"`             // Base64编码的原始指令数据
+	Error     string                 `json:"error,omitempty"`  // 解码错误
+}
+
+// DecodedAccount 指令账户
+type DecodedAccount struct {
+	Name       string `json:"name,omitempty"` // 账户名称
+	PublicKey  string `json:"public_key"`     // 公钥，查找表中的账户为 查找表地址[索引]
+	IsSigner   bool   `json:"is_signer"`      // 是否签名
+	IsWritable bool   `json:"is_writable"`    // 是否可写
 }
 
 // TransactionTestResponse 交易测试响应结构
 type TransactionTestResponse struct {
-	Success   bool     `json:"success"`   // 是否成功
-	Signature string   `json:"signature"` // 交易签名
-	Logs      []string `json:"logs"`      // 日志
-	GasUsed   uint64   `json:"gas_used"`  // 消耗的Gas
-	Error     string   `json:"error"`     // 错误信息
+	Success    bool              `json:"success"`              // 是否成功
+	Signature  string            `json:"signature"`            // 交易签名
+	Logs       []string          `json:"logs"`                 // 日志
+	GasUsed    uint64            `json:"gas_used"`             // 消耗的Gas
+	Simulation *SimulationResult `json:"simulation,omitempty"` // 模拟执行的余额变化
+	Error      string            `json:"error"`                // 错误信息
+}
+
+// SimulationResult 模拟执行结果，基于模拟前后的账户状态计算
+type SimulationResult struct {
+	Signed          bool            `json:"signed"`                      // 是否使用签名交易模拟（未签名时替换区块哈希且不校验签名）
+	UserWallet      string          `json:"user_wallet"`                 // 统计余额变化的钱包
+	InputMint       string          `json:"input_mint,omitempty"`        // 输入代币地址
+	OutputMint      string          `json:"output_mint,omitempty"`       // 输出代币地址
+	InputSpent      uint64          `json:"input_spent"`                 // 实际花费的输入金额
+	OutputReceived  uint64          `json:"output_received"`             // 实际收到的输出金额
+	SOLChange       int64           `json:"sol_change"`                  // 钱包SOL余额变化（lamports，含手续费和租金）
+	Fee             uint64          `json:"fee"`                         // 交易手续费（基础费用+优先费用）
+	RentChange      int64           `json:"rent_change"`                 // 新建账户押金减去关闭账户退还的租金
+	QuotedAmountOut uint64          `json:"quoted_amount_out,omitempty"` // 报价输出金额
+	Slippage        float64         `json:"slippage"`                    // 相对报价的实际滑点，负数表示优于报价
+	BalanceChanges  []BalanceChange `json:"balance_changes"`             // 各账户余额变化
+}
+
+// BalanceChange 账户模拟前后的余额变化
+type BalanceChange struct {
+	Account     string `json:"account"`        // 账户地址
+	Mint        string `json:"mint,omitempty"` // 代币地址，SOL账户为空
+	Owner       string `json:"owner"`          // 账户所有者（代币账户为持有人钱包）
+	PreBalance  uint64 `json:"pre_balance"`    // 模拟前余额
+	PostBalance uint64 `json:"post_balance"`   // 模拟后余额
+	Change      int64  `json:"change"`         // 余额变化
 }
 
 // QuoteResponse 报价响应结构
@@ -161,13 +405,60 @@ type Route struct {
 	AmountOut  uint64  `json:"amount_out"`  // 输出金额
 }
 
-// DEXAdapter DEX适配器接口
+// VenueQuote 单个DEX的询价结果
+type VenueQuote struct {
+	DEX          string         `json:"dex"`             // DEX名称
+	Quote        *QuoteResponse `json:"quote,omitempty"` // 报价
+	NetAmountOut uint64         `json:"net_amount_out"`  // 扣除手续费后的净输出
+	Legs         []Route        `json:"legs,omitempty"`  // 路径上的每一跳
+	Split        []Route        `json:"split,omitempty"` // 拆单的各部分，每部分在一个DEX上直接成交
+	LatencyMs    int64          `json:"latency_ms"`      // 询价耗时（毫秒）
+	Cache        string         `json:"cache,omitempty"` // 报价缓存结果（hit、miss或coalesced），多跳时按顺序以逗号连接
+	Error        string         `json:"error,omitempty"` // 询价失败原因
+}
+
+// RouteQuoteResponse 跨DEX最优报价响应结构
+type RouteQuoteResponse struct {
+	Success      bool         `json:"success"`          // 是否成功
+	InputMint    string       `json:"input_mint"`       // 输入代币地址
+	OutputMint   string       `json:"output_mint"`      // 输出代币地址
+	AmountIn     uint64       `json:"amount_in"`        // 输入金额
+	Best         *VenueQuote  `json:"best,omitempty"`   // 净输出最高的报价
+	Alternatives []VenueQuote `json:"alternatives"`     // 其他报价，按净输出从高到低排列
+	Failed       []VenueQuote `json:"failed,omitempty"` // 询价失败或超时的DEX
+	Error        string       `json:"error"`            // 错误信息
+}
+
+// 配置差异类型
+const (
+	ConfigChangeAdded   = "added"
+	ConfigChangeRemoved = "removed"
+	ConfigChangeChanged = "changed"
+)
+
+// ConfigChange 两个配置版本之间的一处差异
+type ConfigChange struct {
+	Path string      `json:"path"` // 字段路径，如 server.port、dexes[raydium].enabled
+	Type string      `json:"type"` // added, removed, changed
+	Old  interface{} `json:"old"`  // 原值，新增时为null
+	New  interface{} `json:"new"`  // 新值，删除时为null
+}
+
+// ConfigDiff 配置版本差异
+type ConfigDiff struct {
+	From    int            `json:"from"` // 比较的基准版本，0表示空配置
+	To      int            `json:"to"`
+	Changes []ConfigChange `json:"changes"`
+}
+
+// DEXAdapter DEX适配器接口，访问上游接口的方法随ctx取消或超时
 type DEXAdapter interface {
 	GetName() string
 	GetConfig() *config.DEXConfig
 	ValidateRequest(interface{}) error
 	BuildSwapInstruction(*SwapRequest) (*InstructionData, error)
 	BuildLiquidityInstruction(*LiquidityRequest) (*InstructionData, error)
-	GetQuote(inputMint, outputMint string, amountIn uint64) (*QuoteResponse, error)
-	GetPools() ([]PoolInfo, error)
+	GetQuote(ctx context.Context, inputMint, outputMint string, amountIn uint64) (*QuoteResponse, error)
+	GetPools(ctx context.Context) ([]PoolInfo, error)
+	DecodeInstruction(data []byte, accounts []DecodedAccount) (*DecodedInstruction, error)
 }
\ No newline at end of file
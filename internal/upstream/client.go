@@ -0,0 +1,359 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"solana-dex-service/internal/config"
+)
+
+// 未配置时使用的默认值
+const (
+	defaultMaxIdleConnsPerHost = 16
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultMaxResponseBytes    = 16 << 20
+	defaultBackoffBase         = 200 * time.Millisecond
+	defaultBackoffMax          = 5 * time.Second
+	defaultUserAgent           = "solana-dex-service/1.0"
+)
+
+// maxErrorBodyBytes 错误信息中保留的响应体长度
+const maxErrorBodyBytes = 256
+
+// ErrResponseTooLarge 响应体超过大小上限
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// StatusError 上游返回了错误状态码
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Client 上游HTTP客户端：同一主机的连接复用，请求体在每次重试时重建，
+// 5xx和429按指数退避加随机抖动重试，并遵守Retry-After
+type Client struct {
+	transport        *http.Transport
+	httpClient       *http.Client
+	maxAttempts      int
+	backoffBase      time.Duration
+	backoffMax       time.Duration
+	maxResponseBytes int64
+}
+
+// New 根据上游配置创建客户端，未配置的项使用默认值
+func New(cfg *config.UpstreamConfig) *Client {
+	if cfg == nil {
+		cfg = &config.UpstreamConfig{}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	if transport.MaxIdleConnsPerHost == 0 {
+		transport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	if transport.IdleConnTimeout == 0 {
+		transport.IdleConnTimeout = defaultIdleConnTimeout
+	}
+
+	c := &Client{
+		transport:        transport,
+		httpClient:       &http.Client{Transport: transport},
+		maxAttempts:      1,
+		backoffBase:      cfg.BackoffBase,
+		backoffMax:       cfg.BackoffMax,
+		maxResponseBytes: cfg.MaxResponseBytes,
+	}
+	if c.backoffBase == 0 {
+		c.backoffBase = defaultBackoffBase
+	}
+	if c.backoffMax == 0 {
+		c.backoffMax = defaultBackoffMax
+	}
+	if c.maxResponseBytes == 0 {
+		c.maxResponseBytes = defaultMaxResponseBytes
+	}
+	return c
+}
+
+// WithPolicy 返回共享连接池、使用指定单次超时和最大尝试次数的客户端
+func (c *Client) WithPolicy(timeout time.Duration, maxAttempts int) *Client {
+	clone := *c
+	clone.httpClient = &http.Client{Transport: c.transport, Timeout: timeout}
+	clone.maxAttempts = maxAttempts
+	if clone.maxAttempts < 1 {
+		clone.maxAttempts = 1
+	}
+	return &clone
+}
+
+// Do 发起请求并把JSON响应解析到result。GET、HEAD和DELETE的params编码为查询参数，
+// 其他方法的params编码为JSON请求体
+func (c *Client) Do(ctx context.Context, method, rawURL string, params, result interface{}) error {
+	newRequest, err := c.requestBuilder(method, rawURL, params)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	var delay time.Duration
+	for attempt := 0; attempt < c.maxAttempts; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, delay); err != nil {
+				return fmt.Errorf("request aborted: %w", err)
+			}
+		}
+
+		req, err := newRequest(ctx)
+		if err != nil {
+			return err
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("request aborted: %w", ctx.Err())
+			}
+			lastErr = err
+			delay = c.backoff(attempt)
+			continue
+		}
+
+		data, err := c.readBody(resp)
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("request aborted: %w", ctx.Err())
+			}
+			if errors.Is(err, ErrResponseTooLarge) {
+				return err
+			}
+			lastErr = err
+			delay = c.backoff(attempt)
+			continue
+		}
+
+		if retryableStatus(resp.StatusCode) {
+			lastErr = &StatusError{StatusCode: resp.StatusCode, Body: errorBody(data)}
+			delay = c.backoff(attempt)
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				if retryAfter > c.backoffMax {
+					return fmt.Errorf("%w (retry after %s)", lastErr, retryAfter)
+				}
+				if retryAfter > delay {
+					delay = retryAfter
+				}
+			}
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return &StatusError{StatusCode: resp.StatusCode, Body: errorBody(data)}
+		}
+
+		if result != nil && len(data) > 0 {
+			if err := json.Unmarshal(data, result); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("request failed after %d attempts: %w", c.maxAttempts, lastErr)
+}
+
+// requestBuilder 预先编码参数，返回每次尝试都重新创建请求（包括请求体）的函数
+func (c *Client) requestBuilder(method, rawURL string, params interface{}) (func(ctx context.Context) (*http.Request, error), error) {
+	var body []byte
+	if params != nil {
+		switch method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			encoded, err := withQuery(rawURL, params)
+			if err != nil {
+				return nil, err
+			}
+			rawURL = encoded
+		default:
+			data, err := json.Marshal(params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			}
+			body = data
+		}
+	}
+
+	return func(ctx context.Context) (*http.Request, error) {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", defaultUserAgent)
+		return req, nil
+	}, nil
+}
+
+// readBody 读取并关闭响应体，超过大小上限时返回ErrResponseTooLarge
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	if resp.ContentLength > c.maxResponseBytes {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrResponseTooLarge, resp.ContentLength, c.maxResponseBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(data)) > c.maxResponseBytes {
+		return nil, fmt.Errorf("%w: exceeds limit of %d bytes", ErrResponseTooLarge, c.maxResponseBytes)
+	}
+	return data, nil
+}
+
+// backoff 第attempt次失败后的等待时间：指数增长到上限，在后一半区间内随机抖动
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.backoffBase
+	for i := 0; i < attempt && d < c.backoffMax; i++ {
+		d *= 2
+	}
+	if d > c.backoffMax {
+		d = c.backoffMax
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// sleep 等待指定时间，ctx结束或截止时间早于等待结束时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return context.DeadlineExceeded
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableStatus 限流和服务端错误可以重试，501表示不支持，不重试
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && status != http.StatusNotImplemented)
+}
+
+// parseRetryAfter 解析Retry-After头，支持秒数和HTTP日期
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// errorBody 截断后的响应体，用于错误信息
+func errorBody(data []byte) string {
+	body := strings.TrimSpace(string(data))
+	if len(body) > maxErrorBodyBytes {
+		body = body[:maxErrorBodyBytes] + "..."
+	}
+	return body
+}
+
+// withQuery 把params编码为查询参数追加到URL，params可以是url.Values、map或结构体
+func withQuery(rawURL string, params interface{}) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	query := u.Query()
+	if values, ok := params.(url.Values); ok {
+		for key, vals := range values {
+			for _, v := range vals {
+				query.Add(key, v)
+			}
+		}
+	} else {
+		// 经JSON转换为键值，数字使用json.Number避免大整数丢失精度
+		data, err := json.Marshal(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode query params: %w", err)
+		}
+		var fields map[string]interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err != nil {
+			return "", fmt.Errorf("query params must be an object: %w", err)
+		}
+		for key, value := range fields {
+			if err := addQueryValue(query, key, value); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// addQueryValue 添加一个查询参数，数组展开为同名的多个参数，嵌套对象编码为JSON字符串
+func addQueryValue(query url.Values, key string, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+	case string:
+		query.Add(key, v)
+	case json.Number:
+		query.Add(key, v.String())
+	case bool:
+		query.Add(key, strconv.FormatBool(v))
+	case []interface{}:
+		for _, item := range v {
+			if err := addQueryValue(query, key, item); err != nil {
+				return err
+			}
+		}
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode query param %s: %w", key, err)
+		}
+		query.Add(key, string(data))
+	}
+	return nil
+}
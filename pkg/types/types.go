@@ -16,6 +16,7 @@ type SwapRequest struct {
 	Slippage    float64   `json:"slippage" binding:"min=0,max=1"`
 	PriorityFee uint64    `json:"priority_fee"`
 	UserWallet  string    `json:"user_wallet" binding:"required"`
+	FeePayer    string    `json:"fee_payer"`
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -31,14 +32,19 @@ type LiquidityRequest struct {
 	Slippage     float64   `json:"slippage" binding:"min=0,max=1"`
 	PriorityFee  uint64    `json:"priority_fee"`
 	UserWallet   string    `json:"user_wallet" binding:"required"`
+	FeePayer     string    `json:"fee_payer"`
 	CreatedAt    time.Time `json:"created_at"`
 }
 
 // TransactionTestRequest 交易测试请求
 type TransactionTestRequest struct {
-	Transaction  string `json:"transaction" binding:"required"`
-	SimulateOnly bool   `json:"simulate_only"`
-	PrivateKey   string `json:"private_key" binding:"required"`
+	Transaction     string `json:"transaction" binding:"required"`
+	SimulateOnly    bool   `json:"simulate_only"`
+	SignerID        string `json:"signer_id"` // 仅模拟时可省略，使用未签名交易模拟
+	UserWallet      string `json:"user_wallet,omitempty"`
+	InputMint       string `json:"input_mint,omitempty"`
+	OutputMint      string `json:"output_mint,omitempty"`
+	QuotedAmountOut uint64 `json:"quoted_amount_out,omitempty"`
 }
 
 // TransactionResponse 交易响应
@@ -121,15 +127,40 @@ type SuccessResponse struct {
 	Message string      `json:"message,omitempty"`
 }
 
+// 交易状态
+const (
+	TransactionStatusSubmitted = "submitted" // 已发送，尚未确认
+	TransactionStatusConfirmed = "confirmed" // 已确认
+	TransactionStatusFinalized = "finalized" // 已最终确认
+	TransactionStatusFailed    = "failed"    // 发送或执行失败
+)
+
 // TransactionResult 交易结果
 type TransactionResult struct {
 	RequestID       string    `json:"request_id"`
 	TransactionData string    `json:"transaction_data"`
 	Signature       string    `json:"signature,omitempty"`
+	Wallet          string    `json:"wallet,omitempty"`    // 用户钱包地址
+	FeePayer        string    `json:"fee_payer,omitempty"` // 手续费付款人地址
+	DEX             string    `json:"dex,omitempty"`       // 交易涉及的DEX
+	Status          string    `json:"status"`              // submitted, confirmed, finalized, failed
+	Slot            uint64    `json:"slot,omitempty"`
+	Fee             uint64    `json:"fee,omitempty"` // lamports
 	Success         bool      `json:"success"`
 	ErrorMessage    string    `json:"error_message,omitempty"`
-	GasUsed         uint64    `json:"gas_used,omitempty"`
+	GasUsed         uint64    `json:"gas_used,omitempty"` // 消耗的计算单元
 	ExecutedAt      time.Time `json:"executed_at"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ConfigVersion 已应用的配置版本
+type ConfigVersion struct {
+	Version   int       `json:"version"`
+	Author    string    `json:"author"`
+	Reason    string    `json:"reason"`
+	AppliedAt time.Time `json:"applied_at"`
+	Config    string    `json:"config,omitempty"` // YAML格式的完整配置，列表中不返回
 }
 
 // TokenInfo 代币信息
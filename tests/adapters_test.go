@@ -254,14 +254,6 @@ func TestPumpSwapAdapter(t *testing.T) {
 
 // TestBaseAdapterValidation 测试基础适配器验证功能
 func TestBaseAdapterValidation(t *testing.T) {
-	config := &config.DEXConfig{
-		Name:       "test",
-		ProgramID:  "11111111111111111111111111111112",
-		Enabled:    true,
-		Timeout:    30 * time.Second,
-		RetryCount: 3,
-	}
-
 	// 测试有效的交换请求验证
 	validSwapReq := &types.SwapRequest{
 		InputMint:  "So11111111111111111111111111111111111111112",
@@ -374,6 +366,55 @@ func TestBaseAdapterValidation(t *testing.T) {
 	}
 }
 
+// TestValidateSwapRequestAddresses 测试交换请求中的代币、钱包和手续费支付方地址及滑点范围校验
+func TestValidateSwapRequestAddresses(t *testing.T) {
+	adapter, err := adapters.NewRaydiumAdapter(&config.DEXConfig{
+		Name:       "raydium",
+		ProgramID:  "675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8",
+		Enabled:    true,
+		Timeout:    30 * time.Second,
+		RetryCount: 3,
+	})
+	require.NoError(t, err)
+
+	newRequest := func() *types.SwapRequest {
+		return &types.SwapRequest{
+			InputMint:  "So11111111111111111111111111111111111111112",
+			OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+			AmountIn:   1000000000,
+			Slippage:   0.005,
+			UserWallet: "11111111111111111111111111111112",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(req *types.SwapRequest)
+		wantErr string
+	}{
+		{name: "valid request", modify: func(req *types.SwapRequest) {}},
+		{name: "full slippage", modify: func(req *types.SwapRequest) { req.Slippage = 1 }},
+		{name: "negative slippage", modify: func(req *types.SwapRequest) { req.Slippage = -0.1 }, wantErr: "slippage must be between 0 and 1"},
+		{name: "invalid output mint", modify: func(req *types.SwapRequest) { req.OutputMint = "not-a-mint" }, wantErr: "invalid output mint address"},
+		{name: "invalid user wallet", modify: func(req *types.SwapRequest) { req.UserWallet = "not-a-wallet" }, wantErr: "invalid user wallet address"},
+		{name: "invalid fee payer", modify: func(req *types.SwapRequest) { req.FeePayer = "not-a-payer" }, wantErr: "invalid fee payer address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newRequest()
+			tt.modify(req)
+			err := adapter.ValidateRequest(req)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
 // TestInvalidAdapterConfigs 测试无效的适配器配置
 func TestInvalidAdapterConfigs(t *testing.T) {
 	// 测试无效的程序ID
@@ -0,0 +1,293 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAnchorIDL 新格式IDL：swap指令带discriminator、PDA池账户、固定地址账户和可选账户
+const testAnchorIDL = `{
+  "address": "11111111111111111111111111111111",
+  "metadata": {"name": "simple_amm", "version": "0.1.0", "spec": "0.1.0"},
+  "instructions": [
+    {
+      "name": "swap",
+      "discriminator": [248, 198, 158, 145, 225, 117, 135, 200],
+      "accounts": [
+        {"name": "user", "writable": true, "signer": true},
+        {"name": "pool", "writable": true, "pda": {"seeds": [
+          {"kind": "const", "value": [112, 111, 111, 108]},
+          {"kind": "account", "path": "input_mint"},
+          {"kind": "account", "path": "output_mint"}
+        ]}},
+        {"name": "pool_authority", "pda": {"seeds": [
+          {"kind": "const", "value": [97, 117, 116, 104]},
+          {"kind": "account", "path": "pool"}
+        ]}},
+        {"name": "input_mint"},
+        {"name": "output_mint"},
+        {"name": "user_input", "writable": true},
+        {"name": "user_output", "writable": true},
+        {"name": "referrer", "optional": true},
+        {"name": "token_program", "address": "TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA"}
+      ],
+      "args": [
+        {"name": "amount_in", "type": "u64"},
+        {"name": "minimum_amount_out", "type": "u64"},
+        {"name": "fee_tier", "type": "u16"},
+        {"name": "deadline", "type": {"option": "i64"}}
+      ]
+    }
+  ]
+}`
+
+// testLegacyAnchorIDL 旧格式IDL：camelCase名称、isMut/isSigner、字符串const种子，没有discriminator
+const testLegacyAnchorIDL = `{
+  "version": "0.1.0",
+  "name": "legacy_amm",
+  "instructions": [
+    {
+      "name": "addLiquidity",
+      "accounts": [
+        {"name": "owner", "isMut": false, "isSigner": true},
+        {"name": "pool", "isMut": true, "isSigner": false, "pda": {"seeds": [
+          {"kind": "const", "type": "string", "value": "pool"},
+          {"kind": "account", "type": "publicKey", "path": "mintA"},
+          {"kind": "account", "type": "publicKey", "path": "mintB"}
+        ]}},
+        {"name": "mintA", "isMut": false, "isSigner": false},
+        {"name": "mintB", "isMut": false, "isSigner": false},
+        {"name": "tokenProgram", "isMut": false, "isSigner": false}
+      ],
+      "args": [
+        {"name": "maxAmountA", "type": "u64"},
+        {"name": "maxAmountB", "type": "u64"},
+        {"name": "slippageBps", "type": "u16"}
+      ]
+    }
+  ]
+}`
+
+func writeTestIDL(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "idl.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func newAnchorDEXConfig(idlPath string, instructions map[string]config.AnchorInstructionConfig) *config.DEXConfig {
+	return &config.DEXConfig{
+		Name:         "simple-amm",
+		Type:         "anchor",
+		ProgramID:    "9W959DqEETiGZocYWCQPaJ6sBmUzgfxXfqGeTEdp3aQP",
+		IDLPath:      idlPath,
+		Instructions: instructions,
+		Enabled:      true,
+		Timeout:      time.Second,
+		RetryCount:   1,
+	}
+}
+
+// TestAnchorAdapterSwap 测试按IDL和映射构建交换指令：discriminator、Borsh参数、PDA推导、固定地址和可选账户
+func TestAnchorAdapterSwap(t *testing.T) {
+	dexCfg := newAnchorDEXConfig(writeTestIDL(t, testAnchorIDL), map[string]config.AnchorInstructionConfig{
+		"swap": {
+			Instruction: "swap",
+			Args: map[string]string{
+				"amount_in":          "amount_in",
+				"minimum_amount_out": "min_amount_out",
+				"fee_tier":           "const:30",
+			},
+			Accounts: map[string]string{
+				"input_mint":  "input_mint",
+				"output_mint": "output_mint",
+				"user_input":  "user_input_ata",
+				"user_output": "user_output_ata",
+			},
+		},
+	})
+	cfg := createTestConfig()
+	cfg.DEXes = append(cfg.DEXes, *dexCfg)
+	require.NoError(t, cfg.Validate())
+
+	adapter, err := adapters.NewAdapter(dexCfg)
+	require.NoError(t, err)
+	assert.Equal(t, "simple-amm", adapter.GetName())
+
+	user := solana.NewWallet().PublicKey()
+	ins, err := adapter.BuildSwapInstruction(&types.SwapRequest{
+		InputMint:    testSOLMint,
+		OutputMint:   testUSDCMint,
+		AmountIn:     1_000_000,
+		MinAmountOut: 990_000,
+		UserWallet:   user.String(),
+	})
+	require.NoError(t, err)
+
+	programID := solana.MustPublicKeyFromBase58(dexCfg.ProgramID)
+	assert.Equal(t, programID, ins.ProgramID)
+
+	// discriminator + amount_in(u64) + minimum_amount_out(u64) + fee_tier(u16) + deadline(None)
+	expected := []byte{248, 198, 158, 145, 225, 117, 135, 200}
+	expected = binary.LittleEndian.AppendUint64(expected, 1_000_000)
+	expected = binary.LittleEndian.AppendUint64(expected, 990_000)
+	expected = binary.LittleEndian.AppendUint16(expected, 30)
+	expected = append(expected, 0)
+	assert.Equal(t, expected, ins.Data)
+
+	inputMint := solana.MustPublicKeyFromBase58(testSOLMint)
+	outputMint := solana.MustPublicKeyFromBase58(testUSDCMint)
+	pool, _, err := solana.FindProgramAddress([][]byte{[]byte("pool"), inputMint.Bytes(), outputMint.Bytes()}, programID)
+	require.NoError(t, err)
+	authority, _, err := solana.FindProgramAddress([][]byte{[]byte("auth"), pool.Bytes()}, programID)
+	require.NoError(t, err)
+	userInput, _, _ := solana.FindAssociatedTokenAddress(user, inputMint)
+	userOutput, _, _ := solana.FindAssociatedTokenAddress(user, outputMint)
+
+	assert.Equal(t, []solana.AccountMeta{
+		{PublicKey: user, IsSigner: true, IsWritable: true},
+		{PublicKey: pool, IsWritable: true},
+		{PublicKey: authority},
+		{PublicKey: inputMint},
+		{PublicKey: outputMint},
+		{PublicKey: userInput, IsWritable: true},
+		{PublicKey: userOutput, IsWritable: true},
+		{PublicKey: programID},
+		{PublicKey: solana.TokenProgramID},
+	}, ins.Accounts)
+
+	// 按IDL解码
+	accounts := make([]types.DecodedAccount, len(ins.Accounts))
+	for i, account := range ins.Accounts {
+		accounts[i] = types.DecodedAccount{PublicKey: account.PublicKey.String()}
+	}
+	decoded, err := adapter.DecodeInstruction(ins.Data, accounts)
+	require.NoError(t, err)
+	assert.Equal(t, "swap", decoded.Name)
+	assert.Equal(t, uint64(1_000_000), decoded.Fields["amount_in"])
+	assert.Equal(t, uint64(990_000), decoded.Fields["minimum_amount_out"])
+	assert.Equal(t, uint64(30), decoded.Fields["fee_tier"])
+	assert.Nil(t, decoded.Fields["deadline"])
+	assert.Equal(t, "pool_authority", decoded.Accounts[2].Name)
+
+	// 未配置的操作不支持
+	_, err = adapter.BuildLiquidityInstruction(&types.LiquidityRequest{
+		TokenAMint: testSOLMint, TokenBMint: testUSDCMint, AmountA: 1, AmountB: 1,
+		UserWallet: user.String(), Operation: "add",
+	})
+	assert.ErrorContains(t, err, "no instruction mapping configured")
+}
+
+// TestAnchorAdapterLegacyIDL 测试旧格式IDL：按snake_case名称计算discriminator，解析字符串const种子
+func TestAnchorAdapterLegacyIDL(t *testing.T) {
+	dexCfg := newAnchorDEXConfig(writeTestIDL(t, testLegacyAnchorIDL), map[string]config.AnchorInstructionConfig{
+		"add_liquidity": {
+			Instruction: "add_liquidity",
+			Args: map[string]string{
+				"maxAmountA":  "amount_a",
+				"maxAmountB":  "amount_b",
+				"slippageBps": "slippage_bps",
+			},
+			Accounts: map[string]string{
+				"mintA":        "token_a_mint",
+				"mintB":        "token_b_mint",
+				"tokenProgram": "token_program",
+			},
+		},
+	})
+	adapter, err := adapters.NewAdapter(dexCfg)
+	require.NoError(t, err)
+
+	user := solana.NewWallet().PublicKey()
+	ins, err := adapter.BuildLiquidityInstruction(&types.LiquidityRequest{
+		TokenAMint: testSOLMint,
+		TokenBMint: testUSDCMint,
+		AmountA:    500,
+		AmountB:    700,
+		Slippage:   0.01,
+		UserWallet: user.String(),
+		Operation:  "add",
+	})
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte("global:add_liquidity"))
+	assert.Equal(t, sum[:8], ins.Data[:8])
+	assert.Equal(t, uint64(500), binary.LittleEndian.Uint64(ins.Data[8:16]))
+	assert.Equal(t, uint64(700), binary.LittleEndian.Uint64(ins.Data[16:24]))
+	assert.Equal(t, uint16(100), binary.LittleEndian.Uint16(ins.Data[24:26]))
+
+	programID := solana.MustPublicKeyFromBase58(dexCfg.ProgramID)
+	pool, _, err := solana.FindProgramAddress([][]byte{
+		[]byte("pool"),
+		solana.MustPublicKeyFromBase58(testSOLMint).Bytes(),
+		solana.MustPublicKeyFromBase58(testUSDCMint).Bytes(),
+	}, programID)
+	require.NoError(t, err)
+	require.Len(t, ins.Accounts, 5)
+	assert.Equal(t, solana.AccountMeta{PublicKey: user, IsSigner: true}, ins.Accounts[0])
+	assert.Equal(t, solana.AccountMeta{PublicKey: pool, IsWritable: true}, ins.Accounts[1])
+	assert.Equal(t, solana.TokenProgramID, ins.Accounts[4].PublicKey)
+}
+
+// TestAnchorAdapterInvalidMapping 测试映射无法满足IDL时创建适配器失败并给出原因
+func TestAnchorAdapterInvalidMapping(t *testing.T) {
+	idlPath := writeTestIDL(t, testAnchorIDL)
+	swap := func(args, accounts map[string]string) map[string]config.AnchorInstructionConfig {
+		return map[string]config.AnchorInstructionConfig{"swap": {Instruction: "swap", Args: args, Accounts: accounts}}
+	}
+	fullArgs := map[string]string{"amount_in": "amount_in", "minimum_amount_out": "min_amount_out", "fee_tier": "const:30"}
+	fullAccounts := map[string]string{"input_mint": "input_mint", "output_mint": "output_mint", "user_input": "user_input_ata", "user_output": "user_output_ata"}
+	without := func(m map[string]string, key string) map[string]string {
+		out := make(map[string]string)
+		for k, v := range m {
+			if k != key {
+				out[k] = v
+			}
+		}
+		return out
+	}
+	with := func(m map[string]string, key, value string) map[string]string {
+		out := without(m, key)
+		out[key] = value
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		instructions map[string]config.AnchorInstructionConfig
+		idlPath      string
+		err          string
+	}{
+		{"unknown instruction", map[string]config.AnchorInstructionConfig{"swap": {Instruction: "exchange"}}, idlPath, `instruction "exchange" not found`},
+		{"unmapped arg", swap(without(fullArgs, "fee_tier"), fullAccounts), idlPath, "arg fee_tier of instruction swap is not mapped"},
+		{"unknown arg source", swap(with(fullArgs, "amount_in", "amount_a"), fullAccounts), idlPath, `unknown source "amount_a"`},
+		{"invalid literal", swap(with(fullArgs, "fee_tier", "const:70000"), fullAccounts), idlPath, "invalid u16"},
+		{"unmapped account", swap(fullArgs, without(fullAccounts, "user_input")), idlPath, "account user_input of instruction swap is not mapped"},
+		{"unknown account", swap(fullArgs, with(fullAccounts, "vault", "user_wallet")), idlPath, `account "vault" not found`},
+		{"unsupported operation", map[string]config.AnchorInstructionConfig{"stake": {Instruction: "swap"}}, idlPath, "unsupported operation"},
+		{"missing IDL", swap(fullArgs, fullAccounts), filepath.Join(t.TempDir(), "missing.json"), "failed to read IDL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := adapters.NewAdapter(newAnchorDEXConfig(tt.idlPath, tt.instructions))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// 配置校验要求anchor类型提供IDL路径
+	cfg := createTestConfig()
+	cfg.DEXes = append(cfg.DEXes, *newAnchorDEXConfig("", swap(fullArgs, fullAccounts)))
+	assert.ErrorContains(t, cfg.Validate(), "idl_path is required")
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEncodeBatch 测试批量编码按顺序返回结果，失败条目不影响其他条目，所有交易共用一次获取的区块哈希
+func TestEncodeBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rpcServer := newBlockhashRPCServer(t)
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = rpcServer.URL
+	cfg.Batch.MaxItems = 5
+	cfg.Batch.Workers = 2
+
+	transactionHandler := handlers.NewTransactionHandler(services.NewTransactionService(cfg))
+	router := gin.New()
+	router.POST("/api/v1/encode/batch", transactionHandler.EncodeBatch)
+
+	user := solana.NewWallet().PublicKey().String()
+	swap := func(amount uint64) *types.SwapRequest {
+		return &types.SwapRequest{
+			DEXType:    "raydium",
+			InputMint:  testSOLMint,
+			OutputMint: testUSDCMint,
+			AmountIn:   amount,
+			Slippage:   0.01,
+			UserWallet: user,
+		}
+	}
+	batch := types.BatchEncodeRequest{Items: []types.BatchEncodeItem{
+		{Type: "swap", Swap: swap(1000)},
+		{Type: "swap", Swap: &types.SwapRequest{DEXType: "unknown", UserWallet: user}},
+		{Type: "liquidity", Liquidity: &types.LiquidityRequest{
+			DEXType:    "raydium",
+			Operation:  "add",
+			TokenAMint: testSOLMint,
+			TokenBMint: testUSDCMint,
+			AmountA:    1000,
+			AmountB:    2000,
+			Slippage:   0.01,
+			UserWallet: user,
+		}},
+		{Type: "transfer"},
+		{Type: "swap", Swap: swap(3000)},
+	}}
+
+	body, _ := json.Marshal(batch)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/batch", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.BatchEncodeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Results, 5)
+	assert.Equal(t, 3, resp.Succeeded)
+	assert.Equal(t, 2, resp.Failed)
+
+	for i, result := range resp.Results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, batch.Items[i].Type, result.Type)
+	}
+	assert.True(t, resp.Results[0].Success)
+	assert.Contains(t, resp.Results[1].Error, "DEX adapter not found")
+	assert.True(t, resp.Results[2].Success)
+	assert.Contains(t, resp.Results[3].Error, "unsupported item type")
+	assert.True(t, resp.Results[4].Success)
+
+	for _, i := range []int{0, 2, 4} {
+		require.NotNil(t, resp.Results[i].Response)
+		tx := decodeTestTransaction(t, resp.Results[i].Response.Transaction)
+		assert.Equal(t, resp.Blockhash, tx.Message.RecentBlockhash.String())
+		assert.Equal(t, resp.LastValidBlockHeight, resp.Results[i].Response.LastValidBlockHeight)
+		require.NotNil(t, resp.Results[i].Response.Preflight)
+		assert.Equal(t, 1, resp.Results[i].Response.Preflight.Signatures)
+	}
+	assert.Equal(t, 1, rpcServer.Calls("getLatestBlockhash"))
+
+	// 超过条目数上限
+	batch.Items = append(batch.Items, types.BatchEncodeItem{Type: "swap", Swap: swap(4000)})
+	body, _ = json.Marshal(batch)
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/encode/batch", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+
+	// 空批量请求
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/encode/batch", bytes.NewBufferString(`{"items":[]}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+}
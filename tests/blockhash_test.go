@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	bin "github.com/gagliardetto/binary"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockhashRPCServer 创建每次调用返回新区块哈希的模拟RPC节点
+func newBlockhashRPCServer(t *testing.T) *fakeRPCServer {
+	server := newFakeRPCServer(t)
+	var height uint64 = 1000
+	server.Handle("getLatestBlockhash", func(json.RawMessage) (interface{}, error) {
+		h := atomic.AddUint64(&height, 1)
+		return rpcContextResult(h, map[string]interface{}{
+			"blockhash":            solana.NewWallet().PublicKey().String(),
+			"lastValidBlockHeight": h + 150,
+		}), nil
+	})
+	return server
+}
+
+// TestBlockhashManager 测试区块哈希缓存与刷新
+func TestBlockhashManager(t *testing.T) {
+	server := newBlockhashRPCServer(t)
+	manager := services.NewBlockhashManager(newTestRPCPool(t, server.URL), "confirmed", time.Minute)
+
+	first, err := manager.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1151), first.LastValidBlockHeight)
+
+	// 缓存有效期内不再请求RPC
+	second, err := manager.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, first.Blockhash, second.Blockhash)
+	assert.Equal(t, 1, server.Calls("getLatestBlockhash"))
+
+	// 强制刷新获取新的区块哈希
+	refreshed, err := manager.Refresh(context.Background())
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Blockhash, refreshed.Blockhash)
+	assert.Equal(t, 2, server.Calls("getLatestBlockhash"))
+
+	// 后台刷新
+	manager = services.NewBlockhashManager(newTestRPCPool(t, server.URL), "confirmed", 20*time.Millisecond)
+	manager.Start()
+	time.Sleep(70 * time.Millisecond)
+	manager.Stop()
+	assert.GreaterOrEqual(t, server.Calls("getLatestBlockhash"), 4)
+	assert.Zero(t, server.Calls("getRecentBlockhash"))
+}
+
+// TestRefreshBlockhashAPI 测试交易区块哈希刷新接口
+func TestRefreshBlockhashAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newBlockhashRPCServer(t)
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = server.URL
+
+	transactionService := services.NewTransactionService(cfg)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	router := gin.New()
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	router.POST("/api/v1/tx/refresh-blockhash", transactionHandler.RefreshBlockhash)
+
+	wallet := solana.NewWallet()
+	swapReq := types.SwapRequest{
+		DEXType:    "raydium",
+		InputMint:  "So11111111111111111111111111111111111111112",
+		OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		AmountIn:   1000000000,
+		Slippage:   0.005,
+		UserWallet: wallet.PublicKey().String(),
+	}
+	body, _ := json.Marshal(swapReq)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var encoded types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &encoded))
+	assert.Equal(t, uint64(1151), encoded.LastValidBlockHeight)
+
+	// 刷新未签名交易的区块哈希
+	body, _ = json.Marshal(types.RefreshBlockhashRequest{Transaction: encoded.Transaction})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/tx/refresh-blockhash", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var refreshed types.RefreshBlockhashResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &refreshed))
+	assert.Equal(t, uint64(1152), refreshed.LastValidBlockHeight)
+
+	original := decodeTestTransaction(t, encoded.Transaction)
+	restamped := decodeTestTransaction(t, refreshed.Transaction)
+	assert.NotEqual(t, original.Message.RecentBlockhash, restamped.Message.RecentBlockhash)
+	assert.Equal(t, refreshed.Blockhash, restamped.Message.RecentBlockhash.String())
+	assert.Equal(t, original.Message.Instructions, restamped.Message.Instructions)
+
+	// 已签名的交易不能刷新
+	_, err := restamped.Sign(func(key solana.PublicKey) *solana.PrivateKey {
+		if key.Equals(wallet.PublicKey()) {
+			return &wallet.PrivateKey
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	signedData, err := restamped.MarshalBinary()
+	require.NoError(t, err)
+
+	body, _ = json.Marshal(types.RefreshBlockhashRequest{Transaction: base64.StdEncoding.EncodeToString(signedData)})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/tx/refresh-blockhash", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "already signed")
+}
+
+// decodeTestTransaction 解码Base64编码的交易
+func decodeTestTransaction(t *testing.T, encoded string) *solana.Transaction {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+
+	var tx solana.Transaction
+	require.NoError(t, bin.NewBorshDecoder(data).Decode(&tx))
+	return &tx
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCircuitBreaker 测试连续失败后熔断、冷却后放行探测请求、探测成功后恢复
+func TestCircuitBreaker(t *testing.T) {
+	var failing int32 = 1
+	var calls int32
+	server := newDEXAPIServer(t, nil, func(req quoteRequestBody) interface{} {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			return map[string]interface{}{"success": false, "error": "upstream unavailable"}
+		}
+		return map[string]interface{}{"success": true, "data": map[string]interface{}{"amountOut": req.Amount * 2}}
+	})
+
+	dexCfg := config.DEXConfig{
+		Name:       "pumpfun",
+		ProgramID:  "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",
+		Endpoints:  map[string]string{"quote": server.URL + "/quote"},
+		Enabled:    true,
+		Timeout:    5 * time.Second,
+		RetryCount: 1,
+	}
+	adapter, err := adapters.NewPumpfunAdapter(&dexCfg)
+	require.NoError(t, err)
+	breaker := adapters.WithBreaker(adapter, &config.BreakerConfig{
+		FailureThreshold: 3,
+		Cooldown:         200 * time.Millisecond,
+	}).(*adapters.BreakerAdapter)
+
+	assert.Equal(t, adapters.StatusOnline, breaker.Status())
+
+	// 连续失败未达到阈值时为degraded
+	_, err = breaker.GetQuote(context.Background(), "TokenMint", testSOLMint, 1000)
+	require.Error(t, err)
+	assert.Equal(t, adapters.StatusDegraded, breaker.Status())
+	for i := 0; i < 2; i++ {
+		_, err = breaker.GetQuote(context.Background(), "TokenMint", testSOLMint, 1000)
+		require.Error(t, err)
+	}
+
+	// 达到阈值后熔断，不再请求上游
+	assert.Equal(t, adapters.StatusOffline, breaker.Status())
+	status := breaker.Breaker()
+	assert.Equal(t, adapters.BreakerOpen, status.State)
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+	assert.Contains(t, status.LastError, "upstream unavailable")
+	require.NotNil(t, status.OpenedAt)
+
+	_, err = breaker.GetQuote(context.Background(), "TokenMint", testSOLMint, 1000)
+	assert.True(t, errors.Is(err, adapters.ErrCircuitOpen))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// 冷却后半开，探测失败重新熔断
+	time.Sleep(250 * time.Millisecond)
+	assert.Equal(t, adapters.BreakerHalfOpen, breaker.Breaker().State)
+	assert.Equal(t, adapters.StatusDegraded, breaker.Status())
+	_, err = breaker.GetQuote(context.Background(), "TokenMint", testSOLMint, 1000)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, adapters.ErrCircuitOpen))
+	assert.Equal(t, adapters.BreakerOpen, breaker.Breaker().State)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	// 上游恢复后探测成功，关闭熔断
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(250 * time.Millisecond)
+	quote, err := breaker.GetQuote(context.Background(), "TokenMint", testSOLMint, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2000), quote.AmountOut)
+	assert.Equal(t, adapters.BreakerClosed, breaker.Breaker().State)
+	assert.Equal(t, adapters.StatusOnline, breaker.Status())
+}
+
+// TestBreakerIgnoresCanceledRequests 测试调用方取消请求时立即返回，且不计入熔断失败
+func TestBreakerIgnoresCanceledRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"success":true,"data":{"amountOut":1}}`))
+	}))
+	defer server.Close()
+
+	dexCfg := config.DEXConfig{
+		Name:       "pumpfun",
+		ProgramID:  "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",
+		Endpoints:  map[string]string{"quote": server.URL + "/quote"},
+		Enabled:    true,
+		Timeout:    5 * time.Second,
+		RetryCount: 3,
+	}
+	adapter, err := adapters.NewPumpfunAdapter(&dexCfg)
+	require.NoError(t, err)
+	breaker := adapters.WithBreaker(adapter, &config.BreakerConfig{
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+	}).(*adapters.BreakerAdapter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = breaker.GetQuote(ctx, "TokenMint", testSOLMint, 1000)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, adapters.BreakerClosed, breaker.Breaker().State)
+	assert.Equal(t, 0, breaker.Breaker().ConsecutiveFailures)
+
+	// 已取消的上下文不再发起上游请求
+	_, err = breaker.GetQuote(ctx, "TokenMint", testSOLMint, 1000)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Equal(t, adapters.StatusOnline, breaker.Status())
+}
+
+// TestDEXStatusReflectsBreaker 测试DEX列表和状态接口反映熔断器状态
+func TestDEXStatusReflectsBreaker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newDEXAPIServer(t, nil, func(req quoteRequestBody) interface{} {
+		return map[string]interface{}{"success": false, "error": "upstream unavailable"}
+	})
+
+	cfg := createTestConfig()
+	cfg.Breaker = config.BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}
+	setQuoteEndpoint(cfg, "pumpfun", server.URL+"/quote")
+
+	transactionService := services.NewTransactionService(cfg)
+	dexService := services.NewDEXService(cfg)
+	dexService.SetTransactionService(transactionService)
+	router := newAPIRouter(transactionService, dexService, services.NewConfigService(cfg))
+
+	for i := 0; i < 2; i++ {
+		_, err := dexService.GetQuote(context.Background(), "pumpfun", "TokenMint", testSOLMint, 1000)
+		require.Error(t, err)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/dex/list", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var list struct {
+		Data []types.DEXInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	statuses := make(map[string]string)
+	for _, dex := range list.Data {
+		statuses[dex.Name] = dex.Status
+	}
+	assert.Equal(t, "offline", statuses["pumpfun"])
+	assert.Equal(t, "online", statuses["raydium"])
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/dex/pumpfun/status", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var status struct {
+		Data struct {
+			Status  string                  `json:"status"`
+			Breaker *adapters.BreakerStatus `json:"breaker"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "offline", status.Data.Status)
+	require.NotNil(t, status.Data.Breaker)
+	assert.Equal(t, "open", status.Data.Breaker.State)
+	assert.Equal(t, 2, status.Data.Breaker.ConsecutiveFailures)
+}
@@ -142,7 +142,7 @@ func TestConfigService(t *testing.T) {
 	// 测试添加DEX配置
 	newDEXConfig := config.DEXConfig{
 		Name:      "test-new-dex",
-		ProgramID: "22222222222222222222222222222222",
+		ProgramID: "9xQeWvG816bUx9EPjHmaT23yvVM2ZWbrrpZb9PusVFin",
 		Enabled:   true,
 		Timeout:   30 * time.Second,
 		RetryCount: 3,
@@ -208,6 +208,9 @@ func TestConfigDefaults(t *testing.T) {
 	assert.False(t, cfg.DEXes[0].CreatedAt.IsZero())
 	assert.False(t, cfg.DEXes[0].UpdatedAt.IsZero())
 
+	// 多跳路由需显式开启
+	assert.Equal(t, 1, cfg.Routing.MaxHops)
+
 	assert.Equal(t, "info", cfg.Logging.Level)
 	assert.Equal(t, "json", cfg.Logging.Format)
 	assert.Equal(t, "stdout", cfg.Logging.Output)
@@ -216,6 +219,27 @@ func TestConfigDefaults(t *testing.T) {
 	assert.Equal(t, int64(1024*1024), cfg.Security.MaxRequestSize)
 }
 
+// TestConfigPartialUpdateDefaults 测试部分修改配置时补全默认值，不会留下零值超时
+func TestConfigPartialUpdateDefaults(t *testing.T) {
+	_, configService, _, _ := newHotReloadServices(t)
+
+	server := *configService.GetServerConfig()
+	server.ReadTimeout = 0
+	server.QuoteTimeout = 0
+	require.NoError(t, configService.UpdateServerConfig(&server))
+
+	solanaCfg := *configService.GetSolanaConfig()
+	solanaCfg.Timeout = 0
+	solanaCfg.BlockhashRefreshInterval = 0
+	require.NoError(t, configService.UpdateSolanaConfig(&solanaCfg))
+
+	cfg := configService.GetConfig()
+	assert.Equal(t, 30*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, 10*time.Second, cfg.Server.QuoteTimeout)
+	assert.Equal(t, 30*time.Second, cfg.Solana.Timeout)
+	assert.Equal(t, 10*time.Second, cfg.Solana.BlockhashRefreshInterval)
+}
+
 // TestGetDEXConfig 测试获取DEX配置
 func TestGetDEXConfig(t *testing.T) {
 	cfg := &config.Config{
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigConcurrentUpdates 测试并发修改配置不会丢失更新，读取到的快照不受后续修改影响
+func TestConfigConcurrentUpdates(t *testing.T) {
+	configPath, configService, _, _ := newHotReloadServices(t)
+	before := configService.GetConfig()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, configService.AddDEXConfig(config.DEXConfig{
+				Name:      fmt.Sprintf("dex-%d", i),
+				ProgramID: "675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8",
+				Endpoints: map[string]string{"api": "https://example.com"},
+			}))
+		}(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, dex := range configService.GetDEXConfig() {
+				_ = dex.Endpoints["api"]
+			}
+			configService.GetConfigSummary()
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, before.DEXes, 2)
+	assert.Len(t, configService.GetDEXConfig(), 22)
+
+	saved, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	assert.Len(t, saved.DEXes, 22)
+
+	// 原子写入不留下临时文件
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+// TestConfigUpdateIfMatch 测试通过 If-Match 防止覆盖其他请求的配置修改
+func TestConfigUpdateIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, configService, _, _ := newHotReloadServices(t)
+	configHandler := handlers.NewConfigHandler(configService)
+	router := gin.New()
+	router.GET("/api/v1/config", configHandler.GetConfig)
+	router.PUT("/api/v1/config", configHandler.UpdateConfig)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/config", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	var getResp struct {
+		Data config.Config `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &getResp))
+
+	put := func(level, ifMatch string) *httptest.ResponseRecorder {
+		cfg := getResp.Data
+		cfg.Logging.Level = level
+		body, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		req, _ := http.NewRequest("PUT", "/api/v1/config", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 第一个管理员基于获取到的版本修改成功
+	w = put("debug", etag)
+	require.Equal(t, http.StatusOK, w.Code)
+	newETag := w.Header().Get("ETag")
+	assert.NotEqual(t, etag, newETag)
+	assert.Equal(t, "debug", configService.GetConfig().Logging.Level)
+
+	// 第二个管理员基于旧版本的修改被拒绝
+	w = put("warn", etag)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Equal(t, "debug", configService.GetConfig().Logging.Level)
+
+	// 使用新版本或不带 If-Match 时可以修改
+	assert.Equal(t, http.StatusOK, put("warn", newETag).Code)
+	assert.Equal(t, http.StatusOK, put("error", "*").Code)
+	assert.Equal(t, "error", configService.GetConfig().Logging.Level)
+}
+
+// TestConfigDEXUpdateIfMatch 测试修改DEX配置的接口同样检查 If-Match
+func TestConfigDEXUpdateIfMatch(t *testing.T) {
+	_, configService, transactionService, dexService := newHotReloadServices(t)
+	router := newAPIRouter(transactionService, dexService, configService)
+
+	send := func(method, path, ifMatch string, payload interface{}) *httptest.ResponseRecorder {
+		var body []byte
+		if payload != nil {
+			var err error
+			body, err = json.Marshal(payload)
+			require.NoError(t, err)
+		}
+		req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("GET", "/api/v1/config/", "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// 基于获取到的版本修改成功
+	require.Equal(t, http.StatusOK, send("POST", "/api/v1/config/dex/pumpfun/disable", etag, nil).Code, etag)
+	_, version := configService.Snapshot()
+	require.NotEqual(t, etag, strconv.Quote(version))
+
+	// 基于旧版本的DEX修改全部被拒绝，配置保持不变
+	dexes := configService.GetDEXConfig()
+	newDEX := dexes[0]
+	newDEX.Name = "raydium-clmm"
+	for _, tc := range []struct {
+		method, path string
+		payload      interface{}
+	}{
+		{"PUT", "/api/v1/config/dex", dexes},
+		{"POST", "/api/v1/config/dex", newDEX},
+		{"DELETE", "/api/v1/config/dex/raydium", nil},
+		{"POST", "/api/v1/config/dex/pumpfun/enable", nil},
+		{"POST", "/api/v1/config/dex/raydium/disable", nil},
+	} {
+		w := send(tc.method, tc.path, etag, tc.payload)
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code, "%s %s", tc.method, tc.path)
+	}
+	_, after := configService.Snapshot()
+	assert.Equal(t, version, after)
+
+	// 使用新版本或不带 If-Match 时可以修改
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/config/dex/pumpfun/enable", strconv.Quote(version), nil).Code)
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/config/dex/raydium/disable", "", nil).Code)
+}
+
+// TestConfigChangeWhileServing 测试配置变更与读取配置的请求并发执行（配合 -race 运行）
+func TestConfigChangeWhileServing(t *testing.T) {
+	_, configService, transactionService, dexService := newHotReloadServices(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				assert.NoError(t, configService.DisableDEX("pumpfun"))
+			} else {
+				assert.NoError(t, configService.EnableDEX("pumpfun"))
+			}
+		}(i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := dexService.ListDEXes()
+			assert.NoError(t, err)
+			_, err = dexService.GetDEX("raydium")
+			assert.NoError(t, err)
+			transactionService.RouteQuote(ctx, "", "", 0, 0)
+			transactionService.SplitQuote(ctx, "", "", 0, 0)
+			transactionService.EncodeBatch(ctx, &types.BatchEncodeRequest{})
+		}()
+	}
+	wg.Wait()
+
+	_, err := transactionService.GetDEXAdapter("raydium")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sourceOf 查找字段的来源
+func sourceOf(t *testing.T, sources []config.ValueSource, path string) config.ValueSource {
+	for _, source := range sources {
+		if source.Path == path {
+			return source
+		}
+	}
+	t.Fatalf("no source for %s", path)
+	return config.ValueSource{}
+}
+
+// TestConfigEnvOverrides 测试环境变量和 _FILE 文件覆盖配置文件，保存配置时不写入覆盖的值
+func TestConfigEnvOverrides(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "rpc_url")
+	require.NoError(t, os.WriteFile(secretPath, []byte("https://rpc.example.com/?api-key=secret\n"), 0600))
+	t.Setenv("DEX_SERVER_PORT", "9090")
+	t.Setenv("DEX_DEXES_RAYDIUM_ENABLED", "false")
+	t.Setenv("DEX_SOLANA_RPC_URL", "https://ignored.example.com")
+	t.Setenv("DEX_SOLANA_RPC_URL_FILE", secretPath)
+
+	configPath, configService, transactionService, _ := newHotReloadServices(t)
+	cfg := configService.GetConfig()
+	assert.Equal(t, 9090, cfg.Server.Port)
+	assert.Equal(t, "https://rpc.example.com/?api-key=secret", cfg.Solana.RPCURL)
+	_, err := transactionService.GetDEXAdapter("raydium")
+	assert.Error(t, err)
+
+	sources := configService.GetConfigSources()
+	assert.Equal(t, config.ValueSource{Path: "server.port", Source: config.SourceEnv, Origin: "DEX_SERVER_PORT", EnvVar: "DEX_SERVER_PORT"}, sourceOf(t, sources, "server.port"))
+	assert.Equal(t, config.SourceSecretFile, sourceOf(t, sources, "solana.rpc_url").Source)
+	assert.Equal(t, secretPath, sourceOf(t, sources, "solana.rpc_url").Origin)
+	assert.Equal(t, config.SourceEnv, sourceOf(t, sources, "dexes[raydium].enabled").Source)
+	assert.Equal(t, config.SourceFile, sourceOf(t, sources, "server.host").Source)
+	assert.Equal(t, config.SourceDefault, sourceOf(t, sources, "server.quote_timeout").Source)
+	assert.Equal(t, "DEX_DEXES_RAYDIUM_ENDPOINTS_SWAP", sourceOf(t, sources, "dexes[raydium].endpoints.swap").EnvVar)
+
+	// 通过接口修改的字段记为runtime，写入文件时覆盖的字段保留配置文件中的值
+	require.NoError(t, configService.DisableDEX("pumpfun"))
+	assert.Equal(t, config.SourceRuntime, sourceOf(t, configService.GetConfigSources(), "dexes[pumpfun].enabled").Source)
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	saved, err := config.LoadConfigWithEnv(configPath, func(string) (string, bool) { return "", false })
+	require.NoError(t, err)
+	assert.Equal(t, 8080, saved.Server.Port)
+	assert.Equal(t, "https://api.mainnet-beta.solana.com", saved.Solana.RPCURL)
+	for _, dex := range saved.DEXes {
+		assert.Equal(t, dex.Name == "raydium", dex.Enabled, dex.Name)
+	}
+}
+
+// TestConfigEnvInvalidValue 测试无法解析的环境变量和缺失的 _FILE 文件导致加载失败
+func TestConfigEnvInvalidValue(t *testing.T) {
+	tempConfigFile := createTempConfigFile(t)
+	defer os.Remove(tempConfigFile)
+
+	env := map[string]string{"DEX_SERVER_PORT": "abc"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	_, err := config.LoadConfigWithEnv(tempConfigFile, lookup)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DEX_SERVER_PORT")
+
+	env = map[string]string{"DEX_SOLANA_RPC_URL_FILE": filepath.Join(t.TempDir(), "missing")}
+	_, err = config.LoadConfigWithEnv(tempConfigFile, lookup)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DEX_SOLANA_RPC_URL_FILE")
+}
+
+// TestConfigSourcesAPI 测试配置来源接口
+func TestConfigSourcesAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DEX_LOGGING_LEVEL", "debug")
+
+	_, configService, _, _ := newHotReloadServices(t)
+	configHandler := handlers.NewConfigHandler(configService)
+	router := gin.New()
+	router.GET("/api/v1/config/sources", configHandler.GetConfigSources)
+
+	req, _ := http.NewRequest("GET", "/api/v1/config/sources", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Success bool                 `json:"success"`
+		Data    []config.ValueSource `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.Equal(t, config.SourceEnv, sourceOf(t, resp.Data, "logging.level").Source)
+	assert.Equal(t, config.SourceFile, sourceOf(t, resp.Data, "logging.format").Source)
+}
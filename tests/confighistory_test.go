@@ -0,0 +1,159 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/store"
+	"solana-dex-service/internal/types"
+	pkgtypes "solana-dex-service/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigHistory 测试配置变更记录为版本，并按字段比较差异
+func TestConfigHistory(t *testing.T) {
+	_, configService, _, _ := newHotReloadServices(t)
+	historyStore, err := store.NewBoltConfigHistoryStore(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	defer historyStore.Close()
+	configService.SetHistoryStore(historyStore)
+
+	require.NoError(t, configService.DisableDEX("pumpfun", services.ChangeInfo{Author: "alice", Reason: "maintenance"}))
+	require.NoError(t, configService.EnableDEX("pumpfun"))
+
+	history, err := configService.GetHistory()
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, 3, history[0].Version)
+	assert.Equal(t, "system", history[0].Author)
+	assert.Equal(t, "enable dex pumpfun", history[0].Reason)
+	assert.Equal(t, "alice", history[1].Author)
+	assert.Equal(t, "maintenance", history[1].Reason)
+	assert.Equal(t, "startup", history[2].Reason)
+	assert.Empty(t, history[0].Config)
+
+	diff, err := configService.DiffVersion(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, diff.From)
+	assert.Contains(t, diff.Changes, types.ConfigChange{
+		Path: "dexes[pumpfun].enabled",
+		Type: types.ConfigChangeChanged,
+		Old:  true,
+		New:  false,
+	})
+	for _, change := range diff.Changes {
+		assert.NotContains(t, change.Path, "dexes[raydium]")
+	}
+
+	// 第一个版本与空配置比较，新增的配置节整体作为一处差异
+	diff, err = configService.DiffVersion(1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, diff.From)
+	var server *types.ConfigChange
+	for i := range diff.Changes {
+		if diff.Changes[i].Path == "server" {
+			server = &diff.Changes[i]
+		}
+	}
+	require.NotNil(t, server)
+	assert.Equal(t, types.ConfigChangeAdded, server.Type)
+	assert.Equal(t, 8080, server.New.(map[string]interface{})["port"])
+
+	// 配置未变化时重新打开存储不记录新版本
+	other := services.NewConfigService(configService.GetConfig())
+	other.SetHistoryStore(historyStore)
+	history, err = other.GetHistory()
+	require.NoError(t, err)
+	assert.Len(t, history, 3)
+}
+
+// TestConfigRollbackAPI 测试通过接口查看历史、比较差异和回滚配置
+func TestConfigRollbackAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	configPath, configService, transactionService, _ := newHotReloadServices(t)
+	historyStore, err := store.NewBoltConfigHistoryStore(filepath.Join(t.TempDir(), "history.db"))
+	require.NoError(t, err)
+	defer historyStore.Close()
+	configService.SetHistoryStore(historyStore)
+
+	configHandler := handlers.NewConfigHandler(configService)
+	router := gin.New()
+	router.POST("/api/v1/config/dex/:name/disable", configHandler.DisableDEX)
+	router.GET("/api/v1/config/history", configHandler.GetConfigHistory)
+	router.GET("/api/v1/config/history/:v/diff", configHandler.GetConfigDiff)
+	router.POST("/api/v1/config/rollback/:v", configHandler.RollbackConfig)
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("X-Config-Author", "bob")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request("POST", "/api/v1/config/dex/pumpfun/disable")
+	require.Equal(t, http.StatusOK, w.Code)
+	_, err = transactionService.GetDEXAdapter("pumpfun")
+	require.Error(t, err)
+
+	w = request("GET", "/api/v1/config/history")
+	require.Equal(t, http.StatusOK, w.Code)
+	var historyResp struct {
+		Data []pkgtypes.ConfigVersion `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &historyResp))
+	require.Len(t, historyResp.Data, 2)
+	assert.Equal(t, "bob", historyResp.Data[0].Author)
+	assert.Equal(t, "disable dex pumpfun", historyResp.Data[0].Reason)
+
+	w = request("GET", "/api/v1/config/history/2/diff?against=1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var diffResp struct {
+		Data types.ConfigDiff `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diffResp))
+	assert.Contains(t, diffResp.Data.Changes, types.ConfigChange{
+		Path: "dexes[pumpfun].enabled",
+		Type: types.ConfigChangeChanged,
+		Old:  true,
+		New:  false,
+	})
+
+	// 回滚到第一个版本，记录为新版本并立即生效
+	w = request("POST", "/api/v1/config/rollback/1")
+	require.Equal(t, http.StatusOK, w.Code)
+	var rollbackResp struct {
+		Data struct {
+			Version int `json:"version"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rollbackResp))
+	assert.Equal(t, 3, rollbackResp.Data.Version)
+	_, err = transactionService.GetDEXAdapter("pumpfun")
+	assert.NoError(t, err)
+
+	saved, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+	for _, dex := range saved.DEXes {
+		if dex.Name == "pumpfun" {
+			assert.True(t, dex.Enabled)
+		}
+	}
+	latest, err := historyStore.Latest()
+	require.NoError(t, err)
+	assert.Equal(t, "rollback to version 1", latest.Reason)
+
+	assert.Equal(t, http.StatusNotFound, request("GET", "/api/v1/config/history/99/diff").Code)
+	assert.Equal(t, http.StatusNotFound, request("POST", "/api/v1/config/rollback/99").Code)
+	assert.Equal(t, http.StatusBadRequest, request("POST", "/api/v1/config/rollback/abc").Code)
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHotReloadServices 创建使用独立配置文件、订阅配置变更的服务
+func newHotReloadServices(t *testing.T) (string, *services.ConfigService, *services.TransactionService, *services.DEXService) {
+	tempConfigFile := createTempConfigFile(t)
+	defer os.Remove(tempConfigFile)
+	data, err := os.ReadFile(tempConfigFile)
+	require.NoError(t, err)
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	cfg, err := config.LoadConfig(configPath)
+	require.NoError(t, err)
+
+	transactionService := services.NewTransactionService(cfg)
+	dexService := services.NewDEXService(cfg)
+	dexService.SetTransactionService(transactionService)
+	configService := services.NewConfigService(cfg)
+	configService.SetConfigPath(configPath)
+	configService.OnChange(transactionService.ApplyConfig)
+	configService.OnChange(dexService.ApplyConfig)
+	return configPath, configService, transactionService, dexService
+}
+
+// TestConfigChangeRebuildsAdapters 测试通过配置服务禁用、启用和修改DEX后适配器注册表随之更新
+func TestConfigChangeRebuildsAdapters(t *testing.T) {
+	_, configService, transactionService, dexService := newHotReloadServices(t)
+
+	raydium, err := transactionService.GetDEXAdapter("raydium")
+	require.NoError(t, err)
+	_, err = transactionService.GetDEXAdapter("pumpfun")
+	require.NoError(t, err)
+
+	require.NoError(t, configService.DisableDEX("pumpfun"))
+	_, err = transactionService.GetDEXAdapter("pumpfun")
+	assert.Error(t, err)
+	assert.NotContains(t, transactionService.GetSupportedDEXes(), "pumpfun")
+	dex, err := dexService.GetDEX("raydium")
+	require.NoError(t, err)
+	assert.True(t, dex.Enabled)
+
+	// 配置未变化的DEX复用原适配器
+	unchanged, err := transactionService.GetDEXAdapter("raydium")
+	require.NoError(t, err)
+	assert.Same(t, raydium, unchanged)
+
+	require.NoError(t, configService.EnableDEX("pumpfun"))
+	_, err = transactionService.GetDEXAdapter("pumpfun")
+	assert.NoError(t, err)
+
+	// 修改DEX配置后重新创建适配器
+	dexConfigs := append([]config.DEXConfig(nil), configService.GetDEXConfig()...)
+	for i := range dexConfigs {
+		if dexConfigs[i].Name == "raydium" {
+			dexConfigs[i].Timeout = 10 * time.Second
+		}
+	}
+	require.NoError(t, configService.UpdateDEXConfig(dexConfigs))
+	updated, err := transactionService.GetDEXAdapter("raydium")
+	require.NoError(t, err)
+	assert.NotSame(t, raydium, updated)
+	assert.Equal(t, 10*time.Second, updated.GetConfig().Timeout)
+}
+
+// TestConfigFileWatcher 测试手动修改配置文件后自动重新加载，无效的修改保留当前配置
+func TestConfigFileWatcher(t *testing.T) {
+	configPath, configService, transactionService, _ := newHotReloadServices(t)
+	require.NoError(t, configService.StartWatcher())
+	defer configService.StopWatcher()
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	// 禁用pumpfun
+	pumpfun := strings.Index(string(data), `name: "pumpfun"`)
+	require.Greater(t, pumpfun, 0)
+	edited := string(data[:pumpfun]) + strings.Replace(string(data[pumpfun:]), "enabled: true", "enabled: false", 1)
+	require.NoError(t, os.WriteFile(configPath, []byte(edited), 0644))
+
+	assert.Eventually(t, func() bool {
+		_, err := transactionService.GetDEXAdapter("pumpfun")
+		return err != nil
+	}, 3*time.Second, 20*time.Millisecond)
+
+	// 无法解析的配置文件不生效
+	require.NoError(t, os.WriteFile(configPath, []byte("server: ["), 0644))
+	time.Sleep(500 * time.Millisecond)
+	_, err = transactionService.GetDEXAdapter("raydium")
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, configService.GetConfig().Server.Port)
+
+	// 通过服务写入的配置不会触发重复加载，恢复后DEX重新启用
+	require.NoError(t, configService.EnableDEX("pumpfun"))
+	_, err = transactionService.GetDEXAdapter("pumpfun")
+	assert.NoError(t, err)
+}
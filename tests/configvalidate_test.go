@@ -0,0 +1,226 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestKeyPair 生成自签名证书和私钥文件
+func writeTestKeyPair(t *testing.T, dir, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+// errorPaths 列出验证错误的字段路径
+func errorPaths(errs config.ValidationErrors) []string {
+	paths := make([]string, len(errs))
+	for i, err := range errs {
+		paths[i] = err.Path
+	}
+	return paths
+}
+
+// TestConfigValidateAll 测试深度验证收集所有问题并给出字段路径
+func TestConfigValidateAll(t *testing.T) {
+	tempConfigFile := createTempConfigFile(t)
+	defer os.Remove(tempConfigFile)
+	cfg, err := config.LoadConfig(tempConfigFile)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.ValidateAll())
+
+	cfg.Server.Port = 0
+	cfg.Server.Mode = "production"
+	cfg.Solana.RPCURL = "api.mainnet-beta.solana.com"
+	cfg.Solana.WSURL = "https://api.mainnet-beta.solana.com"
+	cfg.Solana.Network = "devnet"
+	cfg.Solana.Commitment = "fast"
+	cfg.DEXes[1].ProgramID = "not-a-program-id"
+	cfg.DEXes[1].RouterAddress = "0OIl"
+	cfg.DEXes[1].Endpoints["api"] = "://pumpportal.fun"
+	cfg.DEXes = append(cfg.DEXes, cfg.DEXes[0])
+	cfg.Security.EnableHTTPS = true
+
+	errs := cfg.ValidateAll()
+	assert.ElementsMatch(t, []string{
+		"server.port",
+		"server.mode",
+		"solana.rpc_url",
+		"solana.ws_url",
+		"solana.commitment",
+		"dexes[0].program_id", // Raydium主网程序ID与devnet不匹配
+		"dexes[1].program_id",
+		"dexes[1].router_address",
+		"dexes[1].endpoints.api",
+		"dexes[2].name",
+		"dexes[2].program_id",
+		"security.cert_file",
+		"security.key_file",
+	}, errorPaths(errs))
+	assert.Contains(t, errs[0].Error(), "server.port must be between 1 and 65535")
+
+	// Validate返回同样的错误列表
+	var validationErrors config.ValidationErrors
+	require.True(t, errors.As(cfg.Validate(), &validationErrors))
+	assert.Len(t, validationErrors, len(errs))
+
+	// 路由参数为0时使用默认值，超出范围时错误信息说明允许的取值
+	cfg, err = config.LoadConfig(tempConfigFile)
+	require.NoError(t, err)
+	cfg.Routing.MaxHops = 0
+	assert.Empty(t, cfg.ValidateAll())
+	cfg.Routing.MaxHops = 4
+	errs = cfg.ValidateAll()
+	require.Equal(t, []string{"routing.max_hops"}, errorPaths(errs))
+	assert.Equal(t, "routing.max_hops must be between 1 and 3, or 0 for the default, got 4", errs[0].Error())
+}
+
+// TestConfigValidateTLS 测试启用HTTPS时检查证书和私钥
+func TestConfigValidateTLS(t *testing.T) {
+	tempConfigFile := createTempConfigFile(t)
+	defer os.Remove(tempConfigFile)
+	cfg, err := config.LoadConfig(tempConfigFile)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile := writeTestKeyPair(t, dir, "server")
+	_, otherKeyFile := writeTestKeyPair(t, dir, "other")
+
+	cfg.Security.EnableHTTPS = true
+	cfg.Security.CertFile = certFile
+	cfg.Security.KeyFile = keyFile
+	assert.Empty(t, cfg.ValidateAll())
+
+	cfg.Security.KeyFile = otherKeyFile
+	assert.Equal(t, []string{"security.cert_file"}, errorPaths(cfg.ValidateAll()))
+
+	cfg.Security.KeyFile = dir
+	assert.Equal(t, []string{"security.key_file"}, errorPaths(cfg.ValidateAll()))
+}
+
+// TestConfigValidateAPI 测试验证接口：验证当前配置或请求体中的候选配置
+func TestConfigValidateAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, configService, transactionService, dexService := newHotReloadServices(t)
+	router := newAPIRouter(transactionService, dexService, configService)
+
+	send := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// 没有请求体时验证当前配置
+	assert.Equal(t, http.StatusOK, send("POST", "/api/v1/config/validate", nil).Code)
+
+	candidate := configService.GetConfig().Clone()
+	candidate.Solana.Commitment = "fast"
+	candidate.DEXes[0].Endpoints["swap"] = "ftp://api.raydium.io"
+	body, err := json.Marshal(candidate)
+	require.NoError(t, err)
+
+	w := send("POST", "/api/v1/config/validate", body)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var resp struct {
+		ValidationErrors config.ValidationErrors `json:"validation_errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.ElementsMatch(t, []string{"solana.commitment", "dexes[0].endpoints.swap"}, errorPaths(resp.ValidationErrors))
+
+	// 验证不会应用候选配置，提交同样的配置时返回相同的字段错误
+	assert.Equal(t, "confirmed", configService.GetConfig().Solana.Commitment)
+	w = send("PUT", "/api/v1/config/", body)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.ValidationErrors, 2)
+}
+
+// TestConfigPartialUpdateValidation 测试修改部分配置时同样验证完整配置
+func TestConfigPartialUpdateValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_, configService, _, _ := newHotReloadServices(t)
+	configHandler := handlers.NewConfigHandler(configService)
+	router := gin.New()
+	router.POST("/api/v1/config/dex", configHandler.AddDEXConfig)
+	router.PUT("/api/v1/config/server", configHandler.UpdateServerConfig)
+	router.PUT("/api/v1/config/solana", configHandler.UpdateSolanaConfig)
+
+	send := func(method, path string, payload interface{}) (*httptest.ResponseRecorder, config.ValidationErrors) {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp struct {
+			ValidationErrors config.ValidationErrors `json:"validation_errors"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.ValidationErrors
+	}
+	before, version := configService.Snapshot()
+
+	w, errs := send("POST", "/api/v1/config/dex", config.DEXConfig{
+		Name:      "bad-dex",
+		ProgramID: "not-a-key",
+		Endpoints: map[string]string{"api": "ftp://example.com"},
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.ElementsMatch(t, []string{"dexes[2].program_id", "dexes[2].endpoints.api"}, errorPaths(errs))
+
+	server := before.Server
+	server.Mode = "verbose"
+	w, errs = send("PUT", "/api/v1/config/server", server)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []string{"server.mode"}, errorPaths(errs))
+
+	solanaCfg := before.Solana
+	solanaCfg.Network = "localnet"
+	w, errs = send("PUT", "/api/v1/config/solana", solanaCfg)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, []string{"solana.network"}, errorPaths(errs))
+
+	// 未通过验证的修改不会生效
+	_, after := configService.Snapshot()
+	assert.Equal(t, version, after)
+}
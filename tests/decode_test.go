@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeViaAPI 调用交易解码接口
+func decodeViaAPI(t *testing.T, router *gin.Engine, encoded string) (*httptest.ResponseRecorder, types.DecodeTransactionResponse) {
+	body, _ := json.Marshal(types.DecodeTransactionRequest{Transaction: encoded})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/tx/decode", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	var resp types.DecodeTransactionResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+// TestDecodeEncodedSwap 测试解码服务编码的交换交易
+func TestDecodeEncodedSwap(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newBlockhashRPCServer(t)
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = server.URL
+	transactionService := services.NewTransactionService(cfg)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	router := gin.New()
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	router.POST("/api/v1/tx/decode", transactionHandler.DecodeTransaction)
+
+	user := solana.NewWallet()
+	relayer := solana.NewWallet()
+	mint := solana.NewWallet().PublicKey()
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:     "pumpfun",
+		InputMint:   "So11111111111111111111111111111111111111112",
+		OutputMint:  mint.String(),
+		AmountIn:    500000000,
+		Slippage:    0.1,
+		PriorityFee: 25000,
+		UserWallet:  user.PublicKey().String(),
+		FeePayer:    relayer.PublicKey().String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var encoded types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &encoded))
+
+	w, decoded := decodeViaAPI(t, router, encoded.Transaction)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	tx := decodeTestTransaction(t, encoded.Transaction)
+	assert.Equal(t, "legacy", decoded.Version)
+	assert.Equal(t, relayer.PublicKey().String(), decoded.FeePayer)
+	assert.Equal(t, tx.Message.RecentBlockhash.String(), decoded.RecentBlockhash)
+	require.Len(t, decoded.Signers, 2)
+	assert.Equal(t, user.PublicKey().String(), decoded.Signers[1].PublicKey)
+	assert.False(t, decoded.Signers[0].Signed)
+	assert.Equal(t, uint64(25000), decoded.ComputeBudget.UnitPrice)
+
+	require.Len(t, decoded.Instructions, 2)
+	assert.Equal(t, "compute_budget", decoded.Instructions[0].Program)
+	assert.Equal(t, "set_compute_unit_price", decoded.Instructions[0].Name)
+
+	swap := decoded.Instructions[1]
+	assert.Equal(t, "pumpfun", swap.Program)
+	assert.Equal(t, "buy", swap.Name)
+	assert.Equal(t, float64(500000000), swap.Fields["amount_in"])
+	assert.Equal(t, float64(450000000), swap.Fields["min_amount_out"])
+	require.Len(t, swap.Accounts, 9)
+	assert.Equal(t, "bonding_curve", swap.Accounts[3].Name)
+	assert.Equal(t, swap.Accounts[3].PublicKey, swap.Fields["bonding_curve"])
+	assert.Equal(t, "user_wallet", swap.Accounts[0].Name)
+	assert.True(t, swap.Accounts[0].IsSigner)
+	assert.Equal(t, user.PublicKey().String(), swap.Accounts[0].PublicKey)
+
+	// 无效的交易数据
+	w, _ = decodeViaAPI(t, router, "not-base64!")
+	assert.Equal(t, 400, w.Code)
+}
+
+// TestDecodeVersionedTransaction 测试解码使用地址查找表的v0交易
+func TestDecodeVersionedTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	transactionService := services.NewTransactionService(createTestConfig())
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	router := gin.New()
+	router.POST("/api/v1/tx/decode", transactionHandler.DecodeTransaction)
+
+	payer := solana.NewWallet().PublicKey()
+	recipient := solana.NewWallet().PublicKey()
+	table := solana.NewWallet().PublicKey()
+
+	transfer := make([]byte, 12)
+	transfer[0] = 2
+	transfer[4] = 0x40
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{
+			solana.NewInstruction(solana.ComputeBudget, solana.AccountMetaSlice{}, []byte{2, 0x40, 0x0d, 0x03, 0x00}),
+			solana.NewInstruction(solana.SystemProgramID, solana.AccountMetaSlice{
+				solana.Meta(payer).WRITE().SIGNER(),
+				solana.Meta(recipient).WRITE(),
+			}, transfer),
+			solana.NewInstruction(solana.MustPublicKeyFromBase58("Stake11111111111111111111111111111111111111"), solana.AccountMetaSlice{}, []byte{1, 2, 3}),
+		},
+		solana.Hash{9},
+		solana.TransactionPayer(payer),
+		solana.TransactionAddressTables(map[solana.PublicKey]solana.PublicKeySlice{
+			table: {recipient},
+		}),
+	)
+	require.NoError(t, err)
+	txData, err := tx.MarshalBinary()
+	require.NoError(t, err)
+
+	w, decoded := decodeViaAPI(t, router, base64.StdEncoding.EncodeToString(txData))
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	assert.Equal(t, "v0", decoded.Version)
+	assert.Equal(t, uint32(200000), decoded.ComputeBudget.UnitLimit)
+	require.Len(t, decoded.AddressTableLookups, 1)
+	assert.Equal(t, table.String(), decoded.AddressTableLookups[0].AccountKey)
+	assert.Equal(t, []int{0}, decoded.AddressTableLookups[0].WritableIndexes)
+
+	require.Len(t, decoded.Instructions, 3)
+	transferInst := decoded.Instructions[1]
+	assert.Equal(t, "transfer", transferInst.Name)
+	assert.Equal(t, float64(64), transferInst.Fields["lamports"])
+	require.Len(t, transferInst.Accounts, 2)
+	assert.Equal(t, "to", transferInst.Accounts[1].Name)
+	assert.Equal(t, table.String()+"[0]", transferInst.Accounts[1].PublicKey)
+	assert.True(t, transferInst.Accounts[1].IsWritable)
+
+	// 未知程序只返回原始数据
+	unknown := decoded.Instructions[2]
+	assert.Empty(t, unknown.Program)
+	assert.Empty(t, unknown.Name)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{1, 2, 3}), unknown.Data)
+}
+
+// TestAdapterDecodeInstruction 测试适配器指令解码与构建对应
+func TestAdapterDecodeInstruction(t *testing.T) {
+	cfg := createTestConfig()
+	wallet := solana.NewWallet().PublicKey().String()
+
+	for _, dexCfg := range cfg.DEXes {
+		dexCfg := dexCfg
+		var adapter types.DEXAdapter
+		var err error
+		switch dexCfg.Name {
+		case "raydium":
+			adapter, err = adapters.NewRaydiumAdapter(&dexCfg)
+		case "pumpswap":
+			adapter, err = adapters.NewPumpSwapAdapter(&dexCfg)
+		default:
+			continue
+		}
+		require.NoError(t, err)
+
+		inst, err := adapter.BuildLiquidityInstruction(&types.LiquidityRequest{
+			DEXType:    dexCfg.Name,
+			Operation:  "remove",
+			TokenAMint: "So11111111111111111111111111111111111111112",
+			TokenBMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+			AmountA:    111,
+			AmountB:    222,
+			UserWallet: wallet,
+		})
+		require.NoError(t, err)
+
+		accounts := make([]types.DecodedAccount, len(inst.Accounts))
+		for i, acc := range inst.Accounts {
+			accounts[i] = types.DecodedAccount{PublicKey: acc.PublicKey.String(), IsSigner: acc.IsSigner, IsWritable: acc.IsWritable}
+		}
+
+		decoded, err := adapter.DecodeInstruction(inst.Data, accounts)
+		require.NoError(t, err, dexCfg.Name)
+		assert.Equal(t, "remove_liquidity", decoded.Name, dexCfg.Name)
+		assert.Equal(t, uint64(111), decoded.Fields["amount_a"])
+		assert.Equal(t, uint64(222), decoded.Fields["amount_b"])
+		assert.Equal(t, "user_wallet", decoded.Accounts[0].Name)
+
+		_, err = adapter.DecodeInstruction([]byte{0xff}, accounts)
+		assert.Error(t, err)
+	}
+}
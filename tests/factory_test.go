@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/services"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAdapterFactory 测试按类型创建适配器：同一类型可以配置多个实例，创建失败的DEX在列表中给出原因
+func TestAdapterFactory(t *testing.T) {
+	assert.Subset(t, adapters.FactoryTypes(), []string{"pumpfun", "pumpswap", "raydium"})
+
+	cfg := createTestConfig()
+	secondProgram := solana.NewWallet().PublicKey().String()
+	cfg.DEXes = append(cfg.DEXes,
+		config.DEXConfig{
+			Name:          "pumpswap-v2",
+			Type:          "pumpswap",
+			ProgramID:     secondProgram,
+			RouterAddress: "PSwapRouterV1111111111111111111111111111111",
+			Endpoints:     map[string]string{"quote": "http://127.0.0.1:1/quote"},
+			Enabled:       true,
+			Timeout:       time.Second,
+			RetryCount:    1,
+		},
+		config.DEXConfig{
+			Name:      "orca",
+			Type:      "whirlpool",
+			ProgramID: "whirLbMiicVdio4qvUfM5KAg6Ct8VwpYzGff3uctyCc",
+			Enabled:   true,
+		},
+		config.DEXConfig{
+			Name:      "raydium-broken",
+			Type:      "raydium",
+			ProgramID: "not-a-program-id",
+			Enabled:   true,
+		},
+	)
+
+	transactionService := services.NewTransactionService(cfg)
+	dexService := services.NewDEXService(cfg)
+	dexService.SetTransactionService(transactionService)
+
+	// 两个pumpswap实例使用各自的配置
+	first, err := transactionService.GetDEXAdapter("pumpswap")
+	require.NoError(t, err)
+	second, err := transactionService.GetDEXAdapter("pumpswap-v2")
+	require.NoError(t, err)
+	assert.Equal(t, "pumpswap-v2", second.GetName())
+	assert.Equal(t, secondProgram, second.GetConfig().ProgramID)
+	assert.NotEqual(t, first.GetConfig().ProgramID, second.GetConfig().ProgramID)
+
+	// 创建失败的DEX不注册，并在列表中给出原因
+	_, err = transactionService.GetDEXAdapter("orca")
+	assert.Error(t, err)
+	assert.Contains(t, transactionService.GetAdapterError("orca").Error(), `unknown dex type "whirlpool"`)
+	assert.Contains(t, transactionService.GetAdapterError("raydium-broken").Error(), "invalid program ID")
+	assert.NoError(t, transactionService.GetAdapterError("raydium"))
+
+	dexes, err := dexService.ListDEXes()
+	require.NoError(t, err)
+	byName := make(map[string]int)
+	for i, dex := range dexes {
+		byName[dex.Name] = i
+	}
+	v2 := dexes[byName["pumpswap-v2"]]
+	assert.Equal(t, "pumpswap", v2.Type)
+	assert.Equal(t, "online", v2.Status)
+	assert.Empty(t, v2.Error)
+	assert.Equal(t, "raydium", dexes[byName["raydium"]].Type)
+
+	orca := dexes[byName["orca"]]
+	assert.Equal(t, "whirlpool", orca.Type)
+	assert.Equal(t, "offline", orca.Status)
+	assert.Contains(t, orca.Error, "unknown dex type")
+
+	broken, err := dexService.GetDEX("raydium-broken")
+	require.NoError(t, err)
+	assert.Equal(t, "offline", broken.Status)
+	assert.Contains(t, broken.Error, "invalid program ID")
+
+	// 配置中的DEX名称不能重复
+	cfg.DEXes = append(cfg.DEXes, cfg.DEXes[0])
+	assert.ErrorContains(t, cfg.Validate(), "duplicated")
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// rpcHandlerFunc 模拟RPC方法处理函数
+type rpcHandlerFunc func(params json.RawMessage) (interface{}, error)
+
+// fakeRPCServer 模拟Solana JSON-RPC节点
+type fakeRPCServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]rpcHandlerFunc
+	calls    map[string]int
+}
+
+// newFakeRPCServer 创建模拟RPC节点，测试结束时自动关闭
+func newFakeRPCServer(t *testing.T) *fakeRPCServer {
+	s := &fakeRPCServer{
+		handlers: make(map[string]rpcHandlerFunc),
+		calls:    make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle 注册RPC方法处理函数
+func (s *fakeRPCServer) Handle(method string, fn rpcHandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = fn
+}
+
+// Calls 返回RPC方法被调用的次数
+func (s *fakeRPCServer) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+// serve 处理JSON-RPC请求
+func (s *fakeRPCServer) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.calls[req.Method]++
+	handler := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	resp := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      req.ID,
+	}
+	if handler == nil {
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "Method not found"}
+	} else if result, err := handler(req.Params); err != nil {
+		resp["error"] = map[string]interface{}{"code": -32000, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// rpcContextResult 构造带context的RPC返回值
+func rpcContextResult(slot uint64, value interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"context": map[string]interface{}{"slot": slot},
+		"value":   value,
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDEXHealthCheck 测试主动健康检查：程序账户可执行且端点可达为online，端点不可达为degraded，程序账户不存在为offline
+func TestDEXHealthCheck(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := createTestConfig()
+	programs := make(map[string]string)
+	for _, dex := range cfg.DEXes {
+		programs[dex.ProgramID] = dex.Name
+	}
+
+	rpcServer := newFakeRPCServer(t)
+	rpcServer.Handle("getAccountInfo", func(params json.RawMessage) (interface{}, error) {
+		var args []json.RawMessage
+		var program string
+		if err := json.Unmarshal(params, &args); err == nil && len(args) > 0 {
+			json.Unmarshal(args[0], &program)
+		}
+		if programs[program] == "pumpswap" {
+			return rpcContextResult(1, nil), nil
+		}
+		return rpcContextResult(1, map[string]interface{}{
+			"lamports":   1141440,
+			"owner":      "BPFLoaderUpgradeab1e11111111111111111111111",
+			"data":       []string{"", "base64"},
+			"executable": true,
+			"rentEpoch":  0,
+		}), nil
+	})
+
+	var mu sync.Mutex
+	methods := make(map[string]int)
+	okServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods[r.Method]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	t.Cleanup(okServer.Close)
+	downServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(downServer.Close)
+
+	cfg.Solana.RPCURL = rpcServer.URL
+	cfg.Health.History = 2
+	for i := range cfg.DEXes {
+		for name := range cfg.DEXes[i].Endpoints {
+			cfg.DEXes[i].Endpoints[name] = okServer.URL + "/" + name
+		}
+	}
+	cfg.DEXes[1].Endpoints["trade"] = downServer.URL
+
+	transactionService := services.NewTransactionService(cfg)
+	dexService := services.NewDEXService(cfg)
+	dexService.SetTransactionService(transactionService)
+	router := newAPIRouter(transactionService, dexService, services.NewConfigService(cfg))
+
+	// 尚未检查时不影响状态
+	assert.Nil(t, dexService.GetDEXHealth("raydium"))
+	status, err := dexService.CheckDEXStatus("pumpswap")
+	require.NoError(t, err)
+	assert.Equal(t, "online", status)
+
+	for i := 0; i < 3; i++ {
+		transactionService.CheckDEXHealth(context.Background())
+	}
+	assert.Equal(t, 3*len(cfg.DEXes), rpcServer.Calls("getAccountInfo"))
+	mu.Lock()
+	assert.Equal(t, map[string]int{"HEAD": 3 * 8}, methods)
+	mu.Unlock()
+
+	statusOf := func(name string) (string, *types.DEXHealth) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/api/v1/dex/"+name+"/status", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, 200, w.Code, w.Body.String())
+
+		var resp struct {
+			Data struct {
+				Status string           `json:"status"`
+				Health *types.DEXHealth `json:"health"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.NotNil(t, resp.Data.Health)
+		return resp.Data.Status, resp.Data.Health
+	}
+
+	status, health := statusOf("raydium")
+	assert.Equal(t, "online", status)
+	require.Len(t, health.Probes, 2)
+	assert.True(t, health.Probes[0].CheckedAt.After(health.Probes[1].CheckedAt))
+	results := health.Probes[0].Results
+	require.Len(t, results, 4)
+	assert.Equal(t, "program", results[0].Target)
+	assert.True(t, results[0].OK)
+	for _, result := range results[1:] {
+		assert.True(t, result.OK, result.Target)
+		assert.Equal(t, http.StatusMethodNotAllowed, result.StatusCode)
+	}
+
+	status, health = statusOf("pumpfun")
+	assert.Equal(t, "degraded", status)
+	var trade types.ProbeResult
+	for _, result := range health.Probes[0].Results {
+		if result.Target == "trade" {
+			trade = result
+		}
+	}
+	assert.False(t, trade.OK)
+	assert.Equal(t, http.StatusServiceUnavailable, trade.StatusCode)
+
+	status, health = statusOf("pumpswap")
+	assert.Equal(t, "offline", status)
+	assert.False(t, health.Probes[0].Results[0].OK)
+	assert.Contains(t, health.Probes[0].Results[0].Error, "not found")
+}
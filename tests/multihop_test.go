@@ -0,0 +1,249 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSOLMint  = "So11111111111111111111111111111111111111112"
+	testUSDCMint = "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v"
+)
+
+// quoteRequestBody 适配器发送的询价参数
+type quoteRequestBody struct {
+	InputMint  string `json:"inputMint"`
+	OutputMint string `json:"outputMint"`
+	Amount     uint64 `json:"amount"`
+}
+
+// newDEXAPIServer 创建模拟DEX接口，quote根据询价参数（GET时为查询参数）返回报价，不支持的代币对返回失败
+func newDEXAPIServer(t *testing.T, pools interface{}, quote func(req quoteRequestBody) interface{}) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pools", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pools)
+	})
+	mux.HandleFunc("/tokens", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pools)
+	})
+	mux.HandleFunc("/quote", func(w http.ResponseWriter, r *http.Request) {
+		var req quoteRequestBody
+		if r.Method == http.MethodGet {
+			req.InputMint = r.URL.Query().Get("inputMint")
+			req.OutputMint = r.URL.Query().Get("outputMint")
+			req.Amount, _ = strconv.ParseUint(r.URL.Query().Get("amount"), 10, 64)
+		} else {
+			json.NewDecoder(r.Body).Decode(&req)
+		}
+		json.NewEncoder(w).Encode(quote(req))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newMultiHopTestRouter 创建只能通过 TOKEN→SOL(pumpfun)→USDC(raydium) 两跳成交的测试路由
+func newMultiHopTestRouter(t *testing.T, token string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	pumpfun := newDEXAPIServer(t,
+		map[string]interface{}{
+			"success": true,
+			"data":    []map[string]interface{}{{"mint": token, "symbol": "TOKEN", "liquidity": 500}},
+		},
+		func(req quoteRequestBody) interface{} {
+			if req.InputMint != token || req.OutputMint != testSOLMint {
+				return map[string]interface{}{"success": false, "error": "pair not supported"}
+			}
+			return map[string]interface{}{
+				"success": true,
+				"data":    map[string]interface{}{"amountOut": req.Amount * 500, "fee": 1000000},
+			}
+		})
+	raydium := newDEXAPIServer(t,
+		map[string]interface{}{
+			"success": true,
+			"data": []map[string]interface{}{
+				{"id": "pool-sol-usdc", "baseMint": testSOLMint, "quoteMint": testUSDCMint, "liquidity": "1000000", "feeRate": 0.0025},
+			},
+		},
+		func(req quoteRequestBody) interface{} {
+			if req.InputMint != testSOLMint || req.OutputMint != testUSDCMint {
+				return map[string]interface{}{"success": false, "message": "no pool"}
+			}
+			return map[string]interface{}{
+				"success": true,
+				"data":    map[string]interface{}{"outAmount": strconv.FormatUint(req.Amount/10, 10), "fee": "100000", "priceImpactPct": 0.01},
+			}
+		})
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = newBlockhashRPCServer(t).URL
+	cfg.Routing.QuoteTimeout = 2 * time.Second
+	cfg.Routing.MaxHops = 3
+	cfg.Routing.MaxPaths = 20
+	cfg.Routing.IntermediateMints = []string{testSOLMint, testUSDCMint}
+	for i := range cfg.DEXes {
+		cfg.DEXes[i].RetryCount = 1
+		switch cfg.DEXes[i].Name {
+		case "pumpfun":
+			cfg.DEXes[i].Endpoints = map[string]string{"api": pumpfun.URL, "quote": pumpfun.URL + "/quote"}
+		case "raydium":
+			cfg.DEXes[i].Endpoints = map[string]string{"pools": raydium.URL + "/pools", "quote": raydium.URL + "/quote"}
+		default:
+			cfg.DEXes[i].Enabled = false
+		}
+	}
+
+	transactionHandler := handlers.NewTransactionHandler(services.NewTransactionService(cfg))
+	router := gin.New()
+	router.GET("/api/v1/route/quote", transactionHandler.GetRouteQuote)
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	router.POST("/api/v1/tx/decode", transactionHandler.DecodeTransaction)
+	return router
+}
+
+// TestMultiHopRouteQuote 测试在池子图上找到两跳路径并逐跳询价
+func TestMultiHopRouteQuote(t *testing.T) {
+	token := solana.NewWallet().PublicKey().String()
+	router := newMultiHopTestRouter(t, token)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/route/quote?inputMint="+token+"&outputMint="+testUSDCMint+"&amountIn=1000000", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.RouteQuoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Best)
+	assert.Equal(t, "pumpfun,raydium", resp.Best.DEX)
+	assert.Equal(t, uint64(49800000), resp.Best.NetAmountOut)
+
+	require.Len(t, resp.Best.Legs, 2)
+	assert.Equal(t, token, resp.Best.Legs[0].InputMint)
+	assert.Equal(t, testSOLMint, resp.Best.Legs[0].OutputMint)
+	assert.Equal(t, token, resp.Best.Legs[0].PoolID)
+	assert.Equal(t, uint64(499000000), resp.Best.Legs[0].AmountOut)
+	assert.Equal(t, "pool-sol-usdc", resp.Best.Legs[1].PoolID)
+	assert.Equal(t, uint64(499000000), resp.Best.Legs[1].AmountIn)
+	require.NotNil(t, resp.Best.Quote)
+	assert.Len(t, resp.Best.Quote.Route, 2)
+
+	// 直接报价全部失败
+	assert.Len(t, resp.Failed, 2)
+
+	// 限制为单跳时没有可用路由
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/route/quote?inputMint="+token+"&outputMint="+testUSDCMint+"&amountIn=1000000&maxHops=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/route/quote?inputMint="+token+"&outputMint="+testUSDCMint+"&amountIn=1000000&maxHops=4", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+}
+
+// TestEncodeMultiHopSwap 测试多跳路径编码到同一笔交易，只在最后一跳限制最小输出
+func TestEncodeMultiHopSwap(t *testing.T) {
+	token := solana.NewWallet().PublicKey().String()
+	router := newMultiHopTestRouter(t, token)
+	user := solana.NewWallet().PublicKey()
+
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:    "auto",
+		InputMint:  token,
+		OutputMint: testUSDCMint,
+		AmountIn:   1000000,
+		Slippage:   0.01,
+		UserWallet: user.String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "pumpfun,raydium", resp.DEXType)
+	assert.Equal(t, uint64(49800000), resp.QuotedAmountOut)
+	assert.Len(t, resp.Route, 2)
+
+	_, decoded := decodeViaAPI(t, router, resp.Transaction)
+	require.True(t, decoded.Success, decoded.Error)
+	require.Len(t, decoded.Instructions, 3)
+
+	// 中间代币（SOL）的关联账户
+	ata, _, err := solana.FindAssociatedTokenAddress(user, solana.SolMint)
+	require.NoError(t, err)
+	assert.Equal(t, "create_idempotent", decoded.Instructions[0].Name)
+	assert.Equal(t, ata.String(), decoded.Instructions[0].Accounts[1].PublicKey)
+
+	first := decoded.Instructions[1]
+	assert.Equal(t, "pumpfun", first.Program)
+	assert.Equal(t, "sell", first.Name)
+	assert.Equal(t, float64(1000000), first.Fields["amount_in"])
+	assert.Equal(t, float64(0), first.Fields["min_amount_out"])
+
+	last := decoded.Instructions[2]
+	assert.Equal(t, "raydium", last.Program)
+	assert.Equal(t, "swap", last.Name)
+	assert.Equal(t, float64(499000000), last.Fields["amount_in"])
+	assert.Equal(t, float64(49302000), last.Fields["min_amount_out"])
+}
+
+// TestMultiHopMinAmountOutOnFinalHop 测试指定min_amount_out时只有最后一跳带最小输出，中间跳按报价输出确定下一跳输入
+func TestMultiHopMinAmountOutOnFinalHop(t *testing.T) {
+	token := solana.NewWallet().PublicKey().String()
+	router := newMultiHopTestRouter(t, token)
+
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:      "auto",
+		InputMint:    token,
+		OutputMint:   testUSDCMint,
+		AmountIn:     1000000,
+		MinAmountOut: 45000000,
+		Slippage:     0.05,
+		UserWallet:   solana.NewWallet().PublicKey().String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.True(t, resp.Success, resp.Error)
+	require.Len(t, resp.Route, 2)
+
+	_, decoded := decodeViaAPI(t, router, resp.Transaction)
+	require.True(t, decoded.Success, decoded.Error)
+
+	var swaps []types.DecodedInstruction
+	for _, instruction := range decoded.Instructions {
+		if _, ok := instruction.Fields["min_amount_out"]; ok {
+			swaps = append(swaps, instruction)
+		}
+	}
+	require.Len(t, swaps, 2)
+	for i, swap := range swaps[:len(swaps)-1] {
+		assert.Equal(t, float64(0), swap.Fields["min_amount_out"], "hop %d", i+1)
+		assert.Equal(t, float64(resp.Route[i+1].AmountIn), swaps[i+1].Fields["amount_in"], "hop %d", i+2)
+	}
+	assert.Equal(t, float64(499000000), swaps[1].Fields["amount_in"])
+	assert.Equal(t, float64(45000000), swaps[1].Fields["min_amount_out"])
+}
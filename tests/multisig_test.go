@@ -0,0 +1,247 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/signer"
+	"solana-dex-service/internal/store"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFeePayerAndMultiSigner 测试中继代付手续费和多签名发送
+func TestFeePayerAndMultiSigner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newBlockhashRPCServer(t)
+	server.Handle("sendTransaction", func(params json.RawMessage) (interface{}, error) {
+		var args []string
+		json.Unmarshal(params, &args)
+		data, _ := base64.StdEncoding.DecodeString(args[0])
+		tx, err := solana.TransactionFromBytes(data)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.VerifySignatures(); err != nil {
+			return nil, err
+		}
+		return tx.Signatures[0].String(), nil
+	})
+
+	user := solana.NewWallet()
+	relayer := solana.NewWallet()
+	ks, err := signer.NewKeystore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock("pw"))
+	_, err = ks.Import("user", user.PrivateKey, "pw")
+	require.NoError(t, err)
+	_, err = ks.Import("relayer", relayer.PrivateKey, "pw")
+	require.NoError(t, err)
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = server.URL
+	transactionService := services.NewTransactionService(cfg)
+	transactionService.SetSigner(ks)
+	txStore, err := store.NewBoltTransactionStore(filepath.Join(t.TempDir(), "transactions.db"))
+	require.NoError(t, err)
+	defer txStore.Close()
+	transactionService.SetTransactionStore(txStore)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	router := gin.New()
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	router.POST("/api/v1/test/transaction", transactionHandler.TestTransaction)
+
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:    "raydium",
+		InputMint:  "So11111111111111111111111111111111111111112",
+		OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		AmountIn:   1000000,
+		Slippage:   0.01,
+		UserWallet: user.PublicKey().String(),
+		FeePayer:   relayer.PublicKey().String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var encoded types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &encoded))
+
+	// 中继账户是付款人，用户钱包仍需签名
+	tx := decodeTestTransaction(t, encoded.Transaction)
+	assert.Equal(t, relayer.PublicKey(), tx.Message.AccountKeys[0])
+	assert.Equal(t, uint8(2), tx.Message.Header.NumRequiredSignatures)
+	assert.True(t, tx.IsSigner(user.PublicKey()))
+
+	// 只有一个签名时不能发送
+	body, _ = json.Marshal(types.TransactionTestRequest{Transaction: encoded.Transaction, SignerID: "user"})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/test/transaction", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), relayer.PublicKey().String())
+	assert.Zero(t, server.Calls("sendTransaction"))
+
+	// 用户和中继共同签名后发送
+	body, _ = json.Marshal(types.TransactionTestRequest{
+		Transaction: encoded.Transaction,
+		SignerID:    "user",
+		SignerIDs:   []string{relayer.PublicKey().String()},
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/test/transaction", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, 1, server.Calls("sendTransaction"))
+
+	// 交易记录归属用户钱包，付款人单独记录
+	records, total, err := txStore.List(store.TransactionFilter{Wallet: user.PublicKey().String()})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, relayer.PublicKey().String(), records[0].FeePayer)
+
+	// 无效的付款人地址
+	body, _ = json.Marshal(types.SwapRequest{
+		DEXType:    "raydium",
+		InputMint:  "So11111111111111111111111111111111111111112",
+		OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		AmountIn:   1000000,
+		UserWallet: user.PublicKey().String(),
+		FeePayer:   "not-a-key",
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "fee payer")
+}
+
+// TestAssembleTransaction 测试组装分离签名
+func TestAssembleTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	transactionService := services.NewTransactionService(createTestConfig())
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	router := gin.New()
+	router.POST("/api/v1/tx/assemble", transactionHandler.AssembleTransaction)
+
+	user := solana.NewWallet()
+	relayer := solana.NewWallet()
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(
+			solana.MemoProgramID,
+			solana.AccountMetaSlice{solana.Meta(user.PublicKey()).SIGNER()},
+			[]byte("relayed"),
+		)},
+		solana.Hash{7},
+		solana.TransactionPayer(relayer.PublicKey()),
+	)
+	require.NoError(t, err)
+
+	messageData, err := tx.Message.MarshalBinary()
+	require.NoError(t, err)
+	userSig, err := user.PrivateKey.Sign(messageData)
+	require.NoError(t, err)
+	relayerSig, err := relayer.PrivateKey.Sign(messageData)
+	require.NoError(t, err)
+
+	assemble := func(req types.AssembleTransactionRequest) (*httptest.ResponseRecorder, types.AssembleTransactionResponse) {
+		body, _ := json.Marshal(req)
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/tx/assemble", bytes.NewBuffer(body))
+		router.ServeHTTP(w, httpReq)
+		var resp types.AssembleTransactionResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	message := base64.StdEncoding.EncodeToString(messageData)
+
+	// 缺少中继签名
+	w, resp := assemble(types.AssembleTransactionRequest{
+		Message:    message,
+		Signatures: []types.DetachedSignature{{PublicKey: user.PublicKey().String(), Signature: userSig.String()}},
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Equal(t, []string{relayer.PublicKey().String()}, resp.MissingSigners)
+
+	// 签名与消息不匹配
+	w, resp = assemble(types.AssembleTransactionRequest{
+		Message:    message,
+		Signatures: []types.DetachedSignature{{PublicKey: user.PublicKey().String(), Signature: relayerSig.String()}},
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, resp.Error, "does not match")
+
+	// 非签名账户
+	stranger := solana.NewWallet()
+	w, resp = assemble(types.AssembleTransactionRequest{
+		Message:    message,
+		Signatures: []types.DetachedSignature{{PublicKey: stranger.PublicKey().String(), Signature: userSig.String()}},
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, resp.Error, "not a required signer")
+
+	// 签名顺序与账户顺序无关
+	w, resp = assemble(types.AssembleTransactionRequest{
+		Message: message,
+		Signatures: []types.DetachedSignature{
+			{PublicKey: user.PublicKey().String(), Signature: userSig.String()},
+			{PublicKey: relayer.PublicKey().String(), Signature: relayerSig.String()},
+		},
+	})
+	require.Equal(t, 200, w.Code, resp.Error)
+	assert.Equal(t, relayerSig.String(), resp.Signature)
+
+	assembled := decodeTestTransaction(t, resp.Transaction)
+	assert.NoError(t, assembled.VerifySignatures())
+	assert.Equal(t, []solana.Signature{relayerSig, userSig}, assembled.Signatures)
+
+	// 也可以直接传入未签名交易
+	unsigned, err := tx.MarshalBinary()
+	require.NoError(t, err)
+	w, _ = assemble(types.AssembleTransactionRequest{
+		Transaction: base64.StdEncoding.EncodeToString(unsigned),
+		Signatures: []types.DetachedSignature{
+			{PublicKey: relayer.PublicKey().String(), Signature: relayerSig.String()},
+			{PublicKey: user.PublicKey().String(), Signature: userSig.String()},
+		},
+	})
+	assert.Equal(t, 200, w.Code)
+
+	// 传入交易已有的签名会保留，只需补充其余签名
+	partial := &solana.Transaction{Signatures: []solana.Signature{relayerSig, {}}, Message: tx.Message}
+	partialData, err := partial.MarshalBinary()
+	require.NoError(t, err)
+	w, resp = assemble(types.AssembleTransactionRequest{
+		Transaction: base64.StdEncoding.EncodeToString(partialData),
+		Signatures:  []types.DetachedSignature{{PublicKey: user.PublicKey().String(), Signature: userSig.String()}},
+	})
+	require.Equal(t, 200, w.Code, resp.Error)
+	assert.Equal(t, []solana.Signature{relayerSig, userSig}, decodeTestTransaction(t, resp.Transaction).Signatures)
+
+	// 已有签名与消息不匹配
+	partial.Signatures = []solana.Signature{userSig, {}}
+	partialData, err = partial.MarshalBinary()
+	require.NoError(t, err)
+	w, resp = assemble(types.AssembleTransactionRequest{
+		Transaction: base64.StdEncoding.EncodeToString(partialData),
+		Signatures:  []types.DetachedSignature{{PublicKey: user.PublicKey().String(), Signature: userSig.String()}},
+	})
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, resp.Error, "does not match")
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMultiSwapTestRouter 创建原子多交换测试路由
+func newMultiSwapTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = newBlockhashRPCServer(t).URL
+
+	transactionHandler := handlers.NewTransactionHandler(services.NewTransactionService(cfg))
+	router := gin.New()
+	router.POST("/api/v1/encode/multi-swap", transactionHandler.EncodeMultiSwap)
+	router.POST("/api/v1/tx/decode", transactionHandler.DecodeTransaction)
+	return router
+}
+
+// postMultiSwap 提交原子多交换请求
+func postMultiSwap(t *testing.T, router *gin.Engine, req types.MultiSwapRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/encode/multi-swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, httpReq)
+	return w
+}
+
+// TestEncodeMultiSwap 测试三个DEX上卖出不同代币换SOL编码到同一笔交易
+func TestEncodeMultiSwap(t *testing.T) {
+	router := newMultiSwapTestRouter(t)
+	user := solana.NewWallet().PublicKey()
+
+	var swaps []types.SwapRequest
+	for _, dex := range []string{"pumpfun", "pumpswap", "raydium"} {
+		swaps = append(swaps, types.SwapRequest{
+			DEXType:    dex,
+			InputMint:  solana.NewWallet().PublicKey().String(),
+			OutputMint: testSOLMint,
+			AmountIn:   1000000,
+			Slippage:   0.01,
+		})
+	}
+
+	w := postMultiSwap(t, router, types.MultiSwapRequest{
+		Swaps:       swaps,
+		UserWallet:  user.String(),
+		PriorityFee: 1000,
+	})
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.MultiSwapResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Swaps, 3)
+	assert.Equal(t, uint32(600000), resp.ComputeUnitLimit)
+	require.NotNil(t, resp.Preflight)
+	assert.LessOrEqual(t, resp.Preflight.Size, 1232)
+	assert.LessOrEqual(t, resp.Preflight.Accounts, 64)
+	assert.Equal(t, 1, resp.Preflight.Signatures)
+	assert.Empty(t, resp.Preflight.Violations)
+
+	_, decoded := decodeViaAPI(t, router, resp.Transaction)
+	require.True(t, decoded.Success, decoded.Error)
+
+	// 计算预算只设置一次，SOL的关联账户只创建一次
+	var names []string
+	for _, instruction := range decoded.Instructions {
+		names = append(names, instruction.Name)
+	}
+	assert.Equal(t, []string{"set_compute_unit_price", "set_compute_unit_limit", "create_idempotent", "sell", "swap", "swap"}, names)
+	assert.Equal(t, "pumpfun", decoded.Instructions[3].Program)
+	assert.Equal(t, "pumpswap", decoded.Instructions[4].Program)
+	assert.Equal(t, "raydium", decoded.Instructions[5].Program)
+
+	ata, _, err := solana.FindAssociatedTokenAddress(user, solana.SolMint)
+	require.NoError(t, err)
+	assert.Equal(t, ata.String(), decoded.Instructions[2].Accounts[1].PublicKey)
+}
+
+// TestEncodeMultiSwapLimits 测试超过交易大小和账户数量限制时返回明确的错误和预检结果
+func TestEncodeMultiSwapLimits(t *testing.T) {
+	router := newMultiSwapTestRouter(t)
+	user := solana.NewWallet().PublicKey().String()
+
+	swaps := func(n int) []types.SwapRequest {
+		var swaps []types.SwapRequest
+		for i := 0; i < n; i++ {
+			swaps = append(swaps, types.SwapRequest{
+				DEXType:    "raydium",
+				InputMint:  solana.NewWallet().PublicKey().String(),
+				OutputMint: testSOLMint,
+				AmountIn:   1000000,
+				Slippage:   0.01,
+			})
+		}
+		return swaps
+	}
+
+	w := postMultiSwap(t, router, types.MultiSwapRequest{Swaps: swaps(15), UserWallet: user})
+	require.Equal(t, 400, w.Code)
+	var errResp types.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Contains(t, errResp.Error, "exceeds limit of 1232 bytes")
+
+	// 预检给出超出的限制和数量，改用查找表可以放下时建议v0交易
+	require.Len(t, errResp.Violations, 1)
+	assert.Equal(t, "transaction_size", errResp.Violations[0].Limit)
+	assert.Equal(t, 1232, errResp.Violations[0].Max)
+	assert.Equal(t, errResp.Violations[0].Actual-1232, errResp.Violations[0].Excess)
+	assert.Greater(t, errResp.Violations[0].Excess, 0)
+	assert.Contains(t, errResp.Violations[0].Suggestion, "v0")
+
+	w = postMultiSwap(t, router, types.MultiSwapRequest{Swaps: swaps(30), UserWallet: user})
+	require.Equal(t, 400, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Contains(t, errResp.Error, "exceeds limit of 64")
+
+	// 账户锁超出时查找表无济于事，不建议v0交易
+	var limits []string
+	for _, v := range errResp.Violations {
+		limits = append(limits, v.Limit)
+		assert.NotContains(t, v.Suggestion, "v0")
+	}
+	assert.Equal(t, []string{"account_locks", "transaction_size"}, limits)
+	assert.Equal(t, errResp.Violations[0].Actual-64, errResp.Violations[0].Excess)
+
+	// 交换的钱包必须与请求一致
+	mismatched := swaps(1)
+	mismatched[0].UserWallet = solana.NewWallet().PublicKey().String()
+	w = postMultiSwap(t, router, types.MultiSwapRequest{Swaps: mismatched, UserWallet: user})
+	require.Equal(t, 400, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	assert.Contains(t, errResp.Error, "swap 1")
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/adapters"
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingQuoteServer 创建记录询价次数的pumpfun报价接口，输出为输入的两倍，pair not supported的代币返回失败
+func newCountingQuoteServer(t *testing.T, delay time.Duration, calls *int32) *httptest.Server {
+	return newDEXAPIServer(t, nil, func(req quoteRequestBody) interface{} {
+		atomic.AddInt32(calls, 1)
+		time.Sleep(delay)
+		if req.OutputMint != testSOLMint {
+			return map[string]interface{}{"success": false, "error": "pair not supported"}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"amountOut": req.Amount * 2, "minAmountOut": req.Amount, "fee": 100, "priceImpact": 0.01},
+		}
+	})
+}
+
+// newCachedPumpfunAdapter 创建启用报价缓存的pumpfun适配器
+func newCachedPumpfunAdapter(t *testing.T, quoteURL string, ttl time.Duration, bucketBps int) *adapters.CachedAdapter {
+	dexCfg := config.DEXConfig{
+		Name:           "pumpfun",
+		ProgramID:      "6EF8rrecthR5Dkzon8Nwu78hRvfCKubJ14M5uBEwF6P",
+		Endpoints:      map[string]string{"quote": quoteURL},
+		Enabled:        true,
+		Timeout:        5 * time.Second,
+		RetryCount:     1,
+		QuoteCacheTTL:  ttl,
+		QuoteBucketBps: bucketBps,
+	}
+	adapter, err := adapters.NewPumpfunAdapter(&dexCfg)
+	require.NoError(t, err)
+	cached, ok := adapters.WithQuoteCache(adapter, &dexCfg).(*adapters.CachedAdapter)
+	require.True(t, ok)
+	return cached
+}
+
+// TestQuoteCache 测试报价缓存的命中、过期、金额区间和失败不缓存
+func TestQuoteCache(t *testing.T) {
+	var calls int32
+	server := newCountingQuoteServer(t, 0, &calls)
+	token := "TokenMint1111111111111111111111111111111111"
+
+	cached := newCachedPumpfunAdapter(t, server.URL+"/quote", 200*time.Millisecond, 0)
+
+	quote, status, err := cached.GetQuoteCached(context.Background(), token, testSOLMint, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, adapters.QuoteCacheMiss, status)
+	assert.Equal(t, uint64(2000), quote.AmountOut)
+
+	quote, status, err = cached.GetQuoteCached(context.Background(), token, testSOLMint, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, adapters.QuoteCacheHit, status)
+	assert.Equal(t, uint64(2000), quote.AmountOut)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 返回的报价是副本，修改不影响缓存
+	quote.AmountOut = 1
+	quote, _, err = cached.GetQuoteCached(context.Background(), token, testSOLMint, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2000), quote.AmountOut)
+
+	// 未配置金额区间时不同金额分别询价
+	_, status, err = cached.GetQuoteCached(context.Background(), token, testSOLMint, 1001)
+	require.NoError(t, err)
+	assert.Equal(t, adapters.QuoteCacheMiss, status)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 过期后重新询价
+	time.Sleep(250 * time.Millisecond)
+	_, status, err = cached.GetQuoteCached(context.Background(), token, testSOLMint, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, adapters.QuoteCacheMiss, status)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+
+	// 失败的询价不缓存
+	for i := 0; i < 2; i++ {
+		_, status, err = cached.GetQuoteCached(context.Background(), testSOLMint, token, 1000)
+		assert.Error(t, err)
+		assert.Equal(t, adapters.QuoteCacheMiss, status)
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+
+	// 同一金额区间内的报价按输入金额等比例换算
+	bucketed := newCachedPumpfunAdapter(t, server.URL+"/quote", time.Minute, 100)
+	_, status, err = bucketed.GetQuoteCached(context.Background(), token, testSOLMint, 1000000)
+	require.NoError(t, err)
+	assert.Equal(t, adapters.QuoteCacheMiss, status)
+	quote, status, err = bucketed.GetQuoteCached(context.Background(), token, testSOLMint, 1000500)
+	require.NoError(t, err)
+	assert.Equal(t, adapters.QuoteCacheHit, status)
+	assert.Equal(t, uint64(1000500), quote.AmountIn)
+	assert.Equal(t, uint64(2001000), quote.AmountOut)
+	assert.Equal(t, uint64(1000500), quote.MinAmountOut)
+	// 价格影响随输入金额增大：0.01 × 1.0005 / (0.99 + 0.01 × 1.0005)
+	assert.InDelta(t, 0.0100049, quote.PriceImpact, 1e-7)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+}
+
+// TestQuoteCacheCoalescing 测试并发的相同询价只请求一次上游接口
+func TestQuoteCacheCoalescing(t *testing.T) {
+	var calls int32
+	server := newCountingQuoteServer(t, 100*time.Millisecond, &calls)
+	token := "TokenMint1111111111111111111111111111111111"
+
+	cached := newCachedPumpfunAdapter(t, server.URL+"/quote", time.Minute, 0)
+
+	const n = 8
+	statuses := make([]string, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			quote, status, err := cached.GetQuoteCached(context.Background(), token, testSOLMint, 5000)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(10000), quote.AmountOut)
+			statuses[i] = status
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	counts := make(map[string]int)
+	for _, status := range statuses {
+		counts[status]++
+	}
+	assert.Equal(t, 1, counts[adapters.QuoteCacheMiss])
+	assert.Equal(t, n-1, counts[adapters.QuoteCacheHit]+counts[adapters.QuoteCacheCoalesced])
+}
+
+// TestQuoteCacheHeaders 测试报价接口在响应头中返回缓存结果
+func TestQuoteCacheHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var calls int32
+	server := newCountingQuoteServer(t, 0, &calls)
+	token := "TokenMint1111111111111111111111111111111111"
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = newBlockhashRPCServer(t).URL
+	for i := range cfg.DEXes {
+		cfg.DEXes[i].Enabled = cfg.DEXes[i].Name == "pumpfun"
+		cfg.DEXes[i].QuoteCacheTTL = time.Minute
+	}
+	setQuoteEndpoint(cfg, "pumpfun", server.URL+"/quote")
+
+	transactionHandler := handlers.NewTransactionHandler(services.NewTransactionService(cfg))
+	router := gin.New()
+	router.GET("/api/v1/quote", transactionHandler.GetQuote)
+	router.GET("/api/v1/route/quote", transactionHandler.GetRouteQuote)
+
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", url, nil)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/api/v1/quote?dex=pumpfun&inputMint=" + token + "&outputMint=" + testSOLMint + "&amountIn=1000")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "miss", w.Header().Get("X-Quote-Cache"))
+
+	w = get("/api/v1/quote?dex=pumpfun&inputMint=" + token + "&outputMint=" + testSOLMint + "&amountIn=1000")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "hit", w.Header().Get("X-Quote-Cache"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 跨DEX询价与单DEX询价共用缓存
+	w = get("/api/v1/route/quote?inputMint=" + token + "&outputMint=" + testSOLMint + "&amountIn=1000&maxHops=1")
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Equal(t, "1", w.Header().Get("X-Quote-Cache-Hits"))
+	assert.Equal(t, "0", w.Header().Get("X-Quote-Cache-Misses"))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	var resp struct {
+		Best struct {
+			Cache string `json:"cache"`
+		} `json:"best"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "hit", resp.Best.Cache)
+
+	w = get("/api/v1/route/quote?inputMint=" + token + "&outputMint=" + testSOLMint + "&amountIn=2000&maxHops=1")
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Quote-Cache-Hits"))
+	assert.Equal(t, "1", w.Header().Get("X-Quote-Cache-Misses"))
+}
@@ -0,0 +1,20 @@
+package tests
+
+import (
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newAPIRouter 使用服务的路由表创建路由，确保测试访问的接口在服务中同样注册
+func newAPIRouter(transactionService *services.TransactionService, dexService *services.DEXService, configService *services.ConfigService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handlers.SetupRoutes(router,
+		handlers.NewTransactionHandler(transactionService),
+		handlers.NewDEXHandler(dexService),
+		handlers.NewConfigHandler(configService),
+	)
+	return router
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newQuoteServer 创建返回固定报价的DEX报价服务
+func newQuoteServer(t *testing.T, delay time.Duration, body interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// setQuoteEndpoint 设置DEX的报价端点
+func setQuoteEndpoint(cfg *config.Config, dexName, url string) {
+	for i := range cfg.DEXes {
+		if cfg.DEXes[i].Name == dexName {
+			cfg.DEXes[i].Endpoints["quote"] = url
+			cfg.DEXes[i].RetryCount = 1
+		}
+	}
+}
+
+// newRoutingTestRouter 创建三个DEX报价不同的测试路由：raydium净输出最高，pumpswap超时
+func newRoutingTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	raydium := newQuoteServer(t, 0, map[string]interface{}{
+		"success": true,
+		"data":    map[string]interface{}{"outAmount": "990000", "minOutAmount": "985000", "fee": "20000", "priceImpactPct": 0.1},
+	})
+	pumpfun := newQuoteServer(t, 0, map[string]interface{}{
+		"success": true,
+		"data":    map[string]interface{}{"amountOut": 1000000, "minAmountOut": 995000, "fee": 50000, "priceImpact": 0.2},
+	})
+	pumpswap := newQuoteServer(t, time.Second, map[string]interface{}{
+		"success": true,
+		"data":    map[string]interface{}{"amountOut": 2000000, "minAmountOut": 1990000, "fee": 0},
+	})
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = newBlockhashRPCServer(t).URL
+	cfg.Routing.QuoteTimeout = 200 * time.Millisecond
+	setQuoteEndpoint(cfg, "raydium", raydium.URL)
+	setQuoteEndpoint(cfg, "pumpfun", pumpfun.URL)
+	setQuoteEndpoint(cfg, "pumpswap", pumpswap.URL)
+
+	transactionHandler := handlers.NewTransactionHandler(services.NewTransactionService(cfg))
+	router := gin.New()
+	router.GET("/api/v1/route/quote", transactionHandler.GetRouteQuote)
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	return router
+}
+
+// TestRouteQuote 测试跨DEX询价按净输出排序并忽略超时的DEX
+func TestRouteQuote(t *testing.T) {
+	router := newRoutingTestRouter(t)
+
+	start := time.Now()
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/route/quote?inputMint=So11111111111111111111111111111111111111112&outputMint=EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v&amountIn=1000000", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+	assert.Less(t, time.Since(start), time.Second, "slow DEX must not delay the response")
+
+	var resp types.RouteQuoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+
+	// pumpfun毛输出更高，但扣除手续费后raydium更优
+	require.NotNil(t, resp.Best)
+	assert.Equal(t, "raydium", resp.Best.DEX)
+	assert.Equal(t, uint64(970000), resp.Best.NetAmountOut)
+	require.Len(t, resp.Alternatives, 1)
+	assert.Equal(t, "pumpfun", resp.Alternatives[0].DEX)
+	assert.Equal(t, uint64(950000), resp.Alternatives[0].NetAmountOut)
+	require.Len(t, resp.Failed, 1)
+	assert.Equal(t, "pumpswap", resp.Failed[0].DEX)
+	assert.Contains(t, resp.Failed[0].Error, "timed out")
+
+	// 参数错误
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/route/quote?inputMint=So11111111111111111111111111111111111111112&outputMint=So11111111111111111111111111111111111111112&amountIn=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/route/quote?inputMint=So11111111111111111111111111111111111111112", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+}
+
+// TestEncodeSwapAutoRoute 测试dex_type为auto时使用最优DEX编码交易
+func TestEncodeSwapAutoRoute(t *testing.T) {
+	router := newRoutingTestRouter(t)
+
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:    "auto",
+		InputMint:  "So11111111111111111111111111111111111111112",
+		OutputMint: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v",
+		AmountIn:   1000000,
+		Slippage:   0.01,
+		UserWallet: solana.NewWallet().PublicKey().String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "raydium", resp.DEXType)
+	assert.Equal(t, uint64(970000), resp.QuotedAmountOut)
+
+	tx := decodeTestTransaction(t, resp.Transaction)
+	require.Len(t, tx.Message.Instructions, 1)
+	programID, err := tx.Message.Program(tx.Message.Instructions[0].ProgramIDIndex)
+	require.NoError(t, err)
+	assert.Equal(t, "675kPX9MHTjS2zt1qfr1NYHuzeLXfQM9H24wFSUt1Mp8", programID.String())
+}
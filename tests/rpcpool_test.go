@@ -0,0 +1,240 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/rpcpool"
+	"solana-dex-service/internal/services"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRPCPool 创建只包含给定端点的RPC端点池
+func newTestRPCPool(t *testing.T, urls ...string) *rpcpool.Pool {
+	var endpoints []config.RPCEndpointConfig
+	for _, url := range urls {
+		endpoints = append(endpoints, config.RPCEndpointConfig{URL: url})
+	}
+	pool, err := rpcpool.New(endpoints, time.Minute, 50)
+	require.NoError(t, err)
+	return pool
+}
+
+// handleSlot 注册返回固定slot的getSlot方法
+func handleSlot(server *fakeRPCServer, slot uint64) {
+	server.Handle("getSlot", func(json.RawMessage) (interface{}, error) {
+		return slot, nil
+	})
+}
+
+// TestRPCPoolConfig 测试端点池配置校验
+func TestRPCPoolConfig(t *testing.T) {
+	_, err := rpcpool.New(nil, time.Second, 0)
+	assert.Error(t, err)
+
+	_, err = rpcpool.New([]config.RPCEndpointConfig{{URL: "http://a", Roles: []string{"write"}}}, time.Second, 0)
+	assert.Error(t, err)
+
+	// 缺少发送端点
+	_, err = rpcpool.New([]config.RPCEndpointConfig{{URL: "http://a", Roles: []string{rpcpool.RoleRead}}}, time.Second, 0)
+	assert.Error(t, err)
+
+	// 未配置rpc_endpoints时回退到rpc_url
+	cfg := config.SolanaConfig{RPCURL: "http://fallback"}
+	endpoints := cfg.GetRPCEndpoints()
+	require.Len(t, endpoints, 1)
+	assert.Equal(t, "http://fallback", endpoints[0].URL)
+}
+
+// TestRPCPoolReadFailover 测试读请求故障转移
+func TestRPCPoolReadFailover(t *testing.T) {
+	healthy := newFakeRPCServer(t)
+	handleSlot(healthy, 100)
+
+	// 不可达的端点
+	down := newFakeRPCServer(t)
+	downURL := down.URL
+	down.Close()
+
+	pool, err := rpcpool.New([]config.RPCEndpointConfig{
+		{URL: downURL, Weight: 10},
+		{URL: healthy.URL, Weight: 1},
+	}, time.Minute, 50)
+	require.NoError(t, err)
+
+	var slot uint64
+	err = pool.Read(context.Background(), func(ctx context.Context, client *rpc.Client) (err error) {
+		slot, err = client.GetSlot(ctx, rpc.CommitmentProcessed)
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), slot)
+	assert.Equal(t, 1, healthy.Calls("getSlot"))
+
+	// 多次失败后不可达端点的评分低于健康端点
+	for i := 0; i < 10; i++ {
+		pool.CheckHealth(context.Background())
+	}
+	status := pool.Status()
+	require.Len(t, status, 2)
+	assert.Equal(t, healthy.URL, status[0].URL)
+	assert.Equal(t, downURL, status[1].URL)
+	assert.Greater(t, status[1].ErrorRate, 0.5)
+	assert.NotEmpty(t, status[1].LastError)
+
+	// 所有端点失败时返回汇总错误
+	err = pool.Read(context.Background(), func(ctx context.Context, client *rpc.Client) error {
+		return errors.New("boom")
+	})
+	assert.ErrorContains(t, err, "all rpc endpoints failed")
+}
+
+// TestRPCPoolSlotLag 测试slot落后的端点被降级
+func TestRPCPoolSlotLag(t *testing.T) {
+	lagging := newFakeRPCServer(t)
+	handleSlot(lagging, 1000)
+	fresh := newFakeRPCServer(t)
+	handleSlot(fresh, 1200)
+
+	pool, err := rpcpool.New([]config.RPCEndpointConfig{
+		{URL: lagging.URL, Weight: 5},
+		{URL: fresh.URL, Weight: 1},
+	}, time.Minute, 50)
+	require.NoError(t, err)
+
+	pool.CheckHealth(context.Background())
+
+	status := pool.Status()
+	require.Len(t, status, 2)
+	assert.Equal(t, fresh.URL, status[0].URL)
+	assert.Equal(t, uint64(0), status[0].SlotLag)
+	assert.Equal(t, uint64(200), status[1].SlotLag)
+
+	// 读请求优先发往未落后的端点
+	lagging.Handle("getBalance", func(json.RawMessage) (interface{}, error) {
+		return rpcContextResult(1000, 1), nil
+	})
+	fresh.Handle("getBalance", func(json.RawMessage) (interface{}, error) {
+		return rpcContextResult(1200, 2), nil
+	})
+	var balance uint64
+	err = pool.Read(context.Background(), func(ctx context.Context, client *rpc.Client) error {
+		result, err := client.GetBalance(ctx, solana.SystemProgramID, rpc.CommitmentProcessed)
+		if err == nil {
+			balance = result.Value
+		}
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), balance)
+	assert.Zero(t, lagging.Calls("getBalance"))
+}
+
+// TestRPCPoolSend 测试交易并发发送到所有发送端点
+func TestRPCPoolSend(t *testing.T) {
+	signature := solana.SignatureFromBytes(make([]byte, 64)).String()
+
+	reader := newFakeRPCServer(t)
+	failing := newFakeRPCServer(t)
+	failing.Handle("sendTransaction", func(json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("node is behind")
+	})
+	sender := newFakeRPCServer(t)
+	sender.Handle("sendTransaction", func(json.RawMessage) (interface{}, error) {
+		return signature, nil
+	})
+
+	pool, err := rpcpool.New([]config.RPCEndpointConfig{
+		{URL: reader.URL, Roles: []string{rpcpool.RoleRead}},
+		{URL: failing.URL, Roles: []string{rpcpool.RoleSend}},
+		{URL: sender.URL, Roles: []string{rpcpool.RoleSend}},
+	}, time.Minute, 50)
+	require.NoError(t, err)
+
+	wallet := solana.NewWallet()
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("hi"))},
+		solana.Hash{},
+		solana.TransactionPayer(wallet.PublicKey()),
+	)
+	require.NoError(t, err)
+
+	got, err := pool.Send(context.Background(), tx)
+	require.NoError(t, err)
+	assert.Equal(t, signature, got.String())
+	assert.Zero(t, reader.Calls("sendTransaction"), "read-only endpoint must not receive transactions")
+	assert.Eventually(t, func() bool {
+		return failing.Calls("sendTransaction") == 1 && sender.Calls("sendTransaction") == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// 所有发送端点失败
+	sender.Handle("sendTransaction", func(json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("rejected")
+	})
+	_, err = pool.Send(context.Background(), tx)
+	assert.ErrorContains(t, err, "node is behind")
+	assert.ErrorContains(t, err, "rejected")
+}
+
+// TestRPCStatusAPI 测试RPC端点状态接口
+func TestRPCStatusAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	primary := newFakeRPCServer(t)
+	handleSlot(primary, 500)
+	backup := newFakeRPCServer(t)
+	handleSlot(backup, 490)
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCEndpoints = []config.RPCEndpointConfig{
+		{URL: primary.URL, Weight: 2},
+		{URL: backup.URL, Weight: 1, Roles: []string{rpcpool.RoleSend}},
+	}
+	cfg.Solana.HealthCheckInterval = time.Hour
+
+	transactionService := services.NewTransactionService(cfg)
+	transactionService.Start()
+	defer transactionService.Stop()
+
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+	router := gin.New()
+	router.GET("/api/v1/rpc/status", transactionHandler.GetRPCStatus)
+
+	// Start会立即执行一次健康检查
+	require.Eventually(t, func() bool {
+		for _, status := range transactionService.GetRPCStatus() {
+			if status.URL == backup.URL {
+				return status.SlotLag == 10
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/rpc/status", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var response struct {
+		Data []rpcpool.EndpointStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, primary.URL, response.Data[0].URL)
+	assert.Equal(t, []string{rpcpool.RoleRead, rpcpool.RoleSend}, response.Data[0].Roles)
+	assert.Equal(t, []string{rpcpool.RoleSend}, response.Data[1].Roles)
+	assert.Equal(t, uint64(10), response.Data[1].SlotLag)
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -57,7 +58,7 @@ func TestTransactionServiceEncodeSwap(t *testing.T) {
 	}
 
 	// 测试编码交换交易
-	resp, err := transactionService.EncodeSwapTransaction(swapReq)
+	resp, err := transactionService.EncodeSwapTransaction(context.Background(), swapReq)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 
@@ -80,7 +81,7 @@ func TestTransactionServiceEncodeSwap(t *testing.T) {
 		UserWallet: "11111111111111111111111111111112",
 	}
 
-	resp, err = transactionService.EncodeSwapTransaction(invalidSwapReq)
+	resp, err = transactionService.EncodeSwapTransaction(context.Background(), invalidSwapReq)
 	assert.NoError(t, err) // 服务层不应该返回错误，而是在响应中标记失败
 	assert.NotNil(t, resp)
 	assert.False(t, resp.Success)
@@ -108,7 +109,7 @@ func TestTransactionServiceEncodeLiquidity(t *testing.T) {
 	}
 
 	// 测试编码流动性交易
-	resp, err := transactionService.EncodeLiquidityTransaction(liquidityReq)
+	resp, err := transactionService.EncodeLiquidityTransaction(context.Background(), liquidityReq)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 
@@ -129,7 +130,7 @@ func TestTransactionServiceEncodeLiquidity(t *testing.T) {
 		UserWallet: "11111111111111111111111111111112",
 	}
 
-	resp, err = transactionService.EncodeLiquidityTransaction(pumpfunLiquidityReq)
+	resp, err = transactionService.EncodeLiquidityTransaction(context.Background(), pumpfunLiquidityReq)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.False(t, resp.Success)
@@ -255,17 +256,17 @@ func TestTransactionTestRequest(t *testing.T) {
 	invalidTestReq := &types.TransactionTestRequest{
 		Transaction:  "invalid-base64-data",
 		SimulateOnly: true,
-		PrivateKey:   "invalid-private-key",
+		SignerID:     "missing-key",
 	}
 
-	resp, err := transactionService.TestTransaction(invalidTestReq)
+	resp, err := transactionService.TestTransaction(context.Background(), invalidTestReq)
 	assert.NoError(t, err) // 服务层不应该返回错误
 	assert.NotNil(t, resp)
 	assert.False(t, resp.Success)
 	assert.NotEmpty(t, resp.Error)
 
 	// 测试模拟交易
-	resp, err = transactionService.SimulateTransaction(invalidTestReq)
+	resp, err = transactionService.SimulateTransaction(context.Background(), invalidTestReq)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.False(t, resp.Success)
@@ -299,7 +300,7 @@ func TestServiceIntegration(t *testing.T) {
 	assert.NoError(t, err)
 
 	// 2. 编码交易
-	txResp, err := transactionService.EncodeSwapTransaction(swapReq)
+	txResp, err := transactionService.EncodeSwapTransaction(context.Background(), swapReq)
 	assert.NoError(t, err)
 	assert.NotNil(t, txResp)
 
@@ -315,7 +316,7 @@ func TestServiceIntegration(t *testing.T) {
 
 // createTestConfig 创建测试配置
 func createTestConfig() *config.Config {
-	return &config.Config{
+	cfg := &config.Config{
 		Server: config.ServerConfig{
 			Port:         8080,
 			Host:         "0.0.0.0",
@@ -393,4 +394,8 @@ func createTestConfig() *config.Config {
 			MaxRequestSize: 1024 * 1024,
 		},
 	}
+
+	// 与加载配置文件一样设置默认值
+	cfg.SetDefaults()
+	return cfg
 }
\ No newline at end of file
@@ -0,0 +1,273 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/signer"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMemoTransaction 创建以payer为付款人的未签名测试交易
+func newTestMemoTransaction(t *testing.T, payer solana.PublicKey) *solana.Transaction {
+	tx, err := solana.NewTransaction(
+		[]solana.Instruction{solana.NewInstruction(solana.MemoProgramID, solana.AccountMetaSlice{}, []byte("test"))},
+		solana.Hash{1},
+		solana.TransactionPayer(payer),
+	)
+	require.NoError(t, err)
+	return tx
+}
+
+// TestKeystore 测试本地加密密钥库
+func TestKeystore(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := signer.NewKeystore(dir)
+	require.NoError(t, err)
+
+	wallet := solana.NewWallet()
+	info, err := ks.Import("trader", wallet.PrivateKey, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, wallet.PublicKey(), info.PublicKey)
+
+	// 非法ID和重复ID
+	_, err = ks.Import("../escape", solana.NewWallet().PrivateKey, "pw")
+	assert.Error(t, err)
+	_, err = ks.Import("trader", solana.NewWallet().PrivateKey, "pw")
+	assert.Error(t, err)
+
+	// 密钥文件中不包含明文私钥
+	data, err := os.ReadFile(filepath.Join(dir, "trader.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), wallet.PrivateKey.String())
+
+	// 重新打开的密钥库处于锁定状态
+	ks, err = signer.NewKeystore(dir)
+	require.NoError(t, err)
+	assert.True(t, ks.Locked())
+
+	keys, err := ks.ListKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "trader", keys[0].ID)
+
+	_, err = ks.Sign(context.Background(), "trader", []byte("msg"))
+	assert.ErrorIs(t, err, signer.ErrLocked)
+
+	assert.Error(t, ks.Unlock("wrong passphrase"))
+	assert.True(t, ks.Locked())
+	require.NoError(t, ks.Unlock("correct horse"))
+
+	// 按ID或公钥引用密钥
+	for _, ref := range []string{"trader", wallet.PublicKey().String()} {
+		signature, err := ks.Sign(context.Background(), ref, []byte("msg"))
+		require.NoError(t, err)
+		assert.True(t, signature.Verify(wallet.PublicKey(), []byte("msg")))
+	}
+
+	_, err = ks.Resolve(context.Background(), "unknown")
+	assert.ErrorIs(t, err, signer.ErrKeyNotFound)
+
+	ks.Lock()
+	_, err = ks.Sign(context.Background(), "trader", []byte("msg"))
+	assert.ErrorIs(t, err, signer.ErrLocked)
+}
+
+// TestSignTransaction 测试使用签名器签名交易
+func TestSignTransaction(t *testing.T) {
+	ks, err := signer.NewKeystore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock("pw"))
+
+	payer := solana.NewWallet()
+	_, err = ks.Import("payer", payer.PrivateKey, "pw")
+	require.NoError(t, err)
+	_, err = ks.Import("other", solana.NewWallet().PrivateKey, "pw")
+	require.NoError(t, err)
+
+	tx := newTestMemoTransaction(t, payer.PublicKey())
+	require.NoError(t, signer.SignTransaction(context.Background(), ks, tx, "payer"))
+	require.Len(t, tx.Signatures, 1)
+	assert.NoError(t, tx.VerifySignatures())
+
+	// 不是交易签名账户的密钥
+	err = signer.SignTransaction(context.Background(), ks, newTestMemoTransaction(t, payer.PublicKey()), "other")
+	assert.ErrorContains(t, err, "not a required signer")
+}
+
+// TestRemoteSigner 测试远程签名服务客户端
+func TestRemoteSigner(t *testing.T) {
+	wallet := solana.NewWallet()
+	forged := false
+	var keyRequests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/keys":
+			atomic.AddInt32(&keyRequests, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"keys": []signer.KeyInfo{{ID: "hsm-1", PublicKey: wallet.PublicKey()}},
+			})
+		case "/sign":
+			var req struct {
+				Key     string `json:"key"`
+				Message string `json:"message"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			message, _ := base64.StdEncoding.DecodeString(req.Message)
+			key := wallet.PrivateKey
+			if forged {
+				key = solana.NewWallet().PrivateKey
+			}
+			signature, _ := key.Sign(message)
+			json.NewEncoder(w).Encode(map[string]string{"signature": signature.String()})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	remote, err := signer.NewRemoteSigner(server.URL, "secret-token", time.Second)
+	require.NoError(t, err)
+
+	tx := newTestMemoTransaction(t, wallet.PublicKey())
+	require.NoError(t, signer.SignTransaction(context.Background(), remote, tx, "hsm-1"))
+	assert.NoError(t, tx.VerifySignatures())
+
+	// 密钥列表被缓存，再次签名不会重新请求 /keys
+	_, err = remote.Sign(context.Background(), "hsm-1", []byte("msg"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&keyRequests))
+
+	// 缓存中找不到的密钥会重新获取列表
+	_, err = remote.Resolve(context.Background(), "missing")
+	assert.ErrorIs(t, err, signer.ErrKeyNotFound)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&keyRequests))
+
+	// 返回的签名无法验证时拒绝
+	forged = true
+	_, err = remote.Sign(context.Background(), wallet.PublicKey().String(), []byte("msg"))
+	assert.ErrorContains(t, err, "does not verify")
+
+	// 认证失败
+	unauthorized, err := signer.NewRemoteSigner(server.URL, "", time.Second)
+	require.NoError(t, err)
+	_, err = unauthorized.ListKeys(context.Background())
+	assert.ErrorContains(t, err, "401")
+}
+
+// TestNewSignerFromConfig 测试按配置创建签名器
+func TestNewSignerFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	ks, err := signer.NewKeystore(dir)
+	require.NoError(t, err)
+	_, err = ks.Import("trader", solana.NewWallet().PrivateKey, "from-env")
+	require.NoError(t, err)
+
+	t.Setenv("TEST_KEYSTORE_PASSPHRASE", "from-env")
+	s, err := signer.New(config.SignerConfig{Backend: "keystore", KeystoreDir: dir, PassphraseEnv: "TEST_KEYSTORE_PASSPHRASE"})
+	require.NoError(t, err)
+	assert.False(t, s.(*signer.Keystore).Locked())
+
+	// 口令错误时启动失败
+	t.Setenv("TEST_KEYSTORE_PASSPHRASE", "wrong")
+	_, err = signer.New(config.SignerConfig{Backend: "keystore", KeystoreDir: dir, PassphraseEnv: "TEST_KEYSTORE_PASSPHRASE"})
+	assert.Error(t, err)
+
+	_, err = signer.New(config.SignerConfig{Backend: "remote"})
+	assert.Error(t, err)
+	_, err = signer.New(config.SignerConfig{Backend: "ledger"})
+	assert.Error(t, err)
+}
+
+// TestTransactionWithSignerID 测试交易测试接口通过signer_id签名
+func TestTransactionWithSignerID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server := newFakeRPCServer(t)
+	server.Handle("simulateTransaction", func(params json.RawMessage) (interface{}, error) {
+		return rpcContextResult(1, map[string]interface{}{
+			"err":           nil,
+			"logs":          []string{"Program log: ok"},
+			"unitsConsumed": 1200,
+		}), nil
+	})
+
+	ks, err := signer.NewKeystore(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, ks.Unlock("pw"))
+	wallet := solana.NewWallet()
+	_, err = ks.Import("trader", wallet.PrivateKey, "pw")
+	require.NoError(t, err)
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = server.URL
+	transactionService := services.NewTransactionService(cfg)
+	transactionService.SetSigner(ks)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	router := gin.New()
+	router.POST("/api/v1/test/simulate", transactionHandler.SimulateTransaction)
+	router.POST("/api/v1/test/transaction", transactionHandler.TestTransaction)
+	router.GET("/api/v1/signer/keys", transactionHandler.ListSignerKeys)
+
+	txData, err := newTestMemoTransaction(t, wallet.PublicKey()).MarshalBinary()
+	require.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(txData)
+
+	body, _ := json.Marshal(types.TransactionTestRequest{Transaction: encoded, SignerID: "trader"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/test/simulate", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.TransactionTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.Equal(t, uint64(1200), resp.GasUsed)
+
+	// 未知的签名密钥
+	body, _ = json.Marshal(types.TransactionTestRequest{Transaction: encoded, SignerID: "nobody", SimulateOnly: true})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/test/transaction", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "not found")
+
+	// 请求中不能携带私钥
+	body, _ = json.Marshal(map[string]interface{}{"transaction": encoded, "private_key": wallet.PrivateKey.String()})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/test/transaction", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "signer_id is required")
+
+	// 密钥列表不包含密钥材料
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/signer/keys", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), wallet.PublicKey().String())
+	assert.NotContains(t, w.Body.String(), wallet.PrivateKey.String())
+}
@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcAccount 构建RPC返回的账户数据
+func rpcAccount(lamports uint64, owner solana.PublicKey, data []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"lamports":   lamports,
+		"owner":      owner.String(),
+		"data":       []string{base64.StdEncoding.EncodeToString(data), "base64"},
+		"executable": false,
+		"rentEpoch":  0,
+		"space":      len(data),
+	}
+}
+
+// tokenAccountData 构建SPL Token账户数据
+func tokenAccountData(mint, owner solana.PublicKey, amount uint64) []byte {
+	data := make([]byte, 165)
+	copy(data[0:32], mint[:])
+	copy(data[32:64], owner[:])
+	binary.LittleEndian.PutUint64(data[64:72], amount)
+	data[108] = 1 // 已初始化
+	return data
+}
+
+// accountsResponder 根据请求的地址返回账户状态，未知账户返回null
+func accountsResponder(state map[string]map[string]interface{}, addresses []string) []interface{} {
+	values := make([]interface{}, len(addresses))
+	for i, address := range addresses {
+		if account, ok := state[address]; ok {
+			values[i] = account
+		}
+	}
+	return values
+}
+
+// TestSimulateBalanceChanges 测试未签名交易模拟并计算余额变化
+func TestSimulateBalanceChanges(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	inputAccount, _, err := solana.FindAssociatedTokenAddress(user, solana.SolMint)
+	require.NoError(t, err)
+	outputAccount, _, err := solana.FindAssociatedTokenAddress(user, mint)
+	require.NoError(t, err)
+
+	const rent = 2039280
+	const fee = 5000 + 5000 // 基础费用 + 25000 micro-lamports × 200000 CU
+	pre := map[string]map[string]interface{}{
+		user.String():         rpcAccount(10000000000, solana.SystemProgramID, nil),
+		inputAccount.String(): rpcAccount(1000000000+rent, solana.TokenProgramID, tokenAccountData(solana.SolMint, user, 1000000000)),
+	}
+	post := map[string]map[string]interface{}{
+		user.String():          rpcAccount(10000000000-fee-rent, solana.SystemProgramID, nil),
+		inputAccount.String():  rpcAccount(500000000+rent, solana.TokenProgramID, tokenAccountData(solana.SolMint, user, 500000000)),
+		outputAccount.String(): rpcAccount(rent, solana.TokenProgramID, tokenAccountData(mint, user, 480000000)),
+	}
+
+	var mu sync.Mutex
+	var simOpts map[string]interface{}
+	server := newBlockhashRPCServer(t)
+	server.Handle("getMultipleAccounts", func(params json.RawMessage) (interface{}, error) {
+		var args []json.RawMessage
+		var addresses []string
+		json.Unmarshal(params, &args)
+		json.Unmarshal(args[0], &addresses)
+		return rpcContextResult(1, accountsResponder(pre, addresses)), nil
+	})
+	server.Handle("simulateTransaction", func(params json.RawMessage) (interface{}, error) {
+		var args []json.RawMessage
+		var opts map[string]interface{}
+		json.Unmarshal(params, &args)
+		json.Unmarshal(args[1], &opts)
+		mu.Lock()
+		simOpts = opts
+		mu.Unlock()
+
+		var addresses []string
+		for _, address := range opts["accounts"].(map[string]interface{})["addresses"].([]interface{}) {
+			addresses = append(addresses, address.(string))
+		}
+		return rpcContextResult(2, map[string]interface{}{
+			"err":           nil,
+			"logs":          []string{"Program log: Instruction: Buy"},
+			"unitsConsumed": 45000,
+			"accounts":      accountsResponder(post, addresses),
+		}), nil
+	})
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = server.URL
+	transactionService := services.NewTransactionService(cfg)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	router := gin.New()
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	router.POST("/api/v1/test/simulate", transactionHandler.SimulateTransaction)
+	router.POST("/api/v1/test/transaction", transactionHandler.TestTransaction)
+
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:     "pumpfun",
+		InputMint:   solana.SolMint.String(),
+		OutputMint:  mint.String(),
+		AmountIn:    500000000,
+		Slippage:    0.1,
+		PriorityFee: 25000,
+		UserWallet:  user.String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var encoded types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &encoded))
+
+	// 不提供签名密钥，直接模拟未签名交易
+	body, _ = json.Marshal(types.TransactionTestRequest{
+		Transaction:     encoded.Transaction,
+		QuotedAmountOut: 500000000,
+	})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/test/simulate", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var resp types.TransactionTestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.True(t, resp.Success, resp.Error)
+	assert.Equal(t, uint64(45000), resp.GasUsed)
+
+	mu.Lock()
+	assert.Equal(t, true, simOpts["replaceRecentBlockhash"])
+	assert.Nil(t, simOpts["sigVerify"])
+	mu.Unlock()
+
+	sim := resp.Simulation
+	require.NotNil(t, sim)
+	assert.False(t, sim.Signed)
+	assert.Equal(t, user.String(), sim.UserWallet)
+	assert.Equal(t, solana.SolMint.String(), sim.InputMint)
+	assert.Equal(t, mint.String(), sim.OutputMint)
+	assert.Equal(t, uint64(500000000), sim.InputSpent)
+	assert.Equal(t, uint64(480000000), sim.OutputReceived)
+	assert.Equal(t, uint64(fee), sim.Fee)
+	assert.Equal(t, int64(rent), sim.RentChange)
+	assert.Equal(t, int64(-(fee + rent)), sim.SOLChange)
+	assert.InDelta(t, 0.04, sim.Slippage, 1e-9)
+	assert.Len(t, sim.BalanceChanges, 3)
+
+	// 实际发送仍然需要签名密钥
+	body, _ = json.Marshal(types.TransactionTestRequest{Transaction: encoded.Transaction})
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/v1/test/transaction", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "signer_id is required")
+}
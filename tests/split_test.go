@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/types"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// constantProductOut 恒定乘积池的输出金额
+func constantProductOut(reserveIn, reserveOut, amountIn uint64) uint64 {
+	return reserveOut * amountIn / (reserveIn + amountIn)
+}
+
+// newSplitTestRouter 创建raydium和pumpswap储备相同的测试路由，大额订单平分到两个池子时输出最高
+func newSplitTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	raydium := newDEXAPIServer(t, nil, func(req quoteRequestBody) interface{} {
+		out := constantProductOut(1000000, 100000000, req.Amount)
+		return map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"outAmount": strconv.FormatUint(out, 10), "fee": "0"},
+		}
+	})
+	pumpswap := newDEXAPIServer(t, nil, func(req quoteRequestBody) interface{} {
+		return map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"amountOut": constantProductOut(1000000, 100000000, req.Amount), "fee": 0},
+		}
+	})
+
+	cfg := createTestConfig()
+	cfg.Solana.RPCURL = newBlockhashRPCServer(t).URL
+	cfg.Routing.QuoteTimeout = 2 * time.Second
+	for i := range cfg.DEXes {
+		cfg.DEXes[i].RetryCount = 1
+		switch cfg.DEXes[i].Name {
+		case "raydium":
+			cfg.DEXes[i].Endpoints = map[string]string{"quote": raydium.URL + "/quote"}
+		case "pumpswap":
+			cfg.DEXes[i].Endpoints = map[string]string{"quote": pumpswap.URL + "/quote"}
+		default:
+			cfg.DEXes[i].Enabled = false
+		}
+	}
+
+	transactionHandler := handlers.NewTransactionHandler(services.NewTransactionService(cfg))
+	router := gin.New()
+	router.GET("/api/v1/route/split", transactionHandler.GetSplitQuote)
+	router.POST("/api/v1/encode/swap", transactionHandler.EncodeSwap)
+	router.POST("/api/v1/tx/decode", transactionHandler.DecodeTransaction)
+	return router
+}
+
+// TestSplitQuote 测试按边际价格拆单优于单个池子的直接报价
+func TestSplitQuote(t *testing.T) {
+	router := newSplitTestRouter(t)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/route/split?inputMint="+testSOLMint+"&outputMint="+testUSDCMint+"&amountIn=1000000", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.RouteQuoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Best)
+
+	// 两个池子各分得一半
+	require.Len(t, resp.Best.Split, 2)
+	assert.Equal(t, uint64(500000), resp.Best.Split[0].AmountIn)
+	assert.Equal(t, uint64(500000), resp.Best.Split[1].AmountIn)
+	assert.ElementsMatch(t, []string{"raydium", "pumpswap"}, []string{resp.Best.Split[0].DEX, resp.Best.Split[1].DEX})
+	assert.Equal(t, 2*constantProductOut(1000000, 100000000, 500000), resp.Best.NetAmountOut)
+	require.NotNil(t, resp.Best.Quote)
+	assert.Equal(t, resp.Best.Split, resp.Best.Quote.Route)
+
+	// 不拆单的直接报价作为备选
+	require.Len(t, resp.Alternatives, 2)
+	assert.Equal(t, constantProductOut(1000000, 100000000, 1000000), resp.Alternatives[0].NetAmountOut)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/route/split?inputMint="+testSOLMint+"&outputMint="+testUSDCMint+"&amountIn=1000000&parts=1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+}
+
+// TestEncodeSplitSwap 测试拆单编码为同一笔交易中的并列交换指令，最小输出按比例分摊
+func TestEncodeSplitSwap(t *testing.T) {
+	router := newSplitTestRouter(t)
+
+	body, _ := json.Marshal(types.SwapRequest{
+		DEXType:      "auto",
+		Split:        true,
+		InputMint:    testSOLMint,
+		OutputMint:   testUSDCMint,
+		AmountIn:     1000000,
+		MinAmountOut: 60000001,
+		UserWallet:   solana.NewWallet().PublicKey().String(),
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/encode/swap", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code, w.Body.String())
+
+	var resp types.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Split, 2)
+	assert.Empty(t, resp.Transactions)
+	assert.Equal(t, 2*constantProductOut(1000000, 100000000, 500000), resp.QuotedAmountOut)
+
+	_, decoded := decodeViaAPI(t, router, resp.Transaction)
+	require.True(t, decoded.Success, decoded.Error)
+	require.Len(t, decoded.Instructions, 2)
+
+	var minTotal float64
+	for _, instruction := range decoded.Instructions {
+		assert.Equal(t, "swap", instruction.Name)
+		assert.Equal(t, float64(500000), instruction.Fields["amount_in"])
+		minTotal += instruction.Fields["min_amount_out"].(float64)
+	}
+	assert.Equal(t, float64(60000001), minTotal)
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/handlers"
+	"solana-dex-service/internal/services"
+	"solana-dex-service/internal/store"
+	pkgtypes "solana-dex-service/pkg/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBoltTransactionStore 测试交易记录存储
+func TestBoltTransactionStore(t *testing.T) {
+	txStore, err := store.NewBoltTransactionStore(filepath.Join(t.TempDir(), "data", "transactions.db"))
+	require.NoError(t, err)
+	defer txStore.Close()
+
+	records := []pkgtypes.TransactionResult{
+		{Signature: "sig-1", RequestID: "req-1", Wallet: "wallet-a", DEX: "raydium", Status: pkgtypes.TransactionStatusSubmitted},
+		{Signature: "sig-2", RequestID: "req-2", Wallet: "wallet-a", DEX: "pumpfun", Status: pkgtypes.TransactionStatusFailed, ErrorMessage: "blockhash not found"},
+		{Signature: "sig-3", RequestID: "req-3", Wallet: "wallet-b", DEX: "raydium", Status: pkgtypes.TransactionStatusFinalized, Slot: 123, Fee: 5000, GasUsed: 42000},
+	}
+	for i := range records {
+		records[i].CreatedAt = time.Now().Add(time.Duration(i) * time.Second)
+		require.NoError(t, txStore.Save(&records[i]))
+	}
+
+	// 测试按签名获取
+	result, err := txStore.Get("sig-3")
+	require.NoError(t, err)
+	assert.Equal(t, "req-3", result.RequestID)
+	assert.Equal(t, uint64(123), result.Slot)
+	assert.Equal(t, uint64(5000), result.Fee)
+	assert.Equal(t, uint64(42000), result.GasUsed)
+	assert.False(t, result.UpdatedAt.IsZero())
+
+	// 测试获取不存在的记录
+	_, err = txStore.Get("missing")
+	assert.ErrorIs(t, err, store.ErrTransactionNotFound)
+
+	// 测试空签名
+	assert.Error(t, txStore.Save(&pkgtypes.TransactionResult{}))
+
+	// 测试筛选
+	results, total, err := txStore.List(store.TransactionFilter{Wallet: "wallet-a"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, "sig-2", results[0].Signature, "results should be newest first")
+
+	results, total, err = txStore.List(store.TransactionFilter{DEX: "raydium", Status: pkgtypes.TransactionStatusFinalized})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, "sig-3", results[0].Signature)
+
+	// 测试分页
+	results, total, err = txStore.List(store.TransactionFilter{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, results, 1)
+	assert.Equal(t, "sig-2", results[0].Signature)
+
+	results, _, err = txStore.List(store.TransactionFilter{Offset: 10})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// TestTransactionStatusAPI 测试交易状态查询接口
+func TestTransactionStatusAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	txStore, err := store.NewBoltTransactionStore(filepath.Join(t.TempDir(), "transactions.db"))
+	require.NoError(t, err)
+	defer txStore.Close()
+
+	require.NoError(t, txStore.Save(&pkgtypes.TransactionResult{
+		Signature: "sig-final",
+		Wallet:    "wallet-a",
+		DEX:       "raydium",
+		Status:    pkgtypes.TransactionStatusFinalized,
+		Success:   true,
+	}))
+	require.NoError(t, txStore.Save(&pkgtypes.TransactionResult{
+		Signature:    "sig-failed",
+		Wallet:       "wallet-b",
+		DEX:          "pumpfun",
+		Status:       pkgtypes.TransactionStatusFailed,
+		ErrorMessage: "insufficient funds",
+	}))
+
+	transactionService := services.NewTransactionService(createTestConfig())
+	transactionService.SetTransactionStore(txStore)
+	transactionHandler := handlers.NewTransactionHandler(transactionService)
+
+	router := gin.New()
+	router.GET("/api/v1/tx", transactionHandler.ListTransactions)
+	router.GET("/api/v1/tx/:signature", transactionHandler.GetTransactionStatus)
+
+	// 已最终确认的交易不需要访问链上
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/tx/sig-final", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var response struct {
+		Success bool                       `json:"success"`
+		Data    pkgtypes.TransactionResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, pkgtypes.TransactionStatusFinalized, response.Data.Status)
+
+	// 不存在的交易返回404
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/tx/unknown", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+
+	// 按状态筛选
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/tx?status=failed&dex=pumpfun", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var listResponse struct {
+		Data struct {
+			Transactions []pkgtypes.TransactionResult `json:"transactions"`
+			Total        int                          `json:"total"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listResponse))
+	assert.Equal(t, 1, listResponse.Data.Total)
+	require.Len(t, listResponse.Data.Transactions, 1)
+	assert.Equal(t, "sig-failed", listResponse.Data.Transactions[0].Signature)
+	assert.Equal(t, "insufficient funds", listResponse.Data.Transactions[0].ErrorMessage)
+
+	// 无效的分页参数返回400，不使用默认值
+	for _, query := range []string{"limit=abc", "limit=0", "limit=1001", "offset=-1", "offset=x"} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", "/api/v1/tx?"+query, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, 400, w.Code, query)
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"solana-dex-service/internal/config"
+	"solana-dex-service/internal/upstream"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFlakyServer 创建前failures次请求返回status的模拟上游，之后返回成功，并记录每次收到的请求体
+func newFlakyServer(t *testing.T, failures int32, status int, header http.Header) (*httptest.Server, *int32, *[]string) {
+	var calls int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&calls, 1) <= failures {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":"try again"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "query": r.URL.RawQuery, "body": string(body)})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls, &bodies
+}
+
+// newTestUpstream 创建退避时间较短的测试客户端
+func newTestUpstream(maxAttempts int) *upstream.Client {
+	return upstream.New(&config.UpstreamConfig{
+		BackoffBase: 10 * time.Millisecond,
+		BackoffMax:  1500 * time.Millisecond,
+	}).WithPolicy(5*time.Second, maxAttempts)
+}
+
+// TestUpstreamRetryRebuildsBody 测试POST重试时每次都发送完整的请求体
+func TestUpstreamRetryRebuildsBody(t *testing.T) {
+	server, calls, bodies := newFlakyServer(t, 2, http.StatusBadGateway, nil)
+
+	var result struct {
+		Success bool   `json:"success"`
+		Body    string `json:"body"`
+	}
+	err := newTestUpstream(3).Do(context.Background(), "POST", server.URL, map[string]interface{}{"amount": 1000}, &result)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	for _, body := range *bodies {
+		assert.JSONEq(t, `{"amount":1000}`, body)
+	}
+}
+
+// TestUpstreamFinalFailure 测试最后一次仍然失败时返回状态码，而不是解析已关闭的响应体
+func TestUpstreamFinalFailure(t *testing.T) {
+	server, calls, _ := newFlakyServer(t, 10, http.StatusServiceUnavailable, nil)
+
+	err := newTestUpstream(3).Do(context.Background(), "GET", server.URL, nil, nil)
+	require.Error(t, err)
+	var statusErr *upstream.StatusError
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Contains(t, err.Error(), "after 3 attempts")
+	assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+
+	// 4xx不重试
+	server, calls, _ = newFlakyServer(t, 10, http.StatusBadRequest, nil)
+	err = newTestUpstream(3).Do(context.Background(), "GET", server.URL, nil, nil)
+	require.True(t, errors.As(err, &statusErr))
+	assert.Equal(t, http.StatusBadRequest, statusErr.StatusCode)
+	assert.Contains(t, statusErr.Body, "try again")
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+}
+
+// TestUpstreamRetryAfter 测试429按Retry-After等待，超过退避上限时不再重试
+func TestUpstreamRetryAfter(t *testing.T) {
+	server, calls, _ := newFlakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+
+	start := time.Now()
+	err := newTestUpstream(3).Do(context.Background(), "GET", server.URL, nil, nil)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+
+	server, calls, _ = newFlakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"120"}})
+	start = time.Now()
+	err = newTestUpstream(3).Do(context.Background(), "GET", server.URL, nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 429")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	// 截止时间早于重试时间时立即返回
+	server, _, _ = newFlakyServer(t, 1, http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}})
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err = newTestUpstream(3).Do(ctx, "GET", server.URL, nil, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+// TestUpstreamQueryParams 测试GET请求的参数编码为查询参数，大整数不丢失精度
+func TestUpstreamQueryParams(t *testing.T) {
+	server, _, bodies := newFlakyServer(t, 0, 0, nil)
+
+	var result struct {
+		Query string `json:"query"`
+	}
+	params := map[string]interface{}{
+		"inputMint": testSOLMint,
+		"amount":    uint64(18446744073709551615),
+		"direct":    true,
+	}
+	err := newTestUpstream(1).Do(context.Background(), "GET", server.URL+"?version=2", params, &result)
+	require.NoError(t, err)
+	assert.Equal(t, "amount=18446744073709551615&direct=true&inputMint="+testSOLMint+"&version=2", result.Query)
+	assert.Equal(t, []string{""}, *bodies)
+}
+
+// TestUpstreamResponseLimit 测试响应体超过上限时返回错误且不重试
+func TestUpstreamResponseLimit(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Write([]byte(`{"data":"` + strings.Repeat("x", 2048) + `"}`))
+	}))
+	defer server.Close()
+
+	client := upstream.New(&config.UpstreamConfig{MaxResponseBytes: 1024}).WithPolicy(5*time.Second, 3)
+	var result map[string]interface{}
+	err := client.Do(context.Background(), "GET", server.URL, nil, &result)
+	assert.True(t, errors.Is(err, upstream.ErrResponseTooLarge))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+// TestUpstreamConnectionReuse 测试同一主机的请求复用连接，不同策略的客户端共享连接池
+func TestUpstreamConnectionReuse(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	shared := upstream.New(&config.UpstreamConfig{MaxIdleConnsPerHost: 2})
+	for i := 0; i < 5; i++ {
+		client := shared.WithPolicy(5*time.Second, i%2+1)
+		require.NoError(t, client.Do(context.Background(), "GET", server.URL, nil, nil))
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&conns))
+}